@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/bot"
+	"github.com/user/slack-bot-api/internal/logging"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/store"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+// runCommand runs a one-off CLI subcommand instead of the bot
+func runCommand(args []string, cfg *config.Config, httpClient *http.Client, logger *logging.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch {
+	case len(args) >= 2 && args[0] == "channels" && args[1] == "suggest":
+		return runChannelsSuggest(ctx, args[2:], cfg, httpClient, logger)
+	case len(args) >= 1 && args[0] == "cleanup":
+		return runCleanup(ctx, args[1:], cfg, httpClient, logger)
+	case len(args) >= 1 && args[0] == "usage":
+		return runUsage(ctx, args[1:])
+	case len(args) >= 1 && args[0] == "migrate":
+		return runMigrate(args[1:], cfg, logger)
+	default:
+		return fmt.Errorf("unknown command %q; available commands:\n"+
+			"  channels suggest  list the most active channels as a SLACK_CHANNEL_IDS value\n"+
+			"  cleanup           delete translations older than TRANSLATION_TTL\n"+
+			"  migrate           upgrade STATE_FILE to the current schema version\n"+
+			"  usage             print a running bot's Slack API calls per method over the last hour",
+			strings.Join(args, " "))
+	}
+}
+
+// runChannelsSuggest scans recent activity in every channel the bot is in
+// and prints the most active ones as a suggested SLACK_CHANNEL_IDS value
+func runChannelsSuggest(ctx context.Context, args []string, cfg *config.Config, httpClient *http.Client, logger *logging.Logger) error {
+	flags := flag.NewFlagSet("channels suggest", flag.ContinueOnError)
+	top := flags.Int("top", 10, "number of channels to suggest")
+	days := flags.Int("days", 7, "how many days of history to count")
+	maxMessages := flags.Int("max-messages", 200, "maximum messages counted per channel")
+	statePath := flags.String("state", "channel-activity.json", "file storing scan progress so an interrupted scan can resume (empty to disable)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := slackClient.New(cfg, httpClient, logger)
+	if err != nil {
+		return err
+	}
+
+	activity, err := client.SuggestChannels(ctx, slackClient.SuggestOptions{
+		Lookback:    time.Duration(*days) * 24 * time.Hour,
+		MaxMessages: *maxMessages,
+		StatePath:   *statePath,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(activity) > *top {
+		activity = activity[:*top]
+	}
+
+	ids := make([]string, 0, len(activity))
+	for _, a := range activity {
+		fmt.Printf("%6d  #%s (%s)\n", a.Messages, a.Name, a.ID)
+		ids = append(ids, a.ID)
+	}
+	fmt.Printf("\nSLACK_CHANNEL_IDS=%s\n", strings.Join(ids, ","))
+
+	if *statePath != "" {
+		fmt.Printf("\nScan progress is kept in %s; delete it to rescan from scratch.\n", *statePath)
+	}
+	return nil
+}
+
+// runCleanup deletes expired translations once, or with -dry-run lists what
+// would be deleted
+func runCleanup(ctx context.Context, args []string, cfg *config.Config, httpClient *http.Client, logger *logging.Logger) error {
+	flags := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only list the translations that would be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.StateFile == "" {
+		return fmt.Errorf("STATE_FILE is not set, so there are no recorded translations to clean up")
+	}
+
+	translator, err := newTranslator(cfg, httpClient, logger)
+	if err != nil {
+		return err
+	}
+	slackBot, err := bot.New(cfg, translator, nil, httpClient, logger)
+	if err != nil {
+		return err
+	}
+
+	results, err := slackBot.Cleanup(ctx, *dryRun)
+	for _, result := range results {
+		fmt.Println(bot.FormatCleanupResult(result))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No expired translations.")
+	}
+	return nil
+}
+
+// runMigrate upgrades the state file to the current schema version, or with
+// -dry-run lists the migrations it still needs
+func runMigrate(args []string, cfg *config.Config, logger *logging.Logger) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only list the pending migrations")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.StateFile == "" {
+		return fmt.Errorf("STATE_FILE is not set, so there is nothing to migrate")
+	}
+
+	version, pending, err := store.Pending(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is at schema version %d, this binary writes version %d.\n", cfg.StateFile, version, store.SchemaVersion)
+	if len(pending) == 0 {
+		fmt.Println("No pending migrations.")
+		return nil
+	}
+	for _, m := range pending {
+		fmt.Printf("%4d  %s\n", m.Version, m.Description)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	if _, err := store.Open(cfg.StateFile, logger); err != nil {
+		return err
+	}
+	fmt.Printf("Migrated to schema version %d.\n", store.SchemaVersion)
+	return nil
+}
+
+// runUsage prints the Slack API usage of a running bot, as served at
+// /debug/slack-usage
+func runUsage(ctx context.Context, args []string) error {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	flags := flag.NewFlagSet("usage", flag.ContinueOnError)
+	url := flags.String("url", "http://localhost:"+port+"/debug/slack-usage", "slack-usage endpoint of the running bot")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reaching the bot: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", *url, resp.StatusCode)
+	}
+
+	var usage v1.SlackUsage
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return fmt.Errorf("error decoding Slack usage: %w", err)
+	}
+
+	if len(usage.Methods) == 0 {
+		fmt.Println("No Slack API calls in the last hour.")
+		return nil
+	}
+
+	fmt.Printf("Slack API calls in the last %s:\n\n", time.Duration(usage.WindowSeconds)*time.Second)
+	fmt.Printf("%-36s %8s %8s %8s %12s\n", "METHOD", "CALLS", "PER MIN", "429S", "AVG LATENCY")
+	for _, m := range usage.Methods {
+		perMinute := float64(m.Calls) / (float64(usage.WindowSeconds) / 60)
+		fmt.Printf("%-36s %8d %8.1f %8d %10dms\n", m.Method, m.Calls, perMinute, m.RateLimited, m.AvgLatencyMS)
+	}
+	return nil
+}
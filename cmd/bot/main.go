@@ -3,27 +3,52 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/user/slack-bot-api/config"
 	"github.com/user/slack-bot-api/internal/bot"
+	"github.com/user/slack-bot-api/internal/httpclient"
+	"github.com/user/slack-bot-api/internal/httpserver"
+	"github.com/user/slack-bot-api/internal/logging"
 )
 
 func main() {
 	// Set up logging
-	logger := log.New(os.Stdout, "slack-bot: ", log.Lshortfile|log.LstdFlags)
-	
+	output := log.New(os.Stdout, "slack-bot: ", log.Lshortfile|log.LstdFlags)
+
 	// Load configuration from environment variables
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Fatalf("Failed to load configuration: %v", err)
+		output.Fatalf("Failed to load configuration: %v", err)
+	}
+	logger := logging.New(output, cfg.LogLevel)
+	if cfg.LogFormat == logging.FormatJSON {
+		logger = logging.NewJSON(cfg.LogLevel)
 	}
 
-	// Create a new bot instance
-	slackBot, err := bot.New(cfg, logger)
+	// Slack and the LLM APIs share one client, honoring the proxy and CA
+	// settings
+	httpClient, err := httpclient.New(cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create HTTP client: %v", err)
+	}
+
+	// Run a one-off subcommand instead of the bot when one is given
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], cfg, httpClient, logger); err != nil {
+			logger.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
+
+	// Create a new bot instance, translating with the configured provider
+	translator, err := newTranslator(cfg, httpClient, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create translator: %v", err)
+	}
+	slackBot, err := bot.New(cfg, translator, newModerator(cfg, httpClient, logger), httpClient, logger)
 	if err != nil {
 		logger.Fatalf("Failed to create bot: %v", err)
 	}
@@ -35,10 +60,10 @@ func main() {
 	// Handle shutdown signals
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	
+
 	go func() {
 		sig := <-sigCh
-		logger.Printf("Received signal: %v, shutting down...", sig)
+		logger.Infof("Received signal: %v, shutting down...", sig)
 		cancel()
 	}()
 
@@ -47,33 +72,24 @@ func main() {
 	if port == "" {
 		port = "8080" // Default port if not specified
 	}
-	
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("Gen Alpha Slack Bot is running! 🤖"))
-	})
-	
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-	
-	server := &http.Server{Addr: ":" + port}
-	
-	go func() {
-		logger.Printf("Starting HTTP server on port %s...", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Printf("HTTP server error: %v", err)
-		}
-	}()
+
+	server := httpserver.New(":"+port, slackBot, slackBot, logger)
+	server.RegisterDebug(slackBot)
+	if err := server.Start(); err != nil {
+		logger.Fatalf("Failed to start HTTP server: %v", err)
+	}
 
 	// Start the bot
-	logger.Println("Starting the Gen Alpha translation bot...")
+	logger.Infof("Starting the Gen Alpha translation bot...")
 	if err := slackBot.Start(ctx); err != nil {
 		logger.Fatalf("Bot error: %v", err)
 	}
-	
-	// Shutdown the HTTP server when the bot is done
-	if err := server.Shutdown(context.Background()); err != nil {
-		logger.Printf("HTTP server shutdown error: %v", err)
+
+	// Shutdown the HTTP server when the bot is done, without waiting on
+	// slow clients forever
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownGrace)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("HTTP server shutdown error: %v", err)
 	}
-} 
\ No newline at end of file
+}
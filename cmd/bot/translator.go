@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/anthropic"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/ollama"
+	"github.com/user/slack-bot-api/internal/openai"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// newTranslator creates the translator for the configured LLM_PROVIDER,
+// caching its translations unless TRANSLATION_CACHE is off
+func newTranslator(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) (translate.Translator, error) {
+	translator, err := newProvider(cfg, httpClient, logger)
+	if err != nil || !cfg.TranslationCache {
+		return translator, err
+	}
+	logger.Infof("Caching up to %d translations for %v", cfg.TranslationCacheSize, cfg.TranslationCacheTTL)
+	return translate.NewCache(translator, cfg.TranslationCacheSize, cfg.TranslationCacheTTL, logger), nil
+}
+
+// newProvider creates the client of the configured LLM_PROVIDER
+func newProvider(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) (translate.Translator, error) {
+	switch cfg.LLMProvider {
+	case config.LLMProviderOpenAICompatible:
+		client, err := openai.NewCompatible(cfg, httpClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case config.LLMProviderAnthropic:
+		return anthropic.New(cfg, httpClient, logger), nil
+	case config.LLMProviderOllama:
+		client, err := ollama.New(cfg, httpClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		// A cheap check of the server and model, so a missing model shows
+		// up at startup rather than at the first translation
+		if cfg.Logs {
+			if err := client.CheckModel(context.Background()); err != nil {
+				logger.Warnf("⚠️ %v", err)
+			} else {
+				logger.Infof("✅ Ollama has model %s", client.Model())
+			}
+		}
+		return client, nil
+	default:
+		return openai.New(cfg, httpClient, logger), nil
+	}
+}
+
+// newModerator creates the OpenAI client checking translations before
+// they're posted, whichever provider translates them, or nil when
+// MODERATION is off
+func newModerator(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) translate.Moderator {
+	if !cfg.Moderation {
+		return nil
+	}
+	logger.Infof("Moderating translations, flagging scores of %.2f or more (action: %s)", cfg.ModerationThreshold, cfg.ModerationAction)
+	return openai.New(cfg, httpClient, logger)
+}
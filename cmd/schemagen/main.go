@@ -0,0 +1,51 @@
+// Command schemagen writes the JSON Schema of the HTTP API response types.
+// It is run through `go generate ./pkg/api/v1`.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/user/slack-bot-api/internal/jsonschema"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+func main() {
+	out := flag.String("out", "schema.json", "file to write the schema to")
+	flag.Parse()
+
+	data, err := generate()
+	if err != nil {
+		log.Fatalf("Failed to encode schema: %v", err)
+	}
+
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatalf("Failed to write schema: %v", err)
+	}
+}
+
+// generate returns the schema document as it is written to schema.json
+func generate() ([]byte, error) {
+	generator := jsonschema.NewGenerator()
+
+	responses := jsonschema.Schema{}
+	for _, response := range v1.Responses {
+		responses[response.Name] = generator.Reflect(response.Type)
+	}
+
+	schema := jsonschema.Schema{
+		"$schema":        "http://json-schema.org/draft-07/schema#",
+		"title":          "slack-bot-api HTTP responses",
+		"schema_version": v1.SchemaVersion,
+		"responses":      responses,
+		"definitions":    generator.Definitions,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
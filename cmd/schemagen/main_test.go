@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const committedSchema = "../../pkg/api/v1/schema.json"
+
+// schema.json is what clients code against, so it must match the types,
+// and the types may only change in ways old clients can live with
+func TestSchemaUpToDate(t *testing.T) {
+	committed, err := os.ReadFile(committedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if bytes.Equal(committed, generated) {
+		return
+	}
+
+	for _, problem := range incompatibilities(t, committed, generated) {
+		t.Errorf("incompatible with schema version 1: %s", problem)
+	}
+	t.Errorf("%s is out of date; run go generate ./pkg/api/v1", committedSchema)
+}
+
+func TestIncompatibilities(t *testing.T) {
+	old := `{"definitions": {"Stats": {"properties": {
+		"translations": {"type": "integer"},
+		"panics": {"type": "integer"},
+		"build": {"$ref": "#/definitions/Build"}
+	}}}}`
+	tests := []struct {
+		name    string
+		schema  string
+		problem []string
+	}{
+		{
+			name: "field added",
+			schema: `{"definitions": {"Stats": {"properties": {
+				"translations": {"type": "integer"},
+				"panics": {"type": "integer"},
+				"build": {"$ref": "#/definitions/Build"},
+				"languages": {"type": "array"}
+			}}}}`,
+		},
+		{
+			name: "field removed and retyped",
+			schema: `{"definitions": {"Stats": {"properties": {
+				"translations": {"type": "string"},
+				"build": {"$ref": "#/definitions/Build"}
+			}}}}`,
+			problem: []string{"Stats.panics was removed", "Stats.translations changed type"},
+		},
+		{
+			name:    "type removed",
+			schema:  `{"definitions": {}}`,
+			problem: []string{"Stats was removed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := incompatibilities(t, []byte(old), []byte(tt.schema))
+			if !reflect.DeepEqual(got, tt.problem) {
+				t.Errorf("incompatibilities = %q, want %q", got, tt.problem)
+			}
+		})
+	}
+}
+
+// incompatibilities lists the fields of the committed schema that the
+// generated one removed or gave a different type, sorted
+func incompatibilities(t *testing.T, committed, generated []byte) []string {
+	t.Helper()
+	type property struct {
+		Type any    `json:"type"`
+		Ref  string `json:"$ref"`
+	}
+	type document struct {
+		Definitions map[string]struct {
+			Properties map[string]property `json:"properties"`
+		} `json:"definitions"`
+	}
+	var before, after document
+	if err := json.Unmarshal(committed, &before); err != nil {
+		t.Fatalf("decoding the committed schema: %v", err)
+	}
+	if err := json.Unmarshal(generated, &after); err != nil {
+		t.Fatalf("decoding the generated schema: %v", err)
+	}
+
+	var problems []string
+	for name, definition := range before.Definitions {
+		now, ok := after.Definitions[name]
+		if !ok {
+			problems = append(problems, name+" was removed")
+			continue
+		}
+		for field, was := range definition.Properties {
+			is, ok := now.Properties[field]
+			switch {
+			case !ok:
+				problems = append(problems, name+"."+field+" was removed")
+			case !reflect.DeepEqual(was, is):
+				problems = append(problems, name+"."+field+" changed type")
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -1,30 +1,426 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// Supported values for OUTPUT_STYLE
+const (
+	OutputStyleTranslation = "translation"
+	OutputStyleVibeCheck   = "vibecheck"
+	OutputStyleBoth        = "both"
+)
+
+// Supported values for LLM_PROVIDER
+const (
+	LLMProviderOpenAI           = "openai"
+	LLMProviderOpenAICompatible = "openai-compatible"
+	LLMProviderAnthropic        = "anthropic"
+	LLMProviderOllama           = "ollama"
+)
+
+// Supported values for QUOTE_MODE
+const (
+	QuoteModeReference = "reference"
+	QuoteModeBoth      = "both"
+)
+
+// OutputLanguageMatch is the OUTPUT_LANGUAGE translating each message into
+// its own language
+const OutputLanguageMatch = "match"
+
+// Supported values for MODERATION_ACTION
+const (
+	ModerationActionSkip   = "skip"
+	ModerationActionNotice = "notice"
+)
+
+// DefaultOnboardingCardText introduces the bot the first time someone's
+// message is translated in a channel, overridable with ONBOARDING_CARD_TEXT
+const DefaultOnboardingCardText = "hey {{.User}}, this bot translated your message into Gen Alpha :sparkles: " +
+	"Wondering why? `/genalpha explain` tells you, `/genalpha help` lists what else it can do, and `/genalpha optout` makes it stop."
+
+// DefaultReplyTemplate posts the translation on its own, overridable with
+// REPLY_TEMPLATE
+const DefaultReplyTemplate = "{{.Translation}}"
+
+// IncludeOriginalReplyTemplate quotes the original message above the
+// translation, the default with INCLUDE_ORIGINAL=true
+const IncludeOriginalReplyTemplate = "{{quote .Original}}\n{{.Translation}}"
+
+// ReplyTemplateFuncs are the functions available to REPLY_TEMPLATE
+var ReplyTemplateFuncs = template.FuncMap{
+	// quote turns text into a Slack blockquote, line by line
+	"quote": func(text string) string {
+		return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	},
+}
+
+// Supported values for EDITED_MESSAGES
+const (
+	EditedMessagesUpdate = "update"
+	EditedMessagesThread = "thread"
+	EditedMessagesIgnore = "ignore"
 )
 
+// Default prompts for translations, overridable with OPENAI_SYSTEM_PROMPT
+// and OPENAI_USER_PROMPT_TEMPLATE
+const (
+	DefaultSystemPrompt = "You are a Gen Alpha language translator. Your job is to translate normal messages into Gen Alpha slang and expressions. " +
+		"Be creative, use current youth trends, emojis, and make it funny but still understandable."
+	DefaultUserPromptTemplate = "Translate the following message to Gen Alpha slang/language (TikTok style, with emojis, internet abbreviations, and current youth trends). " +
+		"Make it humorous but keep the original meaning. The message is from {{.Username}}: \"{{.Message}}\""
+)
+
+// WatchRule guarantees that matching messages are translated, bypassing
+// the channel and target user filters. Rules are given as a JSON array in
+// WATCH_RULES.
+type WatchRule struct {
+	// Channel is the channel ID the message must be posted in
+	Channel string `json:"channel"`
+	// Author is the user ID (U...) or bot ID (B...) that posted it
+	Author string `json:"author"`
+	// Window optionally limits the rule to a time of day, e.g.
+	// "09:25-09:40" in the server's local time
+	Window string `json:"window,omitempty"`
+	// Pattern is an optional regular expression the text must match
+	Pattern string `json:"pattern,omitempty"`
+
+	// Parsed Window, as offsets from midnight, and Pattern
+	WindowStart   time.Duration  `json:"-"`
+	WindowEnd     time.Duration  `json:"-"`
+	PatternRegexp *regexp.Regexp `json:"-"`
+}
+
+// Schedule limits automatic translations to active hours and days, set
+// with ACTIVE_HOURS, ACTIVE_DAYS and TIMEZONE
+type Schedule struct {
+	// Hours is false when translations run around the clock. Otherwise they
+	// run from HoursStart until HoursEnd, as offsets from midnight; a window
+	// ending before it starts crosses midnight.
+	Hours      bool
+	HoursStart time.Duration
+	HoursEnd   time.Duration
+
+	// Days translations run on, nil for every day. A window crossing
+	// midnight belongs to the day it starts on.
+	Days map[time.Weekday]bool
+
+	// Location the hours and days are in
+	Location *time.Location
+}
+
 // Config holds all configuration for the application
 type Config struct {
 	// Slack configuration
-	SlackBotToken     string
-	SlackAppToken     string
-	SlackChannelIDs   []string
-	SlackTargetUsers  []string
-	
+	SlackBotToken    string
+	SlackAppToken    string
+	SlackChannelIDs  []string
+	SlackTargetUsers []string
+	// SLACK_TARGET_USERS is "*": every user who isn't a bot is a target
+	SlackAllTargetUsers bool
+	// Members of these user groups are targets too, refreshed periodically
+	SlackTargetUsergroups    []string
+	UsergroupRefreshInterval time.Duration
+	// Channels and users never translated, even when included above
+	SlackExcludeChannelIDs   []string
+	SlackExcludeUsers        []string
+	AllChannelsWarnThreshold int
+	AllChannelsConfirm       bool
+	TriggerReaction          string
+	// Emoji the author of a message or an admin adds to its translation to
+	// delete it (without colons, empty turns it off), and whether Block Kit
+	// translations get a Remove button for the same
+	RemoveReaction    string
+	RemoveButton      bool
+	AdminUsers        []string
+	OptOutExemptUsers []string
+
+	// Which LLM translates: OpenAI itself, any API compatible with its chat
+	// completions at LLMBaseURL, Anthropic, or a local Ollama server
+	LLMProvider     string
+	LLMBaseURL      string
+	LLMAPIKey       string
+	LLMAPIKeyHeader string
+	LLMModel        string
+
 	// OpenAI configuration
-	OpenAIAPIKey      string
-	OpenAIModel       string
-	OpenAIMaxTokens   int
+	OpenAIAPIKey string
+	OpenAIModel  string
+	// Model used when OpenAIModel is out of quota or doesn't exist (empty
+	// turns falling back off)
+	OpenAIFallbackModel string
+	// Organization and project requests are billed to, for accounts in
+	// several of them
+	OpenAIOrgID     string
+	OpenAIProjectID string
+	OpenAIMaxTokens int
+	// Translations are capped at twice the message's length, but at no
+	// fewer than OpenAIMinTokens; one cut off is retried at
+	// OpenAIMaxTokens with OpenAIRetryTruncated
+	OpenAIMinTokens      int
+	OpenAIRetryTruncated bool
+	OpenAIMaxAttempts    int
+	// Timeout of a single OpenAI, OpenAI-compatible or Anthropic request
+	OpenAITimeout time.Duration
+	// An extra root CA for outgoing requests, e.g. of a corporate proxy,
+	// and whether certificates are checked at all
+	OpenAICACertPath         string
+	OpenAIInsecureSkipVerify bool
+	OpenAISystemPrompt       string
+	OpenAIUserPromptTemplate *template.Template
+	OpenAICompressRequests   bool
+	// Sampling parameters, nil when unset
+	OpenAITemperature      *float64
+	OpenAITopP             *float64
+	OpenAIFrequencyPenalty *float64
+	OpenAIPresencePenalty  *float64
+	// Translations asked for at once, one posted and the others used by
+	// re-rolls first
+	OpenAICandidates int
+	// Stream translations, editing them into Slack as they grow
+	OpenAIStream bool
+
+	// Price per 1K prompt and completion tokens, for estimating the spend;
+	// nil when unset
+	LLMPromptPricePer1K     *float64
+	LLMCompletionPricePer1K *float64
+
+	// Daily caps on LLM usage, in tokens and in estimated dollars (0 is no
+	// cap), and the channel told when one is reached
+	DailyTokenBudget   int
+	DailyCostBudget    float64
+	BudgetAlertChannel string
+
+	// Translations of identical messages are reused for TranslationCacheTTL,
+	// up to TranslationCacheSize of them
+	TranslationCache     bool
+	TranslationCacheSize int
+	TranslationCacheTTL  time.Duration
+
+	// Translations are checked with OpenAI's moderation endpoint before
+	// they're posted. One scoring ModerationThreshold or more in any
+	// category is skipped, or replaced with a notice.
+	Moderation          bool
+	ModerationThreshold float64
+	ModerationAction    string
+
+	// The team's own terms, term → meaning in a YAML or JSON file, added to
+	// the system prompt within GlossaryMaxTokens
+	GlossaryPath      string
+	GlossaryMaxTokens int
+
+	// Anthropic configuration
+	AnthropicAPIKey string
+	AnthropicModel  string
+
+	// Ollama configuration. Local models are slow, so requests get their
+	// own timeout.
+	OllamaHost    string
+	OllamaModel   string
+	OllamaTimeout time.Duration
+
+	// Translation configuration
+	QuoteMode string
+	// OutputLanguage is the ISO 639-1 code of the language translations
+	// are written in, or OutputLanguageMatch for the message's own
+	OutputLanguage      string
+	OutputStyle         string
+	ChannelOutputStyles map[string]string
+	TranslationStyle    string
+	ChannelStyles       map[string]string
+
+	// Accessible output (few emoji, no stretched or all-caps words),
+	// globally or for the listed channels
+	AccessibleOutput         bool
+	AccessibleOutputChannels []string
+
+	// Translations that name nobody, in the prompt or the reply, globally
+	// or for the listed channels
+	Anonymous         bool
+	AnonymousChannels []string
+
+	// Approval before posting, globally or for the listed channels. The
+	// approver defaults to the author of the translated message.
+	ConfirmBeforePost         bool
+	ConfirmBeforePostChannels []string
+	ConfirmApprover           string
+
+	// Post replies as plain text instead of Block Kit
+	PlainTextReplies bool
+
+	// How often a translation can be re-rolled with its button, 0 for no
+	// button
+	MaxRerolls int
+
+	// What happens when a translated message is edited
+	EditedMessages string
+
+	// Token budget for earlier thread messages sent as context with
+	// replies in threads, 0 to send none
+	ThreadContextTokens int
+
+	// One-time card introducing the bot, rendered with {{.User}} and
+	// {{.Channel}}; nil when turned off
+	OnboardingCard *template.Template
+
+	// Layout of a reply, rendered with {{.DisplayName}}, {{.Translation}},
+	// {{.Original}} and {{.Channel}}
+	ReplyTemplate *template.Template
+
+	// Post a placeholder right away and edit the translation into it
+	TranslationPlaceholder bool
+
+	// React to messages with ⏳ while translating, then ✅ or ❌
+	ProgressReactions bool
+
+	// Channels where @channel/@here announcements get a TL;DR and a
+	// translation in their thread
+	AnnouncementTLDRChannels []string
+
+	// Messages not worth translating: shorter than a number of characters
+	// or words (0 turns the check off), only emoji, or only links
+	MinMessageLength int
+	MinMessageWords  int
+	SkipEmojiOnly    bool
+	SkipURLOnly      bool
+
+	// Messages longer than MaxMessageTokens (estimated) are cut short at a
+	// sentence before translating, and those longer than SkipMessageTokens
+	// aren't translated at all; 0 turns either off
+	MaxMessageTokens  int
+	SkipMessageTokens int
+	// Messages longer than SummarizeMessageTokens are summarized and the
+	// summary translated instead; 0 turns it off
+	SummarizeMessageTokens int
+
+	// Messages matching any SkipPatterns aren't translated, and with
+	// RequirePatterns only those matching one of them are
+	SkipPatterns    []*regexp.Regexp
+	RequirePatterns []*regexp.Regexp
+
+	// Hourly translation caps per user and across all users, 0 for no cap
+	MaxTranslationsPerUserPerHour int
+	MaxTranslationsPerHour        int
+
+	// Minimum time between translations in a channel, 0 for none, and
+	// whether the latest message skipped meanwhile is translated after
+	ChannelCooldown      time.Duration
+	ChannelCooldownQueue bool
+
+	// Messages a user sends in a channel within DebounceWindow of each
+	// other are translated together, up to DebounceMaxLength characters
+	DebounceWindow    time.Duration
+	DebounceMaxLength int
+
+	// When automatic translations run
+	Schedule Schedule
+
+	// Channel a daily recap of the translations is posted to (empty turns
+	// it off), at DigestTime after midnight in DigestLocation
+	DigestChannel  string
+	DigestTime     time.Duration
+	DigestLocation *time.Location
+
+	// Message subtypes ignored on top of the built-in ones, like
+	// channel_join and channel_topic
+	SkippedSubtypes []string
+
+	// How long the socket mode connection may be down before /health
+	// reports the bot unhealthy
+	HealthGracePeriod time.Duration
+
+	// Channel told when the socket mode connection is back after at least
+	// ConnectionAlertFailures failed attempts in a row (empty turns it off)
+	ConnectionAlertChannel  string
+	ConnectionAlertFailures int
+
+	// Channel told about messages the bot failed to translate, at most
+	// once per AdminAlertInterval for the same error (empty turns it off)
+	AdminChannelID     string
+	AdminAlertInterval time.Duration
+
+	// Whether messages sent while the bot was disconnected are translated
+	// after reconnecting, as long as they're no older than CatchUpMaxAge,
+	// and at most CatchUpMaxMessages per channel
+	CatchUp            bool
+	CatchUpMaxAge      time.Duration
+	CatchUpMaxMessages int
+
+	// Messages that are always translated
+	WatchRules []WatchRule
+
+	// Retention: the bot deletes its own translations after the TTL
+	// (0 keeps them forever)
+	TranslationTTL         time.Duration
+	ChannelTranslationTTLs map[string]time.Duration
+
+	// Audit log of every translation (empty turns it off), the size at
+	// which it is rotated (0 for no cap) and whether message texts are left
+	// out
+	AuditLogPath     string
+	AuditLogMaxBytes int64
+	AuditLogOmitText bool
+
+	// SQLite database translations are recorded in for the history and
+	// leaderboard commands (empty keeps no history)
+	DatabasePath string
+
+	// State store file (empty keeps state in memory only)
+	StateFile string
+
+	// Translation concurrency: adaptive between min and max unless fixed is set
+	TranslationConcurrencyMin   int
+	TranslationConcurrencyMax   int
+	TranslationConcurrencyFixed int
+	TranslationLatencyTarget    time.Duration
+
+	// Metrics configuration
+	MetricsMaxSeries int
+
+	// How long users.info results are cached
+	UserCacheTTL time.Duration
+
+	// How long conversations.info results are cached
+	ChannelCacheTTL time.Duration
+
+	// Startup configuration
+	StartupReadyTimeout time.Duration
+
+	// Event processing configuration
+	WorkerPoolSize       int
+	PreserveChannelOrder bool
+	// Deadline of processing a single message, 0 for none
+	MessageProcessingTimeout time.Duration
+	// How long messages being processed at shutdown get to finish
+	ShutdownGrace time.Duration
 
 	// App configuration
-	Debug             bool
-	Logs              bool
+	Debug bool
+	Logs  bool
+
+	// LogLevel is the minimum level logged. Without LOG_LEVEL it is info,
+	// or debug when DEBUG is set.
+	LogLevel logging.Level
+
+	// LogFormat is text (the default) or json
+	LogFormat string
 }
 
 // Load reads configuration from environment variables
@@ -52,13 +448,85 @@ func Load() (*Config, error) {
 	// }
 
 	targetUsers := os.Getenv("SLACK_TARGET_USERS")
-	if targetUsers == "" {
-		return nil, errors.New("SLACK_TARGET_USERS environment variable is required")
+	targetUsergroups := os.Getenv("SLACK_TARGET_USERGROUPS")
+	if targetUsers == "" && targetUsergroups == "" {
+		return nil, errors.New("SLACK_TARGET_USERS or SLACK_TARGET_USERGROUPS environment variable is required")
 	}
 
+	// "*" translates everyone, so listing users as well would be ambiguous
+	allTargetUsers, listedUsers := false, 0
+	for _, user := range strings.Split(targetUsers, ",") {
+		switch strings.TrimSpace(user) {
+		case "":
+		case "*":
+			allTargetUsers = true
+		default:
+			listedUsers++
+		}
+	}
+	if allTargetUsers && listedUsers > 0 {
+		return nil, fmt.Errorf("SLACK_TARGET_USERS must be either * or a list of users, not both, got %q", targetUsers)
+	}
+
+	// User group membership changes, so it's fetched again every interval
+	var usergroups []string
+	if targetUsergroups != "" {
+		if allTargetUsers {
+			return nil, errors.New("SLACK_TARGET_USERGROUPS must not be set when SLACK_TARGET_USERS is *")
+		}
+		usergroups = strings.Split(targetUsergroups, ",")
+	}
+	usergroupRefreshInterval, err := getEnvDuration("USERGROUP_REFRESH_INTERVAL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if usergroupRefreshInterval <= 0 {
+		return nil, fmt.Errorf("USERGROUP_REFRESH_INTERVAL must be positive, got %s", usergroupRefreshInterval)
+	}
+
+	// Exclusions win over the include lists above
+	var excludeChannelIDs, excludeUsers []string
+	if value := os.Getenv("SLACK_EXCLUDE_CHANNEL_IDS"); value != "" {
+		excludeChannelIDs = strings.Split(value, ",")
+	}
+	if value := os.Getenv("SLACK_EXCLUDE_USERS"); value != "" {
+		excludeUsers = strings.Split(value, ",")
+	}
+
+	// The LLM provider, each with its own required settings
+	llmProvider := strings.ToLower(os.Getenv("LLM_PROVIDER"))
+	if llmProvider == "" {
+		llmProvider = LLMProviderOpenAI
+	}
 	openAIKey := os.Getenv("OPENAI_API_KEY")
-	if openAIKey == "" {
-		return nil, errors.New("OPENAI_API_KEY environment variable is required")
+	anthropicKey := os.Getenv("ANTHROPIC_API_KEY")
+	llmBaseURL := os.Getenv("LLM_BASE_URL")
+	llmAPIKey := os.Getenv("LLM_API_KEY")
+	llmModel := os.Getenv("LLM_MODEL")
+	llmAPIKeyHeader := os.Getenv("LLM_API_KEY_HEADER")
+	if llmAPIKeyHeader == "" {
+		llmAPIKeyHeader = "Authorization"
+	}
+	switch llmProvider {
+	case LLMProviderOpenAI:
+		if openAIKey == "" {
+			return nil, errors.New("OPENAI_API_KEY environment variable is required")
+		}
+	case LLMProviderOpenAICompatible:
+		if llmBaseURL == "" || llmModel == "" {
+			return nil, fmt.Errorf("LLM_BASE_URL and LLM_MODEL are required with LLM_PROVIDER=%s", llmProvider)
+		}
+		if parsed, err := url.Parse(llmBaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("LLM_BASE_URL must be an absolute URL like \"https://openrouter.ai/api/v1\", got %q", llmBaseURL)
+		}
+	case LLMProviderAnthropic:
+		if anthropicKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required with LLM_PROVIDER=%s", llmProvider)
+		}
+	case LLMProviderOllama:
+	default:
+		return nil, fmt.Errorf("LLM_PROVIDER must be %q, %q, %q or %q, got %q",
+			LLMProviderOpenAI, LLMProviderOpenAICompatible, LLMProviderAnthropic, LLMProviderOllama, llmProvider)
 	}
 
 	// Set defaults for optional values
@@ -66,25 +534,1082 @@ func Load() (*Config, error) {
 	if openAIModel == "" {
 		openAIModel = "gpt-4"
 	}
+	anthropicModel := os.Getenv("ANTHROPIC_MODEL")
+	if anthropicModel == "" {
+		anthropicModel = "claude-sonnet-4-5"
+	}
+	// OLLAMA_HOST is shared with the ollama CLI, which also accepts a bare
+	// host:port
+	ollamaHost := os.Getenv("OLLAMA_HOST")
+	if ollamaHost == "" {
+		ollamaHost = "http://localhost:11434"
+	} else if !strings.Contains(ollamaHost, "://") {
+		ollamaHost = "http://" + ollamaHost
+	}
+	if parsed, err := url.Parse(ollamaHost); llmProvider == LLMProviderOllama && (err != nil || parsed.Host == "") {
+		return nil, fmt.Errorf("OLLAMA_HOST must be a URL like \"http://localhost:11434\", got %q", ollamaHost)
+	}
+	ollamaModel := os.Getenv("OLLAMA_MODEL")
+	if ollamaModel == "" {
+		ollamaModel = "llama3.2"
+	}
+	ollamaTimeout, err := getEnvDuration("OLLAMA_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if ollamaTimeout <= 0 {
+		return nil, fmt.Errorf("OLLAMA_TIMEOUT must be positive, got %s", ollamaTimeout)
+	}
 
 	// Debug flag
 	debug := os.Getenv("DEBUG") == "true"
-	
+
 	// Logs flag
 	logs := os.Getenv("LOGS") == "true"
 
-	// Maximum tokens for OpenAI response
-	openAIMaxTokens := 1024
+	// Log level; DEBUG=true alone still means debug output
+	logLevel := logging.LevelInfo
+	if debug {
+		logLevel = logging.LevelDebug
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		level, err := logging.ParseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+		logLevel = level
+	}
+
+	// Log format, plain text lines unless JSON is asked for
+	logFormat := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	switch logFormat {
+	case "":
+		logFormat = logging.FormatText
+	case logging.FormatText, logging.FormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q, expected %s or %s", logFormat, logging.FormatText, logging.FormatJSON)
+	}
+
+	// Maximum tokens of a response, from any provider
+	openAIMaxTokens, err := getEnvInt("OPENAI_MAX_TOKENS", 1024)
+	if err != nil {
+		return nil, err
+	}
+	if openAIMaxTokens < 1 {
+		return nil, fmt.Errorf("OPENAI_MAX_TOKENS must be at least 1, got %d", openAIMaxTokens)
+	}
+	openAIMinTokens, err := getEnvInt("OPENAI_MIN_TOKENS", min(128, openAIMaxTokens))
+	if err != nil {
+		return nil, err
+	}
+	if openAIMinTokens < 1 || openAIMinTokens > openAIMaxTokens {
+		return nil, fmt.Errorf("OPENAI_MIN_TOKENS must be between 1 and OPENAI_MAX_TOKENS (%d), got %d", openAIMaxTokens, openAIMinTokens)
+	}
+	openAIRetryTruncated := os.Getenv("OPENAI_RETRY_TRUNCATED") != "false"
+
+	// Sampling parameters of OpenAI requests; unset ones aren't sent, and
+	// the temperature falls back to each prompt's own
+	openAITemperature, err := getEnvFloat("OPENAI_TEMPERATURE", 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	openAITopP, err := getEnvFloat("OPENAI_TOP_P", 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	openAIFrequencyPenalty, err := getEnvFloat("OPENAI_FREQUENCY_PENALTY", -2, 2)
+	if err != nil {
+		return nil, err
+	}
+	openAIPresencePenalty, err := getEnvFloat("OPENAI_PRESENCE_PENALTY", -2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token prices, to estimate what the bot costs
+	promptPrice, err := getEnvFloat("LLM_PROMPT_PRICE_PER_1K", 0, math.Inf(1))
+	if err != nil {
+		return nil, err
+	}
+	completionPrice, err := getEnvFloat("LLM_COMPLETION_PRICE_PER_1K", 0, math.Inf(1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache of translations, on unless turned off
+	translationCache := os.Getenv("TRANSLATION_CACHE") != "false"
+	translationCacheSize, err := getEnvInt("TRANSLATION_CACHE_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if translationCacheSize < 1 {
+		return nil, fmt.Errorf("TRANSLATION_CACHE_SIZE must be at least 1, got %d", translationCacheSize)
+	}
+	translationCacheTTL, err := getEnvDuration("TRANSLATION_CACHE_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if translationCacheTTL <= 0 {
+		return nil, fmt.Errorf("TRANSLATION_CACHE_TTL must be positive, got %s", translationCacheTTL)
+	}
+
+	// Daily budget, reset at midnight in TIMEZONE
+	dailyTokenBudget, err := getEnvInt("OPENAI_DAILY_TOKEN_BUDGET", 0)
+	if err != nil {
+		return nil, err
+	}
+	if dailyTokenBudget < 0 {
+		return nil, fmt.Errorf("OPENAI_DAILY_TOKEN_BUDGET must not be negative, got %d", dailyTokenBudget)
+	}
+	var dailyCostBudget float64
+	if budget, err := getEnvFloat("OPENAI_DAILY_COST_BUDGET", 0, math.Inf(1)); err != nil {
+		return nil, err
+	} else if budget != nil && *budget > 0 {
+		if promptPrice == nil && completionPrice == nil {
+			return nil, errors.New("OPENAI_DAILY_COST_BUDGET needs LLM_PROMPT_PRICE_PER_1K or LLM_COMPLETION_PRICE_PER_1K to estimate the cost")
+		}
+		dailyCostBudget = *budget
+	}
+
+	// Attempts per OpenAI request, including retries on 429, 5xx and
+	// network errors
+	openAIMaxAttempts, err := getEnvInt("OPENAI_MAX_ATTEMPTS", 3)
+	if err != nil {
+		return nil, err
+	}
+	if openAIMaxAttempts < 1 {
+		return nil, fmt.Errorf("OPENAI_MAX_ATTEMPTS must be at least 1, got %d", openAIMaxAttempts)
+	}
+	openAITimeout, err := getEnvDuration("OPENAI_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if openAITimeout <= 0 {
+		return nil, fmt.Errorf("OPENAI_TIMEOUT must be positive, got %s", openAITimeout)
+	}
+
+	// Several translations per request, for variety
+	openAICandidates, err := getEnvInt("OPENAI_CANDIDATES", 1)
+	if err != nil {
+		return nil, err
+	}
+	if openAICandidates < 1 || openAICandidates > 10 {
+		return nil, fmt.Errorf("OPENAI_CANDIDATES must be between 1 and 10, got %d", openAICandidates)
+	}
+
+	// Only the chat completions API is streamed
+	openAIStream := os.Getenv("OPENAI_STREAM") == "true"
+	if openAIStream && llmProvider != LLMProviderOpenAI && llmProvider != LLMProviderOpenAICompatible {
+		return nil, fmt.Errorf("OPENAI_STREAM needs LLM_PROVIDER %s or %s, got %s", LLMProviderOpenAI, LLMProviderOpenAICompatible, llmProvider)
+	}
+
+	// Prompts, so the bot can be repurposed for other personas
+	systemPrompt := os.Getenv("OPENAI_SYSTEM_PROMPT")
+	if systemPrompt == "" {
+		systemPrompt = DefaultSystemPrompt
+	}
+	userPromptTemplate, err := parsePromptTemplate(os.Getenv("OPENAI_USER_PROMPT_TEMPLATE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Gzip request bodies, which helps with long prompts
+	openAICompressRequests := os.Getenv("OPENAI_COMPRESS_REQUESTS") == "true"
+
+	// Moderation of translations, on by default whenever there's an OpenAI
+	// API key to call the endpoint with
+	moderationSetting := os.Getenv("MODERATION")
+	if moderationSetting == "true" && openAIKey == "" {
+		return nil, errors.New("MODERATION=true needs OPENAI_API_KEY")
+	}
+	moderation := moderationSetting != "false" && openAIKey != ""
+	moderationThreshold := 0.5
+	if threshold, err := getEnvFloat("MODERATION_THRESHOLD", 0, 1); err != nil {
+		return nil, err
+	} else if threshold != nil {
+		moderationThreshold = *threshold
+	}
+	moderationAction := os.Getenv("MODERATION_ACTION")
+	if moderationAction == "" {
+		moderationAction = ModerationActionSkip
+	}
+	if moderationAction != ModerationActionSkip && moderationAction != ModerationActionNotice {
+		return nil, fmt.Errorf("MODERATION_ACTION must be %q or %q, got %q", ModerationActionSkip, ModerationActionNotice, moderationAction)
+	}
+
+	// A glossary of house slang, kept small next to the prompt
+	glossaryMaxTokens, err := getEnvInt("GLOSSARY_MAX_TOKENS", 500)
+	if err != nil {
+		return nil, err
+	}
+	if glossaryMaxTokens < 1 {
+		return nil, fmt.Errorf("GLOSSARY_MAX_TOKENS must be at least 1, got %d", glossaryMaxTokens)
+	}
+
+	// How shared/forwarded messages are handled: "reference" only uses the
+	// quote as context, "both" also translates the quote itself
+	quoteMode := os.Getenv("QUOTE_MODE")
+	if quoteMode == "" {
+		quoteMode = QuoteModeReference
+	}
+	if quoteMode != QuoteModeReference && quoteMode != QuoteModeBoth {
+		return nil, fmt.Errorf("QUOTE_MODE must be %q or %q, got %q", QuoteModeReference, QuoteModeBoth, quoteMode)
+	}
+
+	// Language translations are written in, whatever the message's
+	outputLanguage := strings.ToLower(strings.TrimSpace(os.Getenv("OUTPUT_LANGUAGE")))
+	if outputLanguage == "" {
+		outputLanguage = "en"
+	}
+	if outputLanguage != OutputLanguageMatch && (len(outputLanguage) != 2 || strings.Trim(outputLanguage, "abcdefghijklmnopqrstuvwxyz") != "") {
+		return nil, fmt.Errorf("OUTPUT_LANGUAGE must be a two-letter language code like en or %q, got %q", OutputLanguageMatch, outputLanguage)
+	}
+
+	// All-channels mode safety: above the threshold, starting requires an
+	// explicit confirmation
+	allChannelsWarnThreshold, err := getEnvInt("ALL_CHANNELS_WARN_THRESHOLD", 100)
+	if err != nil {
+		return nil, err
+	}
+	allChannelsConfirm := os.Getenv("ALL_CHANNELS_CONFIRM") == "true"
+
+	// Emoji that triggers a translation when added to any message (without colons)
+	triggerReaction := strings.Trim(strings.TrimSpace(os.Getenv("TRIGGER_REACTION")), ":")
+
+	// Translations can be deleted by their message's author or an admin
+	removeReaction, ok := os.LookupEnv("REMOVE_REACTION")
+	if !ok {
+		removeReaction = "x"
+	}
+	removeReaction = strings.Trim(strings.TrimSpace(removeReaction), ":")
+	removeButton := os.Getenv("REMOVE_BUTTON") != "false"
+
+	// Users allowed to run admin-only slash commands
+	var adminUsers []string
+	if value := os.Getenv("ADMIN_USERS"); value != "" {
+		adminUsers = strings.Split(value, ",")
+	}
+
+	// Users who can't opt out of translations
+	var optOutExemptUsers []string
+	if value := os.Getenv("OPT_OUT_EXEMPT_USERS"); value != "" {
+		optOutExemptUsers = strings.Split(value, ",")
+	}
+
+	// Output style, globally and per channel
+	outputStyle := os.Getenv("OUTPUT_STYLE")
+	if outputStyle == "" {
+		outputStyle = OutputStyleTranslation
+	}
+	if !validOutputStyle(outputStyle) {
+		return nil, fmt.Errorf("OUTPUT_STYLE must be %q, %q or %q, got %q",
+			OutputStyleTranslation, OutputStyleVibeCheck, OutputStyleBoth, outputStyle)
+	}
+	channelOutputStyles, err := parseChannelMap("CHANNEL_OUTPUT_STYLES")
+	if err != nil {
+		return nil, err
+	}
+	for channelID, style := range channelOutputStyles {
+		if !validOutputStyle(style) {
+			return nil, fmt.Errorf("CHANNEL_OUTPUT_STYLES: unknown style %q for channel %s", style, channelID)
+		}
+	}
+
+	// Translation style (voice), globally and per channel. Names are
+	// checked against the style registry when the bot starts.
+	translationStyle := os.Getenv("TRANSLATION_STYLE")
+	if translationStyle == "" {
+		translationStyle = "genalpha"
+	}
+	channelStyles, err := parseChannelMap("CHANNEL_STYLES")
+	if err != nil {
+		return nil, err
+	}
+
+	// Screen-reader friendly output, globally or per channel
+	accessibleOutput := os.Getenv("ACCESSIBLE_OUTPUT") == "true"
+	var accessibleOutputChannels []string
+	if value := os.Getenv("ACCESSIBLE_OUTPUT_CHANNELS"); value != "" {
+		accessibleOutputChannels = strings.Split(value, ",")
+	}
+
+	// Anonymous translations, globally or per channel
+	anonymous := os.Getenv("ANONYMOUS") == "true"
+	var anonymousChannels []string
+	if value := os.Getenv("ANONYMOUS_CHANNELS"); value != "" {
+		anonymousChannels = strings.Split(value, ",")
+	}
+
+	// Translations can be held for approval before they're posted
+	confirmBeforePost := os.Getenv("CONFIRM_BEFORE_POST") == "true"
+	var confirmBeforePostChannels []string
+	if value := os.Getenv("CONFIRM_BEFORE_POST_CHANNELS"); value != "" {
+		confirmBeforePostChannels = strings.Split(value, ",")
+	}
+	confirmApprover := strings.TrimSpace(os.Getenv("CONFIRM_APPROVER"))
+
+	// Edited messages update the earlier translation, get a new one in the
+	// thread, or are ignored
+	editedMessages := os.Getenv("EDITED_MESSAGES")
+	if editedMessages == "" {
+		editedMessages = EditedMessagesUpdate
+	}
+	if editedMessages != EditedMessagesUpdate && editedMessages != EditedMessagesThread && editedMessages != EditedMessagesIgnore {
+		return nil, fmt.Errorf("EDITED_MESSAGES must be %q, %q or %q, got %q",
+			EditedMessagesUpdate, EditedMessagesThread, EditedMessagesIgnore, editedMessages)
+	}
+
+	// Replies in threads can be translated with the thread as context
+	threadContextTokens, err := getEnvInt("THREAD_CONTEXT_TOKENS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies are laid out by a template
+	replyTemplate, err := parseReplyTemplate(os.Getenv("REPLY_TEMPLATE"), os.Getenv("INCLUDE_ORIGINAL") == "true")
+	if err != nil {
+		return nil, err
+	}
+
+	// New users get a one-time introduction unless it's turned off
+	var onboardingCard *template.Template
+	if os.Getenv("ONBOARDING_CARD") != "false" {
+		onboardingCard, err = parseOnboardingCard(os.Getenv("ONBOARDING_CARD_TEXT"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Replies use Block Kit unless plain text is preferred
+	plainTextReplies := os.Getenv("PLAIN_TEXT_REPLIES") == "true"
+
+	// Block Kit replies get a button to translate the message again
+	maxRerolls, err := getEnvInt("MAX_REROLLS", 3)
+	if err != nil {
+		return nil, err
+	}
+	if maxRerolls < 0 {
+		return nil, fmt.Errorf("MAX_REROLLS must not be negative, got %d", maxRerolls)
+	}
+
+	// A placeholder shows the bot is working while OpenAI is slow
+	translationPlaceholder := os.Getenv("TRANSLATION_PLACEHOLDER") == "true"
+
+	// Reactions are a lighter way to show progress
+	progressReactions := os.Getenv("PROGRESS_REACTIONS") == "true"
+
+	// Announcements can be summarized per channel
+	var announcementTLDRChannels []string
+	if value := os.Getenv("ANNOUNCEMENT_TLDR_CHANNELS"); value != "" {
+		announcementTLDRChannels = strings.Split(value, ",")
+	}
+
+	// Trivial messages can be skipped, each check on its own
+	minMessageLength, err := getEnvInt("MIN_MESSAGE_LENGTH", 0)
+	if err != nil {
+		return nil, err
+	}
+	minMessageWords, err := getEnvInt("MIN_MESSAGE_WORDS", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxMessageTokens, err := getEnvInt("MAX_MESSAGE_TOKENS", 2000)
+	if err != nil {
+		return nil, err
+	}
+	if maxMessageTokens < 0 {
+		return nil, fmt.Errorf("MAX_MESSAGE_TOKENS must not be negative, got %d", maxMessageTokens)
+	}
+	skipMessageTokens, err := getEnvInt("SKIP_MESSAGE_TOKENS", 8000)
+	if err != nil {
+		return nil, err
+	}
+	if skipMessageTokens < 0 {
+		return nil, fmt.Errorf("SKIP_MESSAGE_TOKENS must not be negative, got %d", skipMessageTokens)
+	}
+	if skipMessageTokens > 0 && skipMessageTokens <= maxMessageTokens {
+		return nil, fmt.Errorf("SKIP_MESSAGE_TOKENS must be greater than MAX_MESSAGE_TOKENS (%d), got %d", maxMessageTokens, skipMessageTokens)
+	}
+	summarizeMessageTokens, err := getEnvInt("SUMMARIZE_MESSAGE_TOKENS", 0)
+	if err != nil {
+		return nil, err
+	}
+	if summarizeMessageTokens < 0 {
+		return nil, fmt.Errorf("SUMMARIZE_MESSAGE_TOKENS must not be negative, got %d", summarizeMessageTokens)
+	}
+	if summarizeMessageTokens > 0 && skipMessageTokens > 0 && summarizeMessageTokens >= skipMessageTokens {
+		return nil, fmt.Errorf("SUMMARIZE_MESSAGE_TOKENS must be less than SKIP_MESSAGE_TOKENS (%d), got %d", skipMessageTokens, summarizeMessageTokens)
+	}
+	skipEmojiOnly := os.Getenv("SKIP_EMOJI_ONLY") == "true"
+	skipURLOnly := os.Getenv("SKIP_URL_ONLY") == "true"
+	skipPatterns, err := parsePatterns("SKIP_PATTERNS", os.Getenv("SKIP_PATTERNS"))
+	if err != nil {
+		return nil, err
+	}
+	requirePatterns, err := parsePatterns("REQUIRE_PATTERNS", os.Getenv("REQUIRE_PATTERNS"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Translations can be capped per hour
+	maxTranslationsPerUserPerHour, err := getEnvInt("MAX_TRANSLATIONS_PER_USER_PER_HOUR", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxTranslationsPerHour, err := getEnvInt("MAX_TRANSLATIONS_PER_HOUR", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Channels can cool down between translations
+	channelCooldown, err := getEnvDuration("CHANNEL_COOLDOWN", 0)
+	if err != nil {
+		return nil, err
+	}
+	channelCooldownQueue := os.Getenv("CHANNEL_COOLDOWN_QUEUE") == "true"
+
+	// Fragments sent in quick succession can be combined
+	debounceWindow, err := getEnvDuration("DEBOUNCE_WINDOW", 0)
+	if err != nil {
+		return nil, err
+	}
+	if debounceWindow < 0 {
+		return nil, fmt.Errorf("DEBOUNCE_WINDOW must not be negative, got %s", debounceWindow)
+	}
+	debounceMaxLength, err := getEnvInt("DEBOUNCE_MAX_LENGTH", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if debounceMaxLength < 1 {
+		return nil, fmt.Errorf("DEBOUNCE_MAX_LENGTH must be at least 1, got %d", debounceMaxLength)
+	}
+
+	// Quiet hours and days
+	schedule, err := parseSchedule(os.Getenv("ACTIVE_HOURS"), os.Getenv("ACTIVE_DAYS"), os.Getenv("TIMEZONE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// A daily digest can recap the translations
+	digestTimeValue := os.Getenv("DIGEST_TIME")
+	if digestTimeValue == "" {
+		digestTimeValue = "17:00"
+	}
+	digestTime, err := parseTimeOfDay(digestTimeValue)
+	if err != nil {
+		return nil, fmt.Errorf("DIGEST_TIME must look like \"17:00\", got %q", digestTimeValue)
+	}
+	digestLocation := schedule.Location
+	if timezone := strings.TrimSpace(os.Getenv("DIGEST_TIMEZONE")); timezone != "" {
+		if digestLocation, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("DIGEST_TIMEZONE: unknown time zone %q: %w", timezone, err)
+		}
+	}
+
+	// Translations can be audited to a JSONL file
+	auditLogMaxSizeMB, err := getEnvInt("AUDIT_LOG_MAX_SIZE_MB", 100)
+	if err != nil {
+		return nil, err
+	}
+	if auditLogMaxSizeMB < 0 {
+		return nil, fmt.Errorf("AUDIT_LOG_MAX_SIZE_MB must not be negative, got %d", auditLogMaxSizeMB)
+	}
+	auditLogOmitText := os.Getenv("AUDIT_LOG_OMIT_TEXT") == "true"
+
+	// Housekeeping messages never need translating
+	var skippedSubtypes []string
+	if value := os.Getenv("SKIP_MESSAGE_SUBTYPES"); value != "" {
+		skippedSubtypes = strings.Split(value, ",")
+	}
+
+	// Reconnects are routine, so /health only fails after a grace period
+	healthGracePeriod, err := getEnvDuration("HEALTH_GRACE_PERIOD", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	// A flapping network is reported once it's over, not on every failure
+	connectionAlertFailures, err := getEnvInt("CONNECTION_ALERT_FAILURES", 5)
+	if err != nil {
+		return nil, err
+	}
+	if connectionAlertFailures < 1 {
+		return nil, fmt.Errorf("CONNECTION_ALERT_FAILURES must be at least 1, got %d", connectionAlertFailures)
+	}
+
+	// One broken channel or API key would otherwise alert on every message
+	adminAlertInterval, err := getEnvDuration("ADMIN_ALERT_INTERVAL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if adminAlertInterval <= 0 {
+		return nil, fmt.Errorf("ADMIN_ALERT_INTERVAL must be positive, got %s", adminAlertInterval)
+	}
+
+	// Catching up is bounded so a long outage doesn't flood channels
+	catchUp := os.Getenv("CATCHUP") == "true"
+	catchUpMaxAge, err := getEnvDuration("CATCHUP_MAX_AGE", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	catchUpMaxMessages, err := getEnvInt("CATCHUP_MAX_MESSAGES", 20)
+	if err != nil {
+		return nil, err
+	}
+	if catchUpMaxMessages < 1 || catchUpMaxMessages > 200 {
+		return nil, fmt.Errorf("CATCHUP_MAX_MESSAGES must be between 1 and 200, got %d", catchUpMaxMessages)
+	}
+
+	// Watch rules guarantee translation of specific messages
+	watchRules, err := parseWatchRules(os.Getenv("WATCH_RULES"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Translation retention, globally and per channel
+	translationTTL, err := getEnvDuration("TRANSLATION_TTL", 0)
+	if err != nil {
+		return nil, err
+	}
+	channelTTLValues, err := parseChannelMap("CHANNEL_TRANSLATION_TTLS")
+	if err != nil {
+		return nil, err
+	}
+	channelTranslationTTLs := make(map[string]time.Duration, len(channelTTLValues))
+	for channelID, value := range channelTTLValues {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("CHANNEL_TRANSLATION_TTLS: invalid duration %q for channel %s", value, channelID)
+		}
+		channelTranslationTTLs[channelID] = ttl
+	}
+
+	stateFile := os.Getenv("STATE_FILE")
+
+	// Adaptive concurrency for translation requests
+	concurrencyMin, err := getEnvInt("TRANSLATION_CONCURRENCY_MIN", 1)
+	if err != nil {
+		return nil, err
+	}
+	concurrencyMax, err := getEnvInt("TRANSLATION_CONCURRENCY_MAX", 4)
+	if err != nil {
+		return nil, err
+	}
+	concurrencyFixed, err := getEnvInt("TRANSLATION_CONCURRENCY_FIXED", 0)
+	if err != nil {
+		return nil, err
+	}
+	if concurrencyMin < 1 || concurrencyMax < concurrencyMin || concurrencyFixed < 0 {
+		return nil, fmt.Errorf("invalid translation concurrency: min=%d max=%d fixed=%d (need 1 <= min <= max, fixed >= 0)",
+			concurrencyMin, concurrencyMax, concurrencyFixed)
+	}
+	latencyTarget, err := getEnvDuration("TRANSLATION_LATENCY_TARGET", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hard cap on tracked metric label combinations
+	metricsMaxSeries, err := getEnvInt("METRICS_MAX_SERIES", 500)
+	if err != nil {
+		return nil, err
+	}
+	if metricsMaxSeries < 1 {
+		return nil, fmt.Errorf("METRICS_MAX_SERIES must be at least 1, got %d", metricsMaxSeries)
+	}
+
+	// Cache users.info lookups, which otherwise run for every message
+	userCacheTTL, err := getEnvDuration("USER_CACHE_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	// Channel names change even more rarely, and renames are seen as events
+	channelCacheTTL, err := getEnvDuration("CHANNEL_CACHE_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	// How long acked events wait for startup verification before being
+	// processed anyway
+	startupReadyTimeout, err := getEnvDuration("STARTUP_READY_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// Events are processed by a pool of workers, optionally keeping each
+	// channel's messages in order
+	workerPoolSize, err := getEnvInt("WORKER_POOL_SIZE", 4)
+	if err != nil {
+		return nil, err
+	}
+	if workerPoolSize < 1 {
+		return nil, fmt.Errorf("WORKER_POOL_SIZE must be at least 1, got %d", workerPoolSize)
+	}
+	preserveChannelOrder := os.Getenv("PRESERVE_CHANNEL_ORDER") != "false"
+
+	// A message that takes longer than this to process is given up on,
+	// including its LLM requests and their retries
+	messageProcessingTimeout, err := getEnvDuration("MESSAGE_PROCESSING_TIMEOUT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if messageProcessingTimeout < 0 {
+		return nil, fmt.Errorf("MESSAGE_PROCESSING_TIMEOUT must not be negative, got %s", messageProcessingTimeout)
+	}
+
+	// Translations in flight at shutdown get this long to be posted
+	shutdownGrace, err := getEnvDuration("SHUTDOWN_GRACE", 20*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if shutdownGrace < 0 {
+		return nil, fmt.Errorf("SHUTDOWN_GRACE must not be negative, got %s", shutdownGrace)
+	}
 
 	return &Config{
-		SlackBotToken:    slackBotToken,
-		SlackAppToken:    slackAppToken,
-		SlackChannelIDs:  strings.Split(channelIDs, ","),
-		SlackTargetUsers: strings.Split(targetUsers, ","),
-		OpenAIAPIKey:     openAIKey,
-		OpenAIModel:      openAIModel,
-		OpenAIMaxTokens:  openAIMaxTokens,
-		Debug:            debug,
-		Logs:             logs,
+		SlackBotToken:                 slackBotToken,
+		SlackAppToken:                 slackAppToken,
+		SlackChannelIDs:               strings.Split(channelIDs, ","),
+		SlackTargetUsers:              strings.Split(targetUsers, ","),
+		SlackAllTargetUsers:           allTargetUsers,
+		SlackTargetUsergroups:         usergroups,
+		UsergroupRefreshInterval:      usergroupRefreshInterval,
+		SlackExcludeChannelIDs:        excludeChannelIDs,
+		SlackExcludeUsers:             excludeUsers,
+		AllChannelsWarnThreshold:      allChannelsWarnThreshold,
+		AllChannelsConfirm:            allChannelsConfirm,
+		TriggerReaction:               triggerReaction,
+		RemoveReaction:                removeReaction,
+		RemoveButton:                  removeButton,
+		AdminUsers:                    adminUsers,
+		OptOutExemptUsers:             optOutExemptUsers,
+		LLMProvider:                   llmProvider,
+		LLMBaseURL:                    llmBaseURL,
+		LLMAPIKey:                     llmAPIKey,
+		LLMAPIKeyHeader:               llmAPIKeyHeader,
+		LLMModel:                      llmModel,
+		OpenAIAPIKey:                  openAIKey,
+		OpenAIModel:                   openAIModel,
+		OpenAIFallbackModel:           strings.TrimSpace(os.Getenv("OPENAI_FALLBACK_MODEL")),
+		OpenAIOrgID:                   strings.TrimSpace(os.Getenv("OPENAI_ORG_ID")),
+		OpenAIProjectID:               strings.TrimSpace(os.Getenv("OPENAI_PROJECT_ID")),
+		OpenAIMaxTokens:               openAIMaxTokens,
+		OpenAIMinTokens:               openAIMinTokens,
+		OpenAIRetryTruncated:          openAIRetryTruncated,
+		OpenAIMaxAttempts:             openAIMaxAttempts,
+		OpenAITimeout:                 openAITimeout,
+		OpenAICACertPath:              strings.TrimSpace(os.Getenv("OPENAI_CA_CERT_PATH")),
+		OpenAIInsecureSkipVerify:      os.Getenv("OPENAI_INSECURE_SKIP_VERIFY") == "true",
+		OpenAISystemPrompt:            systemPrompt,
+		OpenAIUserPromptTemplate:      userPromptTemplate,
+		OpenAICompressRequests:        openAICompressRequests,
+		OpenAITemperature:             openAITemperature,
+		OpenAITopP:                    openAITopP,
+		OpenAIFrequencyPenalty:        openAIFrequencyPenalty,
+		OpenAIPresencePenalty:         openAIPresencePenalty,
+		OpenAICandidates:              openAICandidates,
+		OpenAIStream:                  openAIStream,
+		LLMPromptPricePer1K:           promptPrice,
+		LLMCompletionPricePer1K:       completionPrice,
+		DailyTokenBudget:              dailyTokenBudget,
+		DailyCostBudget:               dailyCostBudget,
+		BudgetAlertChannel:            strings.TrimSpace(os.Getenv("BUDGET_ALERT_CHANNEL")),
+		TranslationCache:              translationCache,
+		TranslationCacheSize:          translationCacheSize,
+		TranslationCacheTTL:           translationCacheTTL,
+		GlossaryPath:                  strings.TrimSpace(os.Getenv("GLOSSARY_PATH")),
+		GlossaryMaxTokens:             glossaryMaxTokens,
+		Moderation:                    moderation,
+		ModerationThreshold:           moderationThreshold,
+		ModerationAction:              moderationAction,
+		AnthropicAPIKey:               anthropicKey,
+		AnthropicModel:                anthropicModel,
+		OllamaHost:                    ollamaHost,
+		OllamaModel:                   ollamaModel,
+		OllamaTimeout:                 ollamaTimeout,
+		QuoteMode:                     quoteMode,
+		OutputStyle:                   outputStyle,
+		OutputLanguage:                outputLanguage,
+		ChannelOutputStyles:           channelOutputStyles,
+		TranslationStyle:              translationStyle,
+		ChannelStyles:                 channelStyles,
+		AccessibleOutput:              accessibleOutput,
+		AccessibleOutputChannels:      accessibleOutputChannels,
+		Anonymous:                     anonymous,
+		AnonymousChannels:             anonymousChannels,
+		ConfirmBeforePost:             confirmBeforePost,
+		ConfirmBeforePostChannels:     confirmBeforePostChannels,
+		ConfirmApprover:               confirmApprover,
+		PlainTextReplies:              plainTextReplies,
+		MaxRerolls:                    maxRerolls,
+		EditedMessages:                editedMessages,
+		OnboardingCard:                onboardingCard,
+		ReplyTemplate:                 replyTemplate,
+		ThreadContextTokens:           threadContextTokens,
+		TranslationPlaceholder:        translationPlaceholder,
+		ProgressReactions:             progressReactions,
+		AnnouncementTLDRChannels:      announcementTLDRChannels,
+		MinMessageLength:              minMessageLength,
+		MinMessageWords:               minMessageWords,
+		MaxMessageTokens:              maxMessageTokens,
+		SkipMessageTokens:             skipMessageTokens,
+		SummarizeMessageTokens:        summarizeMessageTokens,
+		SkipEmojiOnly:                 skipEmojiOnly,
+		SkipURLOnly:                   skipURLOnly,
+		SkipPatterns:                  skipPatterns,
+		RequirePatterns:               requirePatterns,
+		MaxTranslationsPerUserPerHour: maxTranslationsPerUserPerHour,
+		MaxTranslationsPerHour:        maxTranslationsPerHour,
+		ChannelCooldown:               channelCooldown,
+		ChannelCooldownQueue:          channelCooldownQueue,
+		DebounceWindow:                debounceWindow,
+		DebounceMaxLength:             debounceMaxLength,
+		Schedule:                      schedule,
+		DigestChannel:                 strings.TrimSpace(os.Getenv("DIGEST_CHANNEL")),
+		DigestTime:                    digestTime,
+		DigestLocation:                digestLocation,
+		SkippedSubtypes:               skippedSubtypes,
+		HealthGracePeriod:             healthGracePeriod,
+		ConnectionAlertChannel:        strings.TrimSpace(os.Getenv("CONNECTION_ALERT_CHANNEL")),
+		ConnectionAlertFailures:       connectionAlertFailures,
+		AdminChannelID:                strings.TrimSpace(os.Getenv("ADMIN_CHANNEL_ID")),
+		AdminAlertInterval:            adminAlertInterval,
+		CatchUp:                       catchUp,
+		CatchUpMaxAge:                 catchUpMaxAge,
+		CatchUpMaxMessages:            catchUpMaxMessages,
+		WatchRules:                    watchRules,
+		TranslationTTL:                translationTTL,
+		ChannelTranslationTTLs:        channelTranslationTTLs,
+		AuditLogPath:                  os.Getenv("AUDIT_LOG_PATH"),
+		AuditLogMaxBytes:              int64(auditLogMaxSizeMB) * 1024 * 1024,
+		AuditLogOmitText:              auditLogOmitText,
+		DatabasePath:                  os.Getenv("DATABASE_PATH"),
+		StateFile:                     stateFile,
+		TranslationConcurrencyMin:     concurrencyMin,
+		TranslationConcurrencyMax:     concurrencyMax,
+		TranslationConcurrencyFixed:   concurrencyFixed,
+		TranslationLatencyTarget:      latencyTarget,
+		MetricsMaxSeries:              metricsMaxSeries,
+		UserCacheTTL:                  userCacheTTL,
+		ChannelCacheTTL:               channelCacheTTL,
+		StartupReadyTimeout:           startupReadyTimeout,
+		WorkerPoolSize:                workerPoolSize,
+		PreserveChannelOrder:          preserveChannelOrder,
+		MessageProcessingTimeout:      messageProcessingTimeout,
+		ShutdownGrace:                 shutdownGrace,
+		Debug:                         debug,
+		Logs:                          logs,
+		LogLevel:                      logLevel,
+		LogFormat:                     logFormat,
 	}, nil
-} 
\ No newline at end of file
+}
+
+// parsePromptTemplate parses the user prompt template, falling back to the
+// default when empty. The template is also rendered once with empty values
+// so references to unknown fields fail at startup rather than per message.
+func parsePromptTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultUserPromptTemplate
+	}
+
+	tmpl, err := template.New("user_prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("OPENAI_USER_PROMPT_TEMPLATE is not a valid template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, map[string]string{"Username": "", "Message": ""}); err != nil {
+		return nil, fmt.Errorf("OPENAI_USER_PROMPT_TEMPLATE can only use {{.Username}} and {{.Message}}: %w", err)
+	}
+	return tmpl, nil
+}
+
+// parseOnboardingCard parses the onboarding card template, falling back to
+// the default card when text is empty
+func parseOnboardingCard(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultOnboardingCardText
+	}
+
+	tmpl, err := template.New("onboarding_card").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("ONBOARDING_CARD_TEXT is not a valid template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, map[string]string{"User": "", "Channel": ""}); err != nil {
+		return nil, fmt.Errorf("ONBOARDING_CARD_TEXT can only use {{.User}} and {{.Channel}}: %w", err)
+	}
+	return tmpl, nil
+}
+
+// parseReplyTemplate parses the reply template, falling back to the
+// default, or the one quoting the original with includeOriginal, when text
+// is empty
+func parseReplyTemplate(text string, includeOriginal bool) (*template.Template, error) {
+	if text == "" {
+		text = DefaultReplyTemplate
+		if includeOriginal {
+			text = IncludeOriginalReplyTemplate
+		}
+	}
+
+	tmpl, err := template.New("reply").Funcs(ReplyTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("REPLY_TEMPLATE is not a valid template: %w", err)
+	}
+	fields := map[string]string{"DisplayName": "", "Translation": "", "Original": "", "Channel": ""}
+	if err := tmpl.Execute(io.Discard, fields); err != nil {
+		return nil, fmt.Errorf("REPLY_TEMPLATE can only use {{.DisplayName}}, {{.Translation}}, {{.Original}} and {{.Channel}}: %w", err)
+	}
+	return tmpl, nil
+}
+
+// parseWatchRules parses and validates the WATCH_RULES JSON array
+func parseWatchRules(value string) ([]WatchRule, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var rules []WatchRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("WATCH_RULES must be a JSON array of rules: %w", err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Channel == "" || rule.Author == "" {
+			return nil, fmt.Errorf("WATCH_RULES: rule %d needs both a channel and an author", i+1)
+		}
+
+		if rule.Window != "" {
+			start, end, ok := strings.Cut(rule.Window, "-")
+			var err error
+			if ok {
+				if rule.WindowStart, err = parseTimeOfDay(start); err == nil {
+					rule.WindowEnd, err = parseTimeOfDay(end)
+				}
+			}
+			if !ok || err != nil {
+				return nil, fmt.Errorf("WATCH_RULES: rule %d has an invalid window %q, expected e.g. \"09:25-09:40\"", i+1, rule.Window)
+			}
+		}
+
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("WATCH_RULES: rule %d has an invalid pattern: %w", i+1, err)
+			}
+			rule.PatternRegexp = re
+		}
+	}
+	return rules, nil
+}
+
+// parsePatterns compiles a comma-separated list of regular expressions.
+// Commas inside {} and [] and escaped ones (\,) belong to the pattern, so
+// repetitions like {2,} can be used.
+func parsePatterns(name, value string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, pattern := range splitPatterns(value) {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern %q: %w", name, pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// splitPatterns splits a pattern list on the commas that separate
+// patterns
+func splitPatterns(value string) []string {
+	var patterns []string
+	var braces, brackets int
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '{':
+			braces++
+		case '}':
+			braces = max(braces-1, 0)
+		case '[':
+			brackets++
+		case ']':
+			brackets = max(brackets-1, 0)
+		case ',':
+			if braces == 0 && brackets == 0 {
+				patterns = append(patterns, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(patterns, value[start:])
+}
+
+// parseSchedule parses ACTIVE_HOURS ("09:00-18:00"), ACTIVE_DAYS
+// ("Mon-Fri" or "Mon,Wed,Fri") and TIMEZONE (e.g. "America/New_York",
+// default the server's local time)
+func parseSchedule(hours, days, timezone string) (Schedule, error) {
+	schedule := Schedule{Location: time.Local}
+
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("TIMEZONE: unknown time zone %q: %w", timezone, err)
+		}
+		schedule.Location = location
+	}
+
+	if hours = strings.TrimSpace(hours); hours != "" {
+		start, end, ok := strings.Cut(hours, "-")
+		var err error
+		if ok {
+			if schedule.HoursStart, err = parseTimeOfDay(start); err == nil {
+				schedule.HoursEnd, err = parseTimeOfDay(end)
+			}
+		}
+		if !ok || err != nil {
+			return Schedule{}, fmt.Errorf("ACTIVE_HOURS must look like \"09:00-18:00\", got %q", hours)
+		}
+		if schedule.HoursStart == schedule.HoursEnd {
+			return Schedule{}, fmt.Errorf("ACTIVE_HOURS must not start and end at the same time, got %q", hours)
+		}
+		schedule.Hours = true
+	}
+
+	if days = strings.TrimSpace(days); days != "" {
+		schedule.Days = make(map[time.Weekday]bool)
+		for _, entry := range strings.Split(days, ",") {
+			first, last, isRange := strings.Cut(entry, "-")
+			from, ok := parseWeekday(first)
+			to := from
+			if ok && isRange {
+				to, ok = parseWeekday(last)
+			}
+			if !ok {
+				return Schedule{}, fmt.Errorf("ACTIVE_DAYS must list days like \"Mon-Fri\" or \"Mon,Wed,Fri\", got %q", days)
+			}
+			// Ranges may wrap around the weekend, like Fri-Mon
+			for day := from; ; day = (day + 1) % 7 {
+				schedule.Days[day] = true
+				if day == to {
+					break
+				}
+			}
+		}
+	}
+
+	return schedule, nil
+}
+
+// parseWeekday parses a day name, full or abbreviated to three letters
+func parseWeekday(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if strings.HasPrefix(name, value) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parseTimeOfDay parses "HH:MM" into an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// validOutputStyle reports whether style is a supported OUTPUT_STYLE value
+func validOutputStyle(style string) bool {
+	return style == OutputStyleTranslation || style == OutputStyleVibeCheck || style == OutputStyleBoth
+}
+
+// parseChannelMap reads a comma separated list of channel:value pairs, e.g.
+// "C0123:vibecheck,C0456:both"
+func parseChannelMap(name string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	value := os.Getenv(name)
+	if value == "" {
+		return result, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		channelID, setting, ok := strings.Cut(entry, ":")
+		channelID = strings.TrimSpace(channelID)
+		setting = strings.TrimSpace(setting)
+		if !ok || channelID == "" || setting == "" {
+			return nil, fmt.Errorf("%s entries must look like CHANNEL:value, got %q", name, entry)
+		}
+		result[channelID] = setting
+	}
+	return result, nil
+}
+
+// getEnvInt reads an integer environment variable, returning def when unset
+func getEnvInt(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+// getEnvFloat reads a number environment variable, which must lie between
+// min and max (which may be infinite). Unset returns nil, so the API's own
+// default applies.
+func getEnvFloat(name string, min, max float64) (*float64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number, got %q", name, value)
+	}
+	if f < min || f > max {
+		if math.IsInf(max, 1) {
+			return nil, fmt.Errorf("%s must be at least %g, got %g", name, min, f)
+		}
+		return nil, fmt.Errorf("%s must be between %g and %g, got %g", name, min, max, f)
+	}
+	return &f, nil
+}
+
+// getEnvDuration reads a duration environment variable (e.g. "30s", "5m"),
+// returning def when unset
+func getEnvDuration(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like 30s or 5m, got %q", name, value)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got %q", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// promptData mirrors what the translator renders the user prompt with
+type promptData struct {
+	Username string
+	Message  string
+}
+
+// Messages are inserted as they are: quotes, braces and template syntax in
+// them are text, not template
+func TestParsePromptTemplateRendering(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     promptData
+		want     string
+	}{
+		{
+			name: "default",
+			data: promptData{Username: "alice", Message: "standup at 10"},
+			want: `Make it humorous but keep the original meaning. The message is from alice: "standup at 10"`,
+		},
+		{
+			name:     "quotes",
+			template: `{{.Username}} wrote "{{.Message}}"`,
+			data:     promptData{Username: `o"brien`, Message: `she said "ship it" and 'left'`},
+			want:     `o"brien wrote "she said "ship it" and 'left'"`,
+		},
+		{
+			name:     "braces",
+			template: "Translate: {{.Message}}",
+			data:     promptData{Message: `func main() { fmt.Println("{}") }`},
+			want:     `Translate: func main() { fmt.Println("{}") }`,
+		},
+		{
+			name:     "template syntax in the message",
+			template: "{{.Username}}: {{.Message}}",
+			data:     promptData{Username: "{{.Message}}", Message: "{{.Username}} }}{{ {{end}}"},
+			want:     "{{.Message}}: {{.Username}} }}{{ {{end}}",
+		},
+		{
+			name:     "HTML isn't escaped",
+			template: "{{.Message}}",
+			data:     promptData{Message: "<b>a & b</b>"},
+			want:     "<b>a & b</b>",
+		},
+		{
+			name:     "literal braces in the template",
+			template: `{{"{{"}}{{.Message}}{{"}}"}}`,
+			data:     promptData{Message: "hi"},
+			want:     "{{hi}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parsePromptTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("parsePromptTemplate: %v", err)
+			}
+			var out strings.Builder
+			if err := tmpl.Execute(&out, tt.data); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if !strings.HasSuffix(out.String(), tt.want) {
+				t.Errorf("rendered %q, want it to end in %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePromptTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "unclosed action", template: "{{.Message", want: "is not a valid template"},
+		{name: "unknown field", template: "{{.Channel}} {{.Message}}", want: "can only use {{.Username}} and {{.Message}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePromptTemplate(tt.template)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parsePromptTemplate(%q) error = %v, want one containing %q", tt.template, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package anthropic translates with Anthropic's Claude models through the
+// Messages API.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/httpclient"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	messagesURL = "https://api.anthropic.com/v1/messages"
+	// apiVersion is the anthropic-version the requests are written against
+	apiVersion = "2023-06-01"
+	// maxResponseBytes caps how much of a response body is read
+	maxResponseBytes = 4 * 1024 * 1024
+)
+
+// Client handles communication with the Anthropic Messages API. It
+// implements translate.Translator.
+type Client struct {
+	apiKey      string
+	model       string
+	limits      translate.OutputLimits
+	maxAttempts int
+	baseURL     string
+	client      *http.Client
+	logger      *logging.Logger
+}
+
+// Message is a single message of a Messages API request
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Tool is a tool the model may call. Structured replies are requested as a
+// call of a single tool whose input follows the schema.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// ToolChoice forces the model to call a tool
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// MessagesRequest represents a request to the Messages API. Unlike OpenAI's
+// chat completions, the system prompt is a top-level field.
+type MessagesRequest struct {
+	Model       string      `json:"model"`
+	System      string      `json:"system,omitempty"`
+	Messages    []Message   `json:"messages"`
+	MaxTokens   int         `json:"max_tokens"`
+	Temperature float64     `json:"temperature"`
+	Tools       []Tool      `json:"tools,omitempty"`
+	ToolChoice  *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// MessagesResponse represents a response from the Messages API
+type MessagesResponse struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// ContentBlock is a block of a response's content: text, or a tool call
+// with its input
+type ContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// New creates a new Anthropic client
+func New(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) *Client {
+	logger.Infof("Initializing Anthropic client with model: %s, max tokens: %d",
+		cfg.AnthropicModel, cfg.OpenAIMaxTokens)
+
+	return &Client{
+		apiKey:      cfg.AnthropicAPIKey,
+		model:       cfg.AnthropicModel,
+		limits:      translate.NewOutputLimits(cfg),
+		maxAttempts: cfg.OpenAIMaxAttempts,
+		baseURL:     messagesURL,
+		client:      httpclient.WithTimeout(httpClient, cfg.OpenAITimeout),
+		logger:      logger,
+	}
+}
+
+// loggerFor returns the logger carrying the fields of the event ctx belongs
+// to
+func (c *Client) loggerFor(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, c.logger)
+}
+
+// Model returns the model used for translations
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Translate translates a message into the given style
+func (c *Client) Translate(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error) {
+	c.loggerFor(ctx).Debugf("Translating message to %s style for user: %s", req.Style.Name, req.Username)
+	c.loggerFor(ctx).Debugf("Original message: %s", req.Message)
+
+	result, err := translate.Translate(ctx, req, c.complete)
+	if err != nil {
+		return translate.TranslationResult{}, err
+	}
+
+	c.loggerFor(ctx).Debugf("Translation: %s", result.Text)
+	return result, nil
+}
+
+// VibeCheck summarizes the tone of a message as a single Gen Alpha line
+func (c *Client) VibeCheck(ctx context.Context, message, username string) (string, error) {
+	c.loggerFor(ctx).Debugf("Generating vibe check for user: %s", username)
+	return translate.VibeCheck(ctx, message, username, c.complete)
+}
+
+// Summarize returns a serious TL;DR of an announcement and its translation
+// into the given style, from a single structured call
+func (c *Client) Summarize(ctx context.Context, style translate.Style, message, username string) (translate.Announcement, error) {
+	c.loggerFor(ctx).Debugf("Summarizing announcement in %s style for user: %s", style.Name, username)
+	return translate.Summarize(ctx, style, message, username, c.complete)
+}
+
+// Complete sends a system and user prompt of the caller's own and returns
+// the reply
+func (c *Client) Complete(ctx context.Context, system, user string, opts ...translate.CompleteOption) (string, error) {
+	return c.complete(ctx, translate.NewCompletion(system, user, opts...))
+}
+
+// complete sends a Messages API request, retrying on load, and returns the
+// text of the reply. A completion with a schema forces a call of a tool
+// taking it as input, and returns the input as JSON.
+func (c *Client) complete(ctx context.Context, completion translate.Completion) (string, error) {
+	c.loggerFor(ctx).Debugf("Generated prompt for Anthropic: %s", completion.User)
+
+	request := MessagesRequest{
+		Model:       c.model,
+		System:      completion.System,
+		Messages:    []Message{{Role: "user", Content: completion.User}},
+		Temperature: completion.Temperature,
+	}
+	if completion.Schema != nil {
+		request.Tools = []Tool{{
+			Name:        completion.Schema.Name,
+			Description: "Reply by calling this tool with the requested fields.",
+			InputSchema: completion.Schema.Schema,
+		}}
+		request.ToolChoice = &ToolChoice{Type: "tool", Name: completion.Schema.Name}
+	}
+
+	return c.limits.Complete(ctx, c.loggerFor(ctx), "Anthropic", completion.MaxTokens, func(maxTokens int) (string, bool, error) {
+		request.MaxTokens = maxTokens
+		return c.completeRequest(ctx, request, completion.Schema)
+	})
+}
+
+// completeRequest sends a prepared Messages API request, retrying on load,
+// and returns the reply and whether it was cut off by max_tokens
+func (c *Client) completeRequest(ctx context.Context, request MessagesRequest, schema *translate.Schema) (string, bool, error) {
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return "", false, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	var body []byte
+	err = translate.Retry(ctx, c.loggerFor(ctx), "Anthropic", c.maxAttempts, func() error {
+		var err error
+		body, err = c.send(ctx, jsonBody)
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return c.parseResponse(ctx, body, schema)
+}
+
+// send makes a single Messages API request and returns the body of a
+// successful response
+func (c *Client) send(ctx context.Context, jsonBody []byte) ([]byte, error) {
+	c.loggerFor(ctx).Debugf("Sending request to Anthropic API using model: %s", c.model)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	startTime := time.Now()
+	resp, err := c.client.Do(req)
+	duration := time.Since(startTime)
+	requestDuration.ObserveDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	logger := c.loggerFor(ctx).With(logging.Duration(duration))
+	logger.Debugf("Received response from Anthropic in %v, status code: %d", duration, resp.StatusCode)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxResponseBytes)
+	}
+
+	// 529 means Anthropic is overloaded, and is retried like any 5xx
+	if resp.StatusCode != http.StatusOK {
+		return nil, &translate.StatusError{
+			Provider:   "Anthropic",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: translate.ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return body, nil
+}
+
+// parseResponse returns the text blocks of a response joined together, or
+// the input of the tool call when schema is set, and whether max_tokens
+// cut it off, and counts its tokens
+func (c *Client) parseResponse(ctx context.Context, body []byte, schema *translate.Schema) (string, bool, error) {
+	var response MessagesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", false, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	translate.AddUsage(ctx, c.model, response.Usage.InputTokens, response.Usage.OutputTokens)
+	truncated := response.StopReason == "max_tokens"
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		switch {
+		case schema != nil && block.Type == "tool_use" && block.Name == schema.Name:
+			return string(block.Input), truncated, nil
+		case schema == nil && block.Type == "text":
+			text.WriteString(block.Text)
+		}
+	}
+
+	if schema != nil {
+		return "", false, fmt.Errorf("no %s tool call returned from Anthropic", schema.Name)
+	}
+	if text.Len() == 0 {
+		return "", false, fmt.Errorf("no text content returned from Anthropic")
+	}
+	return text.String(), truncated, nil
+}
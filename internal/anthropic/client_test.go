@@ -0,0 +1,169 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	okResponse = `{
+		"id": "msg_01",
+		"type": "message",
+		"content": [{"type": "text", "text": "no cap, "}, {"type": "text", "text": "it's giving"}],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 12, "output_tokens": 5}
+	}`
+	overloadedResponse = `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`
+)
+
+func testLogger() *logging.Logger {
+	return logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+}
+
+// newTestClient returns a client sending to handler, and the number of
+// requests it received
+func newTestClient(t *testing.T, maxAttempts int, handler http.HandlerFunc) (*Client, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		AnthropicAPIKey:   "sk-ant-test",
+		AnthropicModel:    "claude-test",
+		OpenAIMaxTokens:   256,
+		OpenAIMaxAttempts: maxAttempts,
+		OpenAITimeout:     5 * time.Second,
+	}
+	c := New(cfg, server.Client(), testLogger())
+	c.baseURL = server.URL
+	return c, &requests
+}
+
+func TestCompleteSuccess(t *testing.T) {
+	c, requests := newTestClient(t, 3, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "sk-ant-test" {
+			t.Errorf("x-api-key = %q, want %q", got, "sk-ant-test")
+		}
+		if got := r.Header.Get("anthropic-version"); got != apiVersion {
+			t.Errorf("anthropic-version = %q, want %q", got, apiVersion)
+		}
+
+		var request MessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if request.Model != "claude-test" || request.System != "be brief" || request.MaxTokens != 256 {
+			t.Errorf("request = %+v, want model claude-test, system %q and max_tokens 256", request, "be brief")
+		}
+		if len(request.Messages) != 1 || request.Messages[0].Content != "hello" {
+			t.Errorf("messages = %+v, want a single user message %q", request.Messages, "hello")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, okResponse)
+	})
+
+	got, err := c.Complete(context.Background(), "be brief", "hello")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if want := "no cap, it's giving"; got != want {
+		t.Errorf("Complete = %q, want %q", got, want)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+// A 529 means Anthropic is overloaded: it is retried, and reported as
+// translate.ErrOverloaded once the attempts run out
+func TestCompleteOverloaded(t *testing.T) {
+	t.Run("retried", func(t *testing.T) {
+		var calls int32
+		c, requests := newTestClient(t, 2, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(529)
+				io.WriteString(w, overloadedResponse)
+				return
+			}
+			io.WriteString(w, okResponse)
+		})
+
+		got, err := c.Complete(context.Background(), "be brief", "hello")
+		if err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if want := "no cap, it's giving"; got != want {
+			t.Errorf("Complete = %q, want %q", got, want)
+		}
+		if got := atomic.LoadInt32(requests); got != 2 {
+			t.Errorf("requests = %d, want 2", got)
+		}
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		c, requests := newTestClient(t, 1, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(529)
+			io.WriteString(w, overloadedResponse)
+		})
+
+		_, err := c.Complete(context.Background(), "be brief", "hello")
+		if !errors.Is(err, translate.ErrOverloaded) {
+			t.Fatalf("Complete error = %v, want translate.ErrOverloaded", err)
+		}
+		var statusErr *translate.StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != 529 || !strings.Contains(statusErr.Body, "overloaded_error") {
+			t.Errorf("Complete error = %v, want a 529 StatusError with the response body", err)
+		}
+		if got := atomic.LoadInt32(requests); got != 1 {
+			t.Errorf("requests = %d, want 1", got)
+		}
+	})
+}
+
+// A body that isn't a Messages API response is an error, and isn't retried
+func TestCompleteMalformedResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "not JSON", body: "<html>Bad gateway</html>", want: "error unmarshaling response"},
+		{name: "truncated JSON", body: okResponse[:40], want: "error unmarshaling response"},
+		{name: "no text content", body: `{"content": [], "usage": {}}`, want: "no text content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requests := newTestClient(t, 3, func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			})
+
+			got, err := c.Complete(context.Background(), "be brief", "hello")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Complete = %q, %v, want an error containing %q", got, err, tt.want)
+			}
+			if got := atomic.LoadInt32(requests); got != 1 {
+				t.Errorf("requests = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package anthropic
+
+import "github.com/user/slack-bot-api/internal/metrics"
+
+var requestDuration = metrics.NewHistogram("slackbot_anthropic_request_duration_seconds",
+	"Latency of single Anthropic requests, retries counted separately", metrics.LatencyBuckets)
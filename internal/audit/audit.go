@@ -0,0 +1,144 @@
+// Package audit appends a record of every posted translation to a JSONL
+// file, for cost analysis and settling who said what.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is one translation in the audit log
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Channel          string    `json:"channel"`
+	User             string    `json:"user"`
+	Original         string    `json:"original,omitempty"`
+	Translated       string    `json:"translated,omitempty"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	LatencyMS        int64     `json:"latency_ms"`
+}
+
+// Log appends entries to a file, one JSON object per line. When the file
+// would grow past its size cap it is renamed to path.1, replacing the
+// previous one, and a new file is started. A nil Log writes nothing. It is
+// safe for concurrent use.
+type Log struct {
+	path     string
+	maxBytes int64
+	omitText bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// Open opens the audit log at path for appending. maxBytes caps the size of
+// a file, 0 for no cap; omitText leaves the message texts out. An empty
+// path returns a nil Log.
+func Open(path string, maxBytes int64, omitText bool) (*Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	l := &Log{path: path, maxBytes: maxBytes, omitText: omitText}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the file and picks up its size; callers must hold l.mu
+// unless l isn't shared yet
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading audit log size: %w", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Write appends an entry
+func (l *Log) Write(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+	if l.omitText {
+		entry.Original = ""
+		entry.Translated = ""
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	if l.file == nil {
+		// A rotation failed earlier; try again
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the full file aside and starts a new one; callers must hold
+// l.mu
+func (l *Log) rotate() error {
+	l.file.Close()
+	l.file = nil
+
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("error rotating audit log: %w", err)
+	}
+	return l.open()
+}
+
+// Close closes the file; later writes are dropped
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxAccessibleEmoji is the number of emoji kept in accessible output
+const maxAccessibleEmoji = 2
+
+// minShoutLength is the length from which an all-caps word counts as
+// shouting rather than an abbreviation like "NGL"
+const minShoutLength = 4
+
+// accessibleText enforces the accessible output rules on a reply whatever
+// the model returned: at most two emoji, no stretched letters and no
+// all-caps words, which are unpleasant to listen to with a screen reader
+func accessibleText(text string) string {
+	text = limitEmoji(text, maxAccessibleEmoji)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		words := strings.Fields(line)
+		for j, word := range words {
+			words[j] = unstretch(unshout(word))
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+const (
+	zeroWidthJoiner   = '\u200d'
+	variationSelector = '\ufe0f'
+	keycap            = '\u20e3'
+)
+
+// isEmoji reports whether r starts an emoji
+func isEmoji(r rune) bool {
+	return r >= 0x1f000 || unicode.Is(unicode.So, r)
+}
+
+// isEmojiPart reports whether r only modifies or joins the emoji before it
+// (variation selector, keycap, skin tone, zero width joiner)
+func isEmojiPart(r rune) bool {
+	return r == zeroWidthJoiner || r == variationSelector || r == keycap || (r >= 0x1f3fb && r <= 0x1f3ff)
+}
+
+// isRegionalIndicator reports whether r is half of a flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// limitEmoji removes every emoji after the first max. Joined sequences
+// like 👩‍💻 and flags count as one emoji.
+func limitEmoji(text string, max int) string {
+	var b strings.Builder
+	count := 0
+	keep := true
+	joined := false   // the previous rune was a zero width joiner
+	halfFlag := false // the previous rune was an unpaired regional indicator
+
+	for _, r := range text {
+		switch {
+		case isEmojiPart(r):
+			if keep {
+				b.WriteRune(r)
+			}
+			joined = r == zeroWidthJoiner
+			continue
+		case isEmoji(r):
+			continuesFlag := halfFlag && isRegionalIndicator(r)
+			if !joined && !continuesFlag {
+				count++
+				keep = count <= max
+			}
+			halfFlag = isRegionalIndicator(r) && !continuesFlag
+			joined = false
+			if keep {
+				b.WriteRune(r)
+			}
+			continue
+		}
+
+		joined, halfFlag, keep = false, false, true
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unstretch collapses letters repeated three or more times, so "sooooo"
+// becomes "so"
+func unstretch(word string) string {
+	runes := []rune(word)
+	var out []rune
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 && unicode.IsLetter(runes[i]) {
+			out = append(out, runes[i])
+		} else {
+			out = append(out, runes[i:j]...)
+		}
+		i = j
+	}
+	return string(out)
+}
+
+// unshout lowercases words of four or more letters written in all caps
+func unshout(word string) string {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return word
+		}
+		if unicode.IsUpper(r) {
+			letters++
+		}
+	}
+	if letters < minShoutLength {
+		return word
+	}
+	return strings.ToLower(word)
+}
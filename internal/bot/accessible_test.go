@@ -0,0 +1,78 @@
+package bot
+
+import "testing"
+
+// The fixtures are replies models gave for the accessible style despite
+// being asked to keep emoji down
+func TestAccessibleText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "already accessible",
+			in:   "The deploy is done, no cap 🔥",
+			want: "The deploy is done, no cap 🔥",
+		},
+		{
+			name: "emoji after the second dropped",
+			in:   "🔥🔥 standup is bussin 💯😤🚀 fr fr ✨",
+			want: "🔥🔥 standup is bussin fr fr",
+		},
+		{
+			name: "skin tones and joined sequences count once",
+			in:   "👋🏽 the 👩‍💻 shipped it 👨‍👩‍👧‍👦 and 🙌🏻",
+			want: "👋🏽 the 👩‍💻 shipped it and",
+		},
+		{
+			name: "flags count once",
+			in:   "🇫🇷🇯🇵 offsite 🇺🇸 vibes",
+			want: "🇫🇷🇯🇵 offsite vibes",
+		},
+		{
+			name: "variation selectors",
+			in:   "❤️ meeting ✅ moved ☀️",
+			want: "❤️ meeting ✅ moved",
+		},
+		{
+			// The digit is read out as text, so it isn't counted
+			name: "keycaps",
+			in:   "1️⃣ 🔥 standup 2️⃣ 💯 retro ✨",
+			want: "1️⃣ 🔥 standup 2️⃣ 💯 retro",
+		},
+		{
+			name: "the count runs across lines",
+			in:   "lowkey 😭\nhighkey 💀\nno thoughts 🧠 just vibes",
+			want: "lowkey 😭\nhighkey 💀\nno thoughts just vibes",
+		},
+		{
+			name: "stretched letters",
+			in:   "sooooo good, yessss 🔥",
+			want: "so good, yes 🔥",
+		},
+		{
+			name: "shouting, but not abbreviations",
+			in:   "NGL this is SO GOATED, FR",
+			want: "NGL this is SO goated, FR",
+		},
+		{
+			name: "everything at once",
+			in:   "😭😭😭 BESTIEEEE the sprint is OVERRRR 💀💀 ✨✨✨",
+			want: "😭😭 bestie the sprint is over",
+		},
+		{
+			name: "only emoji",
+			in:   "🔥💯🚀✨",
+			want: "🔥💯",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accessibleText(tt.in); got != tt.want {
+				t.Errorf("accessibleText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/config"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// announcementLabel is how the translation of an announcement is labeled
+func announcementLabel(style translate.Style) string {
+	if style.Name == translate.DefaultStyle {
+		return "In Gen Alpha"
+	}
+	return "In " + style.Name
+}
+
+// postAnnouncement posts a serious TL;DR and a translation of an @channel
+// announcement in its thread, returning the translation when both were
+// posted. Announcements that were already answered, e.g. when Slack
+// redelivers one after a restart, are skipped.
+func (b *Bot) postAnnouncement(ctx context.Context, event *slackClient.IncomingMessage, user *slack.User, style translate.Style) (string, error) {
+	if b.store.RepliedTo(event.Channel, event.Timestamp) {
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "not summarized yet", false, "a TL;DR was already posted")
+		return "", nil
+	}
+
+	var displayName string
+	if !b.anonymous(event.Channel) {
+		displayName = getDisplayName(user)
+	}
+
+	var announcement translate.Announcement
+	ctx, usage := translate.WithUsage(ctx)
+	start := time.Now()
+	err := b.limited(ctx, func() error {
+		var err error
+		announcement, err = b.translator.Summarize(ctx, b.withGlossary(style), event.Text, displayName)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error summarizing announcement: %w", err)
+	}
+
+	b.recordUsage(ctx, usage)
+
+	// The TL;DR is serious, only the translation is moderated; a withheld
+	// one leaves the TL;DR on its own
+	tldr := "📌 *TL;DR:* " + announcement.TLDR
+	replies := []string{tldr}
+	var translation string
+	if text, ok := b.moderate(ctx, event, announcement.Translation); ok {
+		translation = fmt.Sprintf("🗣️ *%s:* %s", announcementLabel(style), text)
+		replies = append(replies, translation)
+	}
+	b.recordAudit(ctx, event, strings.Join(replies, "\n\n"), usage, time.Since(start))
+	b.recordHistory(ctx, event, strings.Join(replies, "\n\n"), usage)
+
+	if b.confirmBeforePost(event.Channel) {
+		return "", b.requestApproval(ctx, event, event.ThreadTimestamp, strings.Join(replies, "\n\n"), style.Name, config.OutputStyleTranslation)
+	}
+
+	for _, text := range replies {
+		if _, err := b.postReply(ctx, event.Channel, event.ThreadTimestamp, event.Timestamp, event.User, text); err != nil {
+			return "", err
+		}
+	}
+	return translation, nil
+}
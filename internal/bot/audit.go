@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/audit"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// servedModel returns the model that answered the requests counted in
+// usage. That is the translator's own, unless it fell back to another one;
+// cached translations count as the translator's.
+func (b *Bot) servedModel(usage *translate.Usage) string {
+	if model := usage.Model(); model != "" {
+		return model
+	}
+	return b.translator.Model()
+}
+
+// recordAudit appends a translation to the audit log. Failing to write it
+// is logged and otherwise ignored.
+func (b *Bot) recordAudit(ctx context.Context, event *slackClient.IncomingMessage, translated string, usage *translate.Usage, latency time.Duration) {
+	prompt, completion := usage.Tokens()
+	err := b.audit.Write(audit.Entry{
+		Time:             time.Now(),
+		Channel:          event.Channel,
+		User:             event.User,
+		Original:         event.Text,
+		Translated:       translated,
+		Model:            b.servedModel(usage),
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		LatencyMS:        latency.Milliseconds(),
+	})
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error writing audit log: %v", err)
+	}
+}
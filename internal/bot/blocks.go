@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// maxSectionText is the most text Slack accepts in a section block
+const maxSectionText = 3000
+
+// replyBlocks lays out the translation of the message originalTS as an
+// app message: the original author's avatar and name, except in anonymous
+// channels, the translation, its buttons and a divider. It returns nil,
+// meaning plain text, when
+// blocks are turned off, the text doesn't fit a section or the author
+// can't be looked up. The translation is verbatim, so names in it aren't
+// turned into mentions.
+func (b *Bot) replyBlocks(ctx context.Context, channelID, originalTS, userID, text string) []slack.Block {
+	if b.plainTextReplies || len(text) > maxSectionText {
+		return nil
+	}
+
+	var blocks []slack.Block
+	if !b.anonymous(channelID) {
+		user, err := b.slack.GetUserInfo(ctx, userID)
+		if err != nil {
+			b.loggerFor(ctx).Debugf("Posting plain text reply, couldn't look up author %s: %v", userID, err)
+			return nil
+		}
+
+		displayName := getDisplayName(user)
+		var author []slack.MixedElement
+		if user.Profile.Image48 != "" {
+			author = append(author, slack.NewImageBlockElement(user.Profile.Image48, displayName))
+		}
+		author = append(author, slack.NewTextBlockObject(slack.MarkdownType, "*"+displayName+"*", false, false))
+		blocks = append(blocks, slack.NewContextBlock("", author...))
+	}
+
+	blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, true), nil, nil))
+	blocks = append(blocks, b.replyActions(channelID, originalTS)...)
+	return append(blocks, slack.NewDividerBlock())
+}
+
+// replyActions returns the blocks under a translation: who re-rolled it,
+// and the re-roll button while re-rolls are left and the remove button
+func (b *Bot) replyActions(channelID, originalTS string) []slack.Block {
+	var blocks []slack.Block
+	var buttons []slack.BlockElement
+
+	rerolledBy, left, rerollable := b.rerolls.state(channelID, originalTS)
+	if len(rerolledBy) > 0 {
+		mentions := make([]string, len(rerolledBy))
+		for i, userID := range rerolledBy {
+			mentions[i] = "<@" + userID + ">"
+		}
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, "🔁 Re-rolled by "+strings.Join(mentions, ", "), false, false)))
+	}
+	if rerollable && left {
+		buttons = append(buttons, slack.NewButtonBlockElement(rerollActionID, originalTS,
+			slack.NewTextBlockObject(slack.PlainTextType, "🔁 Re-roll", true, false)))
+	}
+
+	if b.removeButton {
+		buttons = append(buttons, slack.NewButtonBlockElement(removeActionID, originalTS,
+			slack.NewTextBlockObject(slack.PlainTextType, "Remove", false, false)).
+			WithStyle(slack.StyleDanger).
+			WithConfirm(slack.NewConfirmationBlockObject(
+				slack.NewTextBlockObject(slack.PlainTextType, "Remove translation?", false, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "Only the author of the original message or an admin can remove it.", false, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "Remove", false, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))))
+	}
+
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("translation_actions", buttons...))
+	}
+	return blocks
+}
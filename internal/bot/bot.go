@@ -2,72 +2,315 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
 
 	"github.com/slack-go/slack"
 
 	"github.com/user/slack-bot-api/config"
-	"github.com/user/slack-bot-api/internal/openai"
+	"github.com/user/slack-bot-api/internal/audit"
+	"github.com/user/slack-bot-api/internal/concurrency"
+	"github.com/user/slack-bot-api/internal/glossary"
+	"github.com/user/slack-bot-api/internal/history"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/metrics"
 	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/store"
+	"github.com/user/slack-bot-api/internal/threadctx"
+	"github.com/user/slack-bot-api/internal/translate"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
 )
 
 // Bot represents the Slack bot application
 type Bot struct {
-	slack  *slackClient.Client
-	openai *openai.Client
-	logger *log.Logger
-	debug  bool
-	logs   bool
-	wg     sync.WaitGroup
+	slack      *slackClient.Client
+	translator translate.Translator
+	// moderator checks translations before they're posted, nil when
+	// MODERATION is off
+	moderator                translate.Moderator
+	moderationThreshold      float64
+	moderationAction         string
+	logger                   *logging.Logger
+	debug                    bool
+	logs                     bool
+	quoteMode                string
+	defaultOutputStyle       string
+	channelOutputStyles      map[string]string
+	defaultTranslationStyle  translate.Style
+	channelTranslationStyles map[string]translate.Style
+	accessibleOutput         bool
+	accessibleChannels       map[string]bool
+	anonymousAll             bool
+	anonymousChannels        map[string]bool
+	confirmAll               bool
+	confirmChannels          map[string]bool
+	confirmApprover          string
+	approvals                approvalStats
+	placeholder              bool
+	// stream edits translations into their placeholder as they're written
+	stream                 bool
+	plainTextReplies       bool
+	rerolls                *translationRerolls
+	removeButton           bool
+	editedMessages         string
+	onboardingCard         *template.Template
+	replyTemplate          *template.Template
+	threadContextTokens    int
+	maxMessageTokens       int
+	summarizeTokens        int
+	outputLanguage         string
+	glossary               *glossary.Glossary
+	progressReactions      bool
+	filter                 messageFilter
+	rateLimit              *translationRateLimit
+	cooldown               *channelCooldown
+	debounce               *messageDebouncer
+	schedule               config.Schedule
+	optOutExempt           map[string]bool
+	stats                  *translationStats
+	budget                 *dailyBudget
+	budgetAlertChannel     string
+	audit                  *audit.Log
+	history                history.Store
+	digestChannel          string
+	digestTime             time.Duration
+	digestLocation         *time.Location
+	limiter                *concurrency.Limiter
+	labelPolicy            *metrics.LabelPolicy
+	translations           *metrics.CounterVec
+	translationFailures    *metrics.CounterVec
+	matched                *metrics.CounterVec
+	store                  *store.Store
+	defaultTranslationTTL  time.Duration
+	channelTranslationTTLs map[string]time.Duration
+	wg                     sync.WaitGroup
 }
 
-// New creates a new Bot instance
-func New(cfg *config.Config, logger *log.Logger) (*Bot, error) {
+// New creates a new Bot instance translating with translator. Translations
+// are checked with moderator before they're posted, unless it's nil. Slack
+// is called with httpClient.
+func New(cfg *config.Config, translator translate.Translator, moderator translate.Moderator, httpClient *http.Client, logger *logging.Logger) (*Bot, error) {
 	// Initialize Slack client
-	slack, err := slackClient.New(cfg, logger)
+	slack, err := slackClient.New(cfg, httpClient, logger)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing Slack client: %w", err)
 	}
 
-	// Initialize OpenAI client
-	openai := openai.New(cfg, logger)
+	// Resolve translation styles now so a typo fails at startup
+	styles := translate.NewStyles(cfg)
+	defaultTranslationStyle, ok := styles.Style(cfg.TranslationStyle)
+	if !ok {
+		return nil, fmt.Errorf("TRANSLATION_STYLE: unknown style %q (available: %s)",
+			cfg.TranslationStyle, strings.Join(styles.Names(), ", "))
+	}
+	channelTranslationStyles := make(map[string]translate.Style, len(cfg.ChannelStyles))
+	for channelID, name := range cfg.ChannelStyles {
+		style, ok := styles.Style(name)
+		if !ok {
+			return nil, fmt.Errorf("CHANNEL_STYLES: unknown style %q for channel %s (available: %s)",
+				name, channelID, strings.Join(styles.Names(), ", "))
+		}
+		channelTranslationStyles[channelID] = style
+	}
 
 	if cfg.Logs {
-		logger.Println("Bot initialized with configuration:")
-		logger.Printf("  Debug mode: %v", cfg.Debug)
-		logger.Printf("  Logs enabled: %v", cfg.Logs)
-		logger.Printf("  OpenAI Model: %s", cfg.OpenAIModel)
-		
+		logger.Infof("Bot initialized with configuration:")
+		logger.Infof("  Debug mode: %v", cfg.Debug)
+		logger.Infof("  Log level: %s", logger.Level())
+		logger.Infof("  LLM provider: %s, model: %s", cfg.LLMProvider, translator.Model())
+
 		// Log detailed channel information
-		logger.Println("\nConfigured Slack Channels:")
+		logger.Infof("\nConfigured Slack Channels:")
 		for i, channelID := range cfg.SlackChannelIDs {
-			logger.Printf("  %d. Channel ID: %s", i+1, channelID)
+			logger.Infof("  %d. Channel ID: %s", i+1, channelID)
 		}
-		
+
 		// Log detailed target user information
-		logger.Println("\nConfigured Target Users:")
+		logger.Infof("\nConfigured Target Users:")
 		for i, user := range cfg.SlackTargetUsers {
-			logger.Printf("  %d. User: %s", i+1, user)
+			logger.Infof("  %d. User: %s", i+1, user)
+		}
+
+		if len(cfg.SlackExcludeChannelIDs) > 0 || len(cfg.SlackExcludeUsers) > 0 {
+			logger.Infof("\nExcluded: channels %s; users %s",
+				strings.Join(cfg.SlackExcludeChannelIDs, ", "), strings.Join(cfg.SlackExcludeUsers, ", "))
+		}
+	}
+
+	// Bound concurrent translations, adapting to LLM latency unless pinned
+	var limiter *concurrency.Limiter
+	if cfg.TranslationConcurrencyFixed > 0 {
+		limiter = concurrency.NewFixed(cfg.TranslationConcurrencyFixed)
+	} else {
+		limiter = concurrency.NewAdaptive(
+			cfg.TranslationConcurrencyMin,
+			cfg.TranslationConcurrencyMax,
+			cfg.TranslationLatencyTarget,
+			func(limit int) {
+				logger.Infof("Translation concurrency limit is now %d", limit)
+				concurrencyLimit.Set(float64(limit))
+			},
+		)
+	}
+	concurrencyLimit.Set(float64(limiter.Limit()))
+
+	accessibleChannels := make(map[string]bool)
+	for _, channelID := range cfg.AccessibleOutputChannels {
+		if channelID = strings.TrimSpace(channelID); channelID != "" {
+			accessibleChannels[channelID] = true
+		}
+	}
+
+	anonymousChannels := make(map[string]bool)
+	for _, channelID := range cfg.AnonymousChannels {
+		if channelID = strings.TrimSpace(channelID); channelID != "" {
+			anonymousChannels[channelID] = true
+		}
+	}
+
+	optOutExempt := make(map[string]bool)
+	for _, user := range cfg.OptOutExemptUsers {
+		if user = strings.TrimSpace(user); user != "" {
+			optOutExempt[user] = true
+		}
+	}
+
+	confirmChannels := make(map[string]bool)
+	for _, channelID := range cfg.ConfirmBeforePostChannels {
+		if channelID = strings.TrimSpace(channelID); channelID != "" {
+			confirmChannels[channelID] = true
+		}
+	}
+
+	// Posted translations are remembered so they can be cleaned up later
+	state, err := store.Open(cfg.StateFile, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error opening state store: %w", err)
+	}
+
+	auditLog, err := audit.Open(cfg.AuditLogPath, cfg.AuditLogMaxBytes, cfg.AuditLogOmitText)
+	if err != nil {
+		return nil, err
+	}
+
+	translationHistory, err := history.Open(cfg.DatabasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	houseSlang, err := glossary.Load(cfg.GlossaryPath, cfg.GlossaryMaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	if houseSlang.Enabled() {
+		logger.Infof("📖 Loaded %d glossary terms from %s", houseSlang.Len(), cfg.GlossaryPath)
+		if _, omitted := houseSlang.Prompt(); omitted > 0 {
+			logger.Warnf("⚠️ %d glossary terms don't fit in GLOSSARY_MAX_TOKENS and are left out of the prompt", omitted)
 		}
 	}
 
-	return &Bot{
-		slack:  slack,
-		openai: openai,
-		logger: logger,
-		debug:  cfg.Debug,
-		logs:   cfg.Logs,
-	}, nil
+	// Metric labels are governed centrally so per-channel series stay bounded
+	labelPolicy := metrics.NewLabelPolicy(slack.MonitoredChannels(), cfg.MetricsMaxSeries)
+	prices := tokenPrices{prompt: cfg.LLMPromptPricePer1K, completion: cfg.LLMCompletionPricePer1K}
+
+	b := &Bot{
+		slack:                    slack,
+		translator:               translator,
+		moderator:                moderator,
+		moderationThreshold:      cfg.ModerationThreshold,
+		moderationAction:         cfg.ModerationAction,
+		logger:                   logger,
+		debug:                    cfg.Debug,
+		logs:                     cfg.Logs,
+		quoteMode:                cfg.QuoteMode,
+		defaultOutputStyle:       cfg.OutputStyle,
+		channelOutputStyles:      cfg.ChannelOutputStyles,
+		defaultTranslationStyle:  defaultTranslationStyle,
+		channelTranslationStyles: channelTranslationStyles,
+		accessibleOutput:         cfg.AccessibleOutput,
+		accessibleChannels:       accessibleChannels,
+		anonymousAll:             cfg.Anonymous,
+		anonymousChannels:        anonymousChannels,
+		confirmAll:               cfg.ConfirmBeforePost,
+		confirmChannels:          confirmChannels,
+		confirmApprover:          cfg.ConfirmApprover,
+		placeholder:              cfg.TranslationPlaceholder,
+		stream:                   cfg.OpenAIStream,
+		plainTextReplies:         cfg.PlainTextReplies,
+		rerolls:                  newTranslationRerolls(cfg.MaxRerolls),
+		removeButton:             cfg.RemoveButton,
+		editedMessages:           cfg.EditedMessages,
+		onboardingCard:           cfg.OnboardingCard,
+		replyTemplate:            cfg.ReplyTemplate,
+		threadContextTokens:      cfg.ThreadContextTokens,
+		maxMessageTokens:         cfg.MaxMessageTokens,
+		summarizeTokens:          cfg.SummarizeMessageTokens,
+		outputLanguage:           cfg.OutputLanguage,
+		glossary:                 houseSlang,
+		progressReactions:        cfg.ProgressReactions,
+		filter: messageFilter{
+			minLength:       cfg.MinMessageLength,
+			minWords:        cfg.MinMessageWords,
+			emojiOnly:       cfg.SkipEmojiOnly,
+			urlOnly:         cfg.SkipURLOnly,
+			maxTokens:       cfg.SkipMessageTokens,
+			skipPatterns:    cfg.SkipPatterns,
+			requirePatterns: cfg.RequirePatterns,
+		},
+		rateLimit:              newTranslationRateLimit(cfg.MaxTranslationsPerUserPerHour, cfg.MaxTranslationsPerHour, time.Now()),
+		cooldown:               newChannelCooldown(cfg.ChannelCooldown, cfg.ChannelCooldownQueue, logger),
+		debounce:               newMessageDebouncer(cfg.DebounceWindow, cfg.DebounceMaxLength, logger),
+		schedule:               cfg.Schedule,
+		optOutExempt:           optOutExempt,
+		stats:                  newTranslationStats(time.Now(), prices),
+		budget:                 newDailyBudget(cfg.DailyTokenBudget, cfg.DailyCostBudget, prices, cfg.Schedule.Location, state),
+		budgetAlertChannel:     cfg.BudgetAlertChannel,
+		audit:                  auditLog,
+		history:                translationHistory,
+		digestChannel:          cfg.DigestChannel,
+		digestTime:             cfg.DigestTime,
+		digestLocation:         cfg.DigestLocation,
+		limiter:                limiter,
+		labelPolicy:            labelPolicy,
+		translations:           metrics.NewCounterVec(labelPolicy),
+		translationFailures:    metrics.NewCounterVec(labelPolicy),
+		matched:                metrics.NewCounterVec(labelPolicy),
+		store:                  state,
+		defaultTranslationTTL:  cfg.TranslationTTL,
+		channelTranslationTTLs: cfg.ChannelTranslationTTLs,
+	}
+	b.registerCommands()
+	metrics.Default.RegisterCounterVec("slackbot_messages_matched_total",
+		"Messages that passed the filters and were handed to the translator", b.matched, "channel")
+	metrics.Default.RegisterCounterVec("slackbot_translations_total",
+		"Translations posted", b.translations, "channel", "persona", "model")
+	metrics.Default.RegisterCounterVec("slackbot_translation_failures_total",
+		"Messages whose translation failed", b.translationFailures, "channel")
+	b.slack.HandleAction(approveActionID, b.handleApprovalAction)
+	b.slack.HandleAction(discardActionID, b.handleApprovalAction)
+	b.slack.HandleAction(rerollActionID, b.handleRerollAction)
+	b.slack.HandleAction(removeActionID, b.handleRemoveAction)
+	if cfg.RemoveReaction != "" {
+		b.slack.HandleReaction(cfg.RemoveReaction, b.handleRemoveReaction)
+	}
+	if cfg.CatchUp {
+		b.slack.EnableCatchUp(b.store)
+	}
+
+	return b, nil
 }
 
 // Start starts the bot
 func (b *Bot) Start(ctx context.Context) error {
-	if b.logs {
-		b.logger.Println("Starting Gen Alpha translation bot...")
-	}
-	
+	b.loggerFor(ctx).Infof("Starting Gen Alpha translation bot...")
+
 	// Create a context that can be canceled
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -81,91 +324,718 @@ func (b *Bot) Start(ctx context.Context) error {
 		b.processMessages(ctx)
 	}()
 
-	if b.logs {
-		b.logger.Println("Message processing routine started")
+	b.loggerFor(ctx).Infof("Message processing routine started")
+
+	// Keep retrying a degraded state store until it recovers
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.recoverStore(ctx, storeRetryInterval)
+	}()
+
+	// Discard translations nobody approved in time
+	if b.confirmAll || len(b.confirmChannels) > 0 {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.expireApprovals(ctx)
+		}()
 	}
 
-	// Start the Slack client
-	if err := b.slack.Start(ctx); err != nil {
-		return err
+	// Recap the day's translations
+	if b.digestChannel != "" {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.runDigest(ctx)
+		}()
+	}
+
+	// Delete expired translations in the background
+	if b.retentionEnabled() {
+		if b.store.Path() == "" {
+			b.loggerFor(ctx).Warnf("⚠️ TRANSLATION_TTL is set without STATE_FILE; translations posted before a restart won't be cleaned up")
+		}
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.runCleanup(ctx)
+		}()
 	}
 
+	// Start the Slack client. A failure is fatal; stop the background work
+	// and let in-flight messages drain before reporting it.
+	err := b.slack.Start(ctx)
+	cancel()
+	b.debounce.stop()
+	b.cooldown.stop()
+
 	// Wait for all goroutines to finish
 	b.wg.Wait()
-	if b.logs {
-		b.logger.Println("All bot goroutines have completed")
+	b.loggerFor(ctx).Infof("All bot goroutines have completed")
+	if err := b.audit.Close(); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error closing audit log: %v", err)
 	}
-	
-	return nil
+	if err := b.history.Close(); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error closing history database: %v", err)
+	}
+	if err := b.store.FlushSeen(); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error saving catch-up checkpoints: %v", err)
+	}
+
+	return err
 }
 
 // processMessages handles incoming Slack messages
 func (b *Bot) processMessages(ctx context.Context) {
-	b.logger.Println("Starting to process messages")
+	b.loggerFor(ctx).Infof("Starting to process messages")
 
 	// Process events from Slack
-	b.slack.ProcessEvents(ctx, func(ctx context.Context, event *slack.MessageEvent) error {
-		if b.logs {
-			b.logger.Printf("Processing new message event - Channel: %s, User: %s", 
-				event.Channel, event.User)
-		}
-		
-		// Get user info
-		user, err := b.slack.GetUserInfo(ctx, event.User)
-		if err != nil {
-			return fmt.Errorf("error getting user info: %w", err)
+	b.slack.ProcessEvents(ctx, b.process)
+}
+
+// process translates a message that passed the Slack client's filters.
+// Messages held back by a channel cooldown or the debounce window come
+// back through it later, with a MESSAGE_PROCESSING_TIMEOUT of their own.
+func (b *Bot) process(ctx context.Context, event *slackClient.IncomingMessage, user *slack.User) (err error) {
+	b.loggerFor(ctx).Debugf("Processing new message event - Channel: %s, User: %s",
+		event.Channel, event.User)
+
+	// Slack redelivers events the bot may already have answered before a
+	// restart. Edits and explicit requests translate again on purpose, and
+	// announcements check for themselves.
+	if !slackClient.IsEdit(event) && !slackClient.IsOnDemand(event) && !slackClient.IsAnnouncement(event) &&
+		b.store.RepliedTo(event.Channel, event.Timestamp) {
+		b.loggerFor(ctx).Debugf("⏩ Skipping message %s, already translated", event.Timestamp)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "not translated yet", false, "a translation was already posted")
+		return nil
+	}
+
+	// Fragments sent in quick succession are held and translated
+	// together, before the filters judge them
+	if b.debounce.applies(ctx, event) && b.debounce.hold(ctx, event, user, b.slack.WithDeadline(b.process)) {
+		b.loggerFor(ctx).Debugf("⏸️ Holding message %s for DEBOUNCE_WINDOW", event.Timestamp)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "not debounced", false, "held for DEBOUNCE_WINDOW, translated together with the next messages")
+		return nil
+	}
+
+	// Short, emoji-only and link-only messages aren't worth translating
+	if reason := b.filter.skipReason(event); reason != "" {
+		b.loggerFor(ctx).Debugf("⏩ Skipping message %s: %s", event.Timestamp, reason)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "worth translating", false, reason)
+		return nil
+	}
+
+	// Patterns see names and link labels, not Slack markup
+	if b.filter.matchesPatterns(event) {
+		if reason := b.filter.patternSkipReason(b.resolvedEvent(ctx, event).Text); reason != "" {
+			b.loggerFor(ctx).Debugf("⏩ Skipping message %s: %s", event.Timestamp, reason)
+			b.slack.Decisions().Step(event.Channel, event.Timestamp, "matches patterns", false, reason)
+			return nil
 		}
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "matches patterns", true, "")
+	}
+
+	// People who opted out are left alone
+	if b.optedOut(event.User) {
+		b.loggerFor(ctx).Debugf("⏩ Skipping message %s, %s opted out", event.Timestamp, event.User)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "opted in", false, "author opted out")
+		return nil
+	}
 
-		// Log the message we're about to process
-		if b.logs {
-			b.logger.Printf("Received message from %s (%s):", user.RealName, user.Name)
-			b.logger.Printf("  Message text: %s", event.Text)
-			b.logger.Printf("  Channel: %s", event.Channel)
-			b.logger.Printf("  Timestamp: %s", event.Timestamp)
-		} else {
-			b.logger.Printf("Processing message from user %s (%s): %s", user.Name, user.ID, event.Text)
+	// Nothing is translated during quiet hours unless asked for
+	if !slackClient.IsOnDemand(event) && !slackClient.IsWatched(event) && !scheduleActive(b.schedule, time.Now()) {
+		b.loggerFor(ctx).Debugf("⏩ Skipping message %s, outside ACTIVE_HOURS/ACTIVE_DAYS", event.Timestamp)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "active hours", false, "outside ACTIVE_HOURS/ACTIVE_DAYS")
+		return nil
+	}
+
+	// Channels cool down between translations. Explicit requests, watched
+	// messages, announcements and edits aren't held back.
+	var posted bool
+	if !slackClient.IsOnDemand(event) && !slackClient.IsWatched(event) && !slackClient.IsAnnouncement(event) && !slackClient.IsEdit(event) {
+		if !b.cooldown.start(event.Channel, time.Now()) {
+			detail := "CHANNEL_COOLDOWN active"
+			if b.cooldown.queue(ctx, event, user, b.slack.WithDeadline(b.process)) {
+				detail += ", queued until it's over"
+			}
+			b.loggerFor(ctx).Debugf("⏩ Skipping message %s, %s", event.Timestamp, detail)
+			b.slack.Decisions().Step(event.Channel, event.Timestamp, "channel cooled down", false, detail)
+			return nil
 		}
+		defer func() { b.cooldown.finish(event.Channel, posted, time.Now()) }()
+	}
 
-		// Translate the message
-		if b.logs {
-			b.logger.Printf("Sending message to OpenAI for Gen Alpha translation")
+	// Heavy posters only get so many translations an hour. Direct
+	// messages are open to anyone, so they count too.
+	if (!slackClient.IsOnDemand(event) || slackClient.IsDirect(event)) && !slackClient.IsWatched(event) {
+		if ok, limit := b.rateLimit.allow(event.User, time.Now()); !ok {
+			b.loggerFor(ctx).Debugf("⏩ Skipping message %s, %s reached", event.Timestamp, limit)
+			b.slack.Decisions().Step(event.Channel, event.Timestamp, "within rate limit", false, limit+" reached")
+			return nil
 		}
-		
-		// Get the best display name using the fallback logic
-		displayName := getDisplayName(user)
-		
-		translatedText, err := b.openai.TranslateToGenAlpha(ctx, event.Text, displayName)
+	}
+
+	// Once the day's budget is used up nothing is translated until
+	// midnight, not even explicit requests
+	if b.budget.exhausted(time.Now()) {
+		b.loggerFor(ctx).Debugf("⏩ Skipping message %s, daily budget used up", event.Timestamp)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "within daily budget", false, "daily budget used up")
+		return nil
+	}
+
+	b.matched.Inc(metrics.Labels{Channel: event.Channel})
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("error translating message: %w", err)
+			b.translationFailures.Inc(metrics.Labels{Channel: event.Channel})
+		}
+	}()
+
+	// Edits replace the earlier translation, if there is one
+	var previous store.Reply
+	edit := slackClient.IsEdit(event)
+	if edit {
+		var ok bool
+		if previous, ok = b.editedTranslation(event); !ok {
+			return nil
 		}
+	}
+
+	// Let the author see the message was picked up
+	if b.progressReactions {
+		b.react(ctx, event, reactionWorking)
+		defer func() { b.finishProgress(ctx, event, posted, err) }()
+	}
 
-		if b.logs {
-			b.logger.Printf("Received translation from OpenAI:")
-			b.logger.Printf("  Original: %s", event.Text)
-			b.logger.Printf("  Translated: %s", translatedText)
+	// Log the message we're about to process
+	b.loggerFor(ctx).Debugf("Received message from %s (%s):", user.RealName, user.Name)
+	b.loggerFor(ctx).Debugf("  Message text: %s", event.Text)
+	b.loggerFor(ctx).Debugf("  Channel: %s", event.Channel)
+	b.loggerFor(ctx).Debugf("  Timestamp: %s", event.Timestamp)
+
+	// The model sees names and link labels, not Slack markup
+	event = b.resolvedEvent(ctx, event)
+
+	// Translate the message
+	b.loggerFor(ctx).Debugf("Sending message to the translator")
+
+	// Watched messages jump the queue for a translation slot
+	if slackClient.IsWatched(event) {
+		ctx = withPriority(ctx)
+	}
+
+	// Announcements get a TL;DR thread instead of a regular translation
+	if slackClient.IsAnnouncement(event) {
+		translationStyle := b.translationStyle(event.Channel)
+		var translation string
+		translation, err = b.postAnnouncement(ctx, event, user, translationStyle)
+		if posted = translation != ""; posted {
+			b.translations.Inc(metrics.Labels{Channel: event.Channel, Persona: translationStyle.Name, Model: b.translator.Model()})
+			b.stats.recordTranslation(event.Channel, event.User, config.OutputStyleTranslation, translation)
 		}
+		return err
+	}
 
-		// Format the response using the best display name
-		response := translatedText
+	// Get the best display name using the fallback logic, unless
+	// nobody may be named
+	var displayName string
+	if b.anonymous(event.Channel) {
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "anonymous", true, "")
+	} else {
+		displayName = getDisplayName(user)
+	}
 
-		if b.logs {
-			b.logger.Printf("Posting translation as channel message")
+	style := b.outputStyle(event.Channel)
+	b.slack.Decisions().Step(event.Channel, event.Timestamp, "output style", true, style)
+	translationStyle := b.translationStyle(event.Channel)
+	b.slack.Decisions().Step(event.Channel, event.Timestamp, "translation style", true, translationStyle.Name)
+	accessible := b.accessible(event.Channel)
+	if accessible {
+		translationStyle = translationStyle.Accessible()
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "accessible output", true, "")
+	}
+
+	// Post the translated message directly to the channel, or in the
+	// thread for explicit mention and shortcut requests and for
+	// fragments translated together; direct messages are answered
+	// where they were sent
+	var threadTS string
+	if slackClient.IsOnDemand(event) {
+		threadTS = event.ThreadTimestamp
+	} else if event.Type == messageTypeDebounced {
+		threadTS = event.ThreadTimestamp
+		if threadTS == "" {
+			threadTS = event.Timestamp
 		}
+	}
 
-		// Post the translated message directly to the channel
-		_, _, err = b.slack.PostMessage(ctx, event.Channel, response)
-		if err != nil {
-			return fmt.Errorf("error posting message: %w", err)
+	// Show the bot is on it while the model works, a streamed
+	// translation growing in the placeholder. Translations held for
+	// approval aren't public yet, so they get no placeholder.
+	confirm := b.confirmBeforePost(event.Channel)
+	var placeholderTS string
+	if (b.placeholder || b.stream) && !confirm && !edit {
+		placeholderTS = b.postPlaceholder(ctx, event.Channel, threadTS)
+	}
+
+	ctx, usage := translate.WithUsage(ctx)
+	stopStreaming := func() {}
+	if b.stream && placeholderTS != "" {
+		ctx, stopStreaming = b.streamInto(ctx, event.Channel, placeholderTS)
+	}
+	translateStart := time.Now()
+	translatedText, candidates, err := b.buildReply(ctx, event, displayName, style, translationStyle)
+	stopStreaming()
+	if err != nil {
+		if placeholderTS != "" {
+			b.failPlaceholder(ctx, event.Channel, placeholderTS)
 		}
+		return err
+	}
+	translateLatency := time.Since(translateStart)
+
+	if accessible {
+		translatedText = accessibleText(translatedText)
+	}
+	b.recordUsage(ctx, usage)
 
-		if b.logs {
-			b.logger.Printf("Successfully posted translation in channel %s", event.Channel)
-		} else {
-			b.logger.Printf("Posted translated message for %s", user.Name)
+	// Nothing offensive goes out in a work Slack
+	translatedText, ok := b.moderate(ctx, event, translatedText)
+	if !ok {
+		if placeholderTS != "" {
+			if err := b.slack.DeleteMessage(ctx, event.Channel, placeholderTS); err != nil {
+				b.loggerFor(ctx).Errorf("❌ Error removing placeholder of withheld translation: %v", err)
+			}
 		}
-		
 		return nil
+	}
+	b.recordAudit(ctx, event, translatedText, usage, translateLatency)
+	b.recordHistory(ctx, event, translatedText, usage)
+
+	b.loggerFor(ctx).Debugf("Received %s from the translator:", style)
+	b.loggerFor(ctx).Debugf("  Original: %s", event.Text)
+	b.loggerFor(ctx).Debugf("  Translated: %s", translatedText)
+
+	// Lay out the response with the reply template
+	response := b.renderReply(ctx, event, displayName, translatedText)
+
+	b.loggerFor(ctx).Debugf("Posting translation as channel message")
+
+	// Cautious channels get an approval step before anything is public
+	if confirm {
+		return b.requestApproval(ctx, event, threadTS, response, translationStyle.Name, style)
+	}
+
+	// The re-roll button translates the message again the same way
+	b.rerolls.remember(event.Channel, event.Timestamp, rerollable{
+		event:            event,
+		displayName:      displayName,
+		style:            style,
+		translationStyle: translationStyle,
+		accessible:       accessible,
+		candidates:       candidates,
+	})
+
+	var replyTS string
+	switch {
+	case edit:
+		err = b.postEditedTranslation(ctx, event, previous, response)
+	case placeholderTS != "":
+		replyTS, err = b.finishPlaceholder(ctx, event.Channel, threadTS, placeholderTS, event.Timestamp, event.User, response)
+	default:
+		replyTS, err = b.postReply(ctx, event.Channel, threadTS, event.Timestamp, event.User, response)
+	}
+	if err != nil {
+		return err
+	}
+	posted = true
+
+	// Introduce the bot the first time someone is translated here
+	if replyTS != "" {
+		b.onboard(ctx, event, threadTS, replyTS)
+	}
+
+	b.slack.Decisions().Translated(event.Channel, event.Timestamp, b.servedModel(usage), translateLatency)
+	b.translations.Inc(metrics.Labels{Channel: event.Channel, Persona: translationStyle.Name, Model: b.servedModel(usage)})
+	b.stats.recordTranslation(event.Channel, event.User, style, response)
+
+	b.loggerFor(ctx).Debugf("Posted %s for %s in channel %s", style, user.Name, event.Channel)
+
+	return nil
+}
+
+// postReply posts a reply in a channel, or in a thread when threadTS is
+// set, and records it in the state store. It returns the reply's timestamp.
+func (b *Bot) postReply(ctx context.Context, channelID, threadTS, originalTS, userID, text string) (string, error) {
+	// Names in a translation never notify anyone
+	postOptions := []slack.MsgOption{slack.MsgOptionLinkNames(false)}
+	if threadTS != "" {
+		postOptions = append(postOptions, slack.MsgOptionTS(threadTS))
+	}
+
+	var replyTS string
+	var err error
+	if blocks := b.replyBlocks(ctx, channelID, originalTS, userID, text); blocks != nil {
+		_, replyTS, err = b.slack.PostBlocks(ctx, channelID, text, blocks, postOptions...)
+	} else {
+		_, replyTS, err = b.slack.PostMessage(ctx, channelID, text, postOptions...)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error posting message: %w", err)
+	}
+
+	b.recordReply(channelID, originalTS, replyTS, userID)
+	return replyTS, nil
+}
+
+// recordReply remembers a posted reply for retention cleanup
+func (b *Bot) recordReply(channelID, originalTS, replyTS, userID string) {
+	err := b.store.RecordReply(store.Reply{
+		Channel:    channelID,
+		OriginalTS: originalTS,
+		ReplyTS:    replyTS,
+		User:       userID,
+		PostedAt:   time.Now(),
+	})
+	if err != nil {
+		b.logger.Errorf("❌ Error recording posted translation: %v", err)
+	}
+}
+
+// storeRetryInterval is how often writing a degraded state store is
+// retried, and catch-up checkpoints are saved
+const storeRetryInterval = 30 * time.Second
+
+// recoverStore retries writing the state store every interval while it is
+// degraded, and otherwise saves moved catch-up checkpoints, until ctx is
+// done
+func (b *Bot) recoverStore(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if b.store.Degraded() {
+				b.store.Flush()
+			} else if err := b.store.FlushSeen(); err != nil {
+				b.loggerFor(ctx).Errorf("❌ Error saving catch-up checkpoints: %v", err)
+			}
+		}
+	}
+}
+
+// outputStyle returns the output style configured for a channel
+func (b *Bot) outputStyle(channelID string) string {
+	if style, ok := b.channelOutputStyles[channelID]; ok {
+		return style
+	}
+	return b.defaultOutputStyle
+}
+
+// translationStyle returns the translation style configured for a channel
+func (b *Bot) translationStyle(channelID string) translate.Style {
+	if style, ok := b.channelTranslationStyles[channelID]; ok {
+		return style
+	}
+	return b.defaultTranslationStyle
+}
+
+// accessible reports whether a channel gets screen-reader friendly output
+func (b *Bot) accessible(channelID string) bool {
+	return b.accessibleOutput || b.accessibleChannels[channelID]
+}
+
+// anonymous reports whether translations in a channel name nobody, in the
+// prompt or the reply
+func (b *Bot) anonymous(channelID string) bool {
+	return b.anonymousAll || b.anonymousChannels[channelID]
+}
+
+// buildReply produces the reply text for a message in the given output
+// style: a translation, a one-line vibe check, or the vibe line above the
+// translation. When the model offered other translations it also returns
+// the replies they make, for re-rolls.
+func (b *Bot) buildReply(ctx context.Context, event *slackClient.IncomingMessage, displayName, style string, translationStyle translate.Style) (string, []string, error) {
+	var vibe string
+	if style == config.OutputStyleVibeCheck || style == config.OutputStyleBoth {
+		err := b.limited(ctx, func() error {
+			var err error
+			vibe, err = b.translator.VibeCheck(ctx, event.Text, displayName)
+			return err
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("error generating vibe check: %w", err)
+		}
+		if style == config.OutputStyleVibeCheck {
+			return vibe, nil, nil
+		}
+	}
+
+	// Shared/forwarded messages are passed along as quoted context
+	quotes := sharedMessages(event.Attachments)
+	if b.anonymous(event.Channel) {
+		for i := range quotes {
+			quotes[i].Author = ""
+		}
+	}
+	if len(quotes) > 0 {
+		b.loggerFor(ctx).Debugf("Message shares %d quoted message(s)", len(quotes))
+	}
+
+	thread := b.threadContext(ctx, event, displayName)
+
+	// A message too long to translate whole is summarized, and the
+	// summary translated instead
+	text, label := event.Text, ""
+	if tokens := threadctx.EstimateTokens(event.Text); b.summarizeTokens > 0 && tokens > b.summarizeTokens {
+		summary, err := b.summarizeLong(ctx, event.Text)
+		if err != nil {
+			return "", nil, err
+		}
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "summarized", true,
+			fmt.Sprintf("about %d tokens, more than SUMMARIZE_MESSAGE_TOKENS", tokens))
+		b.loggerFor(ctx).Debugf("📝 Summarized a message of about %d tokens: %s", tokens, summary)
+		text, label = summary, summaryLabel
+	}
+
+	translatedText, candidates, err := b.translateInThread(ctx, translationStyle, text, displayName, thread, quotes...)
+	if err != nil {
+		return "", nil, fmt.Errorf("error translating message: %w", err)
+	}
+	// Only the message's own translation is streamed
+	ctx = translate.WithPartials(ctx, nil)
+
+	// Optionally translate the quotes too, posted below the commentary
+	var translatedQuotes []string
+	if b.quoteMode == config.QuoteModeBoth {
+		for _, quote := range quotes {
+			translatedQuote, err := b.translate(ctx, translationStyle, quote.Text, quote.Author)
+			if err != nil {
+				return "", nil, fmt.Errorf("error translating quoted message: %w", err)
+			}
+			translatedQuotes = append(translatedQuotes, translatedQuote)
+		}
+	}
+
+	// Candidates get the same vibe line and quotes
+	reply := func(translation string) string {
+		if label != "" {
+			translation = label + " " + translation
+		}
+		for i, translatedQuote := range translatedQuotes {
+			translation = formatQuotedTranslation(translation, quotes[i], translatedQuote)
+		}
+		if vibe != "" {
+			translation = vibe + "\n" + translation
+		}
+		return translation
+	}
+	alternatives := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		alternatives = append(alternatives, reply(candidate))
+	}
+	return reply(translatedText), alternatives, nil
+}
+
+// DebugState returns a snapshot of the bot's internal state for
+// troubleshooting, served at /debug/state
+func (b *Bot) DebugState() v1.DebugState {
+	records := b.slack.Decisions().Recent()
+	decisions := make([]v1.DecisionRecord, 0, len(records))
+	for _, r := range records {
+		steps := make([]v1.DecisionStep, 0, len(r.Steps))
+		for _, step := range r.Steps {
+			steps = append(steps, v1.DecisionStep{Filter: step.Filter, Passed: step.Passed, Detail: step.Detail})
+		}
+
+		decisions = append(decisions, v1.DecisionRecord{
+			Channel:    r.Channel,
+			Timestamp:  r.Timestamp,
+			User:       r.User,
+			ReceivedAt: r.ReceivedAt,
+			Steps:      steps,
+			Translated: r.Translated,
+			Model:      r.Model,
+			LatencyMS:  r.Latency.Milliseconds(),
+			Error:      r.Error,
+		})
+	}
+
+	return v1.DebugState{
+		SchemaVersion:               v1.SchemaVersion,
+		StartupPhase:                b.slack.Phase(),
+		TranslationConcurrencyLimit: b.limiter.Limit(),
+		TranslationsInFlight:        b.limiter.InFlight(),
+		MetricLabelOverflow:         b.labelPolicy.Overflow(),
+		DuplicateEventsDropped:      b.slack.DuplicateEvents(),
+		StateStoreDegraded:          b.store.Degraded(),
+		Approvals: v1.ApprovalStats{
+			Approved:  atomic.LoadUint64(&b.approvals.approved),
+			Discarded: atomic.LoadUint64(&b.approvals.discarded),
+			Expired:   atomic.LoadUint64(&b.approvals.expired),
+		},
+		Decisions: decisions,
+	}
+}
+
+// Health reports whether the bot is working, served at /health. It turns
+// unhealthy when the socket mode connection has been down for longer than
+// the grace period, so an orchestrator can restart the bot.
+func (b *Bot) Health() v1.Health {
+	health := b.health()
+	if problem := b.slack.HealthProblem(); problem != "" {
+		health.Status, health.Problem = v1.HealthUnhealthy, problem
+	}
+	return health
+}
+
+// Readiness reports whether the bot has been able to receive events since
+// startup, served at /ready
+func (b *Bot) Readiness() v1.Health {
+	health := b.health()
+	if !b.slack.Ready() {
+		health.Status, health.Problem = v1.HealthNotReady, "no hello received from Slack yet"
+	}
+	return health
+}
+
+func (b *Bot) health() v1.Health {
+	health := v1.Health{
+		SchemaVersion:      v1.SchemaVersion,
+		Status:             v1.HealthOK,
+		Connected:          b.slack.Connected(),
+		ConnectionFailures: b.slack.ConnectionFailures(),
+		StateStoreDegraded: b.store.Degraded(),
+	}
+	if t := b.slack.LastConnectedAt(); !t.IsZero() {
+		health.LastConnectedAt = &t
+	}
+	return health
+}
+
+// SlackUsage summarizes the bot's Slack Web API calls over the last hour,
+// served at /debug/slack-usage
+func (b *Bot) SlackUsage() v1.SlackUsage {
+	bounds := make([]int64, 0, len(slackClient.LatencyBounds))
+	for _, bound := range slackClient.LatencyBounds {
+		bounds = append(bounds, bound.Milliseconds())
+	}
+
+	usage := b.slack.APIUsage()
+	methods := make([]v1.MethodUsage, 0, len(usage))
+	for _, m := range usage {
+		methods = append(methods, v1.MethodUsage{
+			Method:       m.Method,
+			Calls:        m.Calls,
+			RateLimited:  m.RateLimited,
+			AvgLatencyMS: m.AvgLatency.Milliseconds(),
+			Histogram:    m.Histogram,
+		})
+	}
+
+	return v1.SlackUsage{
+		SchemaVersion:   v1.SchemaVersion,
+		WindowSeconds:   int(time.Hour / time.Second),
+		LatencyBoundsMS: bounds,
+		Methods:         methods,
+	}
+}
+
+// translate calls the translator within the concurrency limit
+func (b *Bot) translate(ctx context.Context, style translate.Style, text, username string, quotes ...translate.QuotedMessage) (string, error) {
+	translated, _, err := b.translateInThread(ctx, style, text, username, nil, quotes...)
+	return translated, err
+}
+
+// translateInThread calls the translator with thread context within the
+// concurrency limit. It also returns the other translations the model
+// offered, if any.
+func (b *Bot) translateInThread(ctx context.Context, style translate.Style, text, username string, thread []translate.QuotedMessage, quotes ...translate.QuotedMessage) (string, []string, error) {
+	var translated string
+	var candidates []string
+	err := b.limited(ctx, func() error {
+		var err error
+		var result translate.TranslationResult
+		result, err = b.translator.Translate(ctx, translate.TranslationRequest{
+			Style:            b.withGlossary(style),
+			Message:          text,
+			Username:         username,
+			Thread:           thread,
+			Quotes:           quotes,
+			MaxMessageTokens: b.maxMessageTokens,
+			OutputLanguage:   b.outputLanguage,
+		})
+		if result.Truncated {
+			b.loggerFor(ctx).Debugf("✂️ Message is longer than MAX_MESSAGE_TOKENS, translated the start of it")
+		}
+		if result.Language != "" {
+			b.loggerFor(ctx).Debugf("🌐 Message is in %s", translate.LanguageName(result.Language))
+			b.stats.recordLanguage(result.Language)
+		}
+		translated, candidates = result.Text, result.Candidates
+		return err
 	})
+	return translated, candidates, err
+}
+
+// loggerFor returns the logger carrying the fields of the event ctx belongs
+// to
+func (b *Bot) loggerFor(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, b.logger)
+}
+
+// limited runs an LLM call within the concurrency limit, feeding the
+// call's latency and outcome back to the limiter
+func (b *Bot) limited(ctx context.Context, call func() error) error {
+	acquire := b.limiter.Acquire
+	if isPriority(ctx) {
+		acquire = b.limiter.AcquirePriority
+	}
+	if err := acquire(ctx); err != nil {
+		return fmt.Errorf("waiting for translation slot: %w", err)
+	}
+
+	start := time.Now()
+	err := call()
+	b.limiter.Release(time.Since(start), translationOutcome(err))
+	if err != nil {
+		b.stats.recordOpenAIError()
+	}
+
+	return err
+}
+
+type priorityKey struct{}
+
+// withPriority marks LLM calls made with ctx as high priority
+func withPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, priorityKey{}, true)
+}
+
+// isPriority reports whether ctx was marked by withPriority
+func isPriority(ctx context.Context) bool {
+	priority, _ := ctx.Value(priorityKey{}).(bool)
+	return priority
+}
+
+// translationOutcome classifies a translation error for the limiter:
+// timeouts and 429/5xx responses are load signals, anything else is not
+func translationOutcome(err error) concurrency.Outcome {
+	if err == nil {
+		return concurrency.Success
+	}
+
+	var netErr net.Error
+	if errors.Is(err, translate.ErrOverloaded) || errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return concurrency.Overload
+	}
+
+	return concurrency.Failure
 }
 
 // getDisplayName returns the best available display name for a user
@@ -174,10 +1044,10 @@ func getDisplayName(user *slack.User) string {
 	if user.Profile.DisplayName != "" {
 		return user.Profile.DisplayName
 	}
-	
+
 	if user.Name != "" {
 		return user.Name
 	}
-	
+
 	return user.RealName
-} 
\ No newline at end of file
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/slacktest"
+	"github.com/user/slack-bot-api/internal/translatetest"
+)
+
+const (
+	testChannel = "C0000001"
+	testUser    = "U0000001"
+)
+
+func testLogger() *logging.Logger {
+	return logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+}
+
+// newTestBot creates a bot talking to a fake Slack and translating with a
+// fake translator. It is configured through the environment like the real
+// one, with env on top of a minimal configuration translating everyone.
+func newTestBot(t *testing.T, env map[string]string) (*Bot, *slacktest.Server, *translatetest.Translator) {
+	t.Helper()
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+	t.Setenv("SLACK_TARGET_USERS", "*")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("TIMEZONE", "UTC")
+	t.Setenv("ONBOARDING_CARD", "false")
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	server := slacktest.NewServer(t)
+	server.AddUser(slack.User{ID: testUser, Name: "alice", Profile: slack.UserProfile{DisplayName: "alice"}})
+	translator := &translatetest.Translator{}
+	b, err := New(cfg, translator, nil, server.HTTPClient(), testLogger())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return b, server, translator
+}
+
+// testMessage is a new message in the test channel
+func testMessage(server *slacktest.Server, text string) *slackClient.IncomingMessage {
+	return &slackClient.IncomingMessage{
+		Channel:     testChannel,
+		ChannelType: "channel",
+		User:        testUser,
+		Text:        text,
+		Timestamp:   server.NextTS(),
+	}
+}
+
+// testAuthor is the author of testMessage
+func testAuthor() *slack.User {
+	return &slack.User{ID: testUser, Name: "alice", Profile: slack.UserProfile{DisplayName: "alice"}}
+}
+
+// postsOf returns the posts made with chat.postMessage
+func postsOf(server *slacktest.Server) []slacktest.Post {
+	var posts []slacktest.Post
+	for _, post := range server.Posts() {
+		if post.Method == "chat.postMessage" {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
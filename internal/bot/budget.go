@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/store"
+)
+
+// dailyBudget caps the LLM usage of a day, in tokens, estimated cost or
+// both. Once it's used up nothing is translated until midnight in location.
+// The day's usage is kept in the state store, so with STATE_FILE a restart
+// doesn't reset it. It is safe for concurrent use.
+type dailyBudget struct {
+	// A cap of 0 is no cap
+	tokens   int
+	cost     float64
+	prices   tokenPrices
+	location *time.Location
+	store    *store.Store
+
+	mu    sync.Mutex
+	spend store.DailySpend
+}
+
+func newDailyBudget(tokens int, cost float64, prices tokenPrices, location *time.Location, state *store.Store) *dailyBudget {
+	b := &dailyBudget{
+		tokens:   tokens,
+		cost:     cost,
+		prices:   prices,
+		location: location,
+		store:    state,
+	}
+	b.spend, _ = state.DailySpend()
+	return b
+}
+
+// enabled reports whether there is a cap
+func (b *dailyBudget) enabled() bool {
+	return b.tokens > 0 || b.cost > 0
+}
+
+// exhausted reports whether the day's budget is used up
+func (b *dailyBudget) exhausted(now time.Time) bool {
+	if !b.enabled() {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover(now)
+	return b.exhaustedLocked()
+}
+
+// add counts the tokens of a reply. It reports true when they used the
+// budget up and nobody was told yet today, so the notice is posted once.
+func (b *dailyBudget) add(promptTokens, completionTokens int, now time.Time) (bool, error) {
+	if !b.enabled() {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover(now)
+	b.spend.PromptTokens += promptTokens
+	b.spend.CompletionTokens += completionTokens
+
+	notify := !b.spend.Notified && b.exhaustedLocked()
+	if notify {
+		b.spend.Notified = true
+	}
+	return notify, b.store.SetDailySpend(b.spend)
+}
+
+// usage describes the day's usage against the budget
+func (b *dailyBudget) usage() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	used := fmt.Sprintf("%d tokens", b.spend.PromptTokens+b.spend.CompletionTokens)
+	if b.tokens > 0 {
+		used += fmt.Sprintf(" of %d", b.tokens)
+	}
+	if b.cost > 0 {
+		cost, _ := b.prices.cost(uint64(b.spend.PromptTokens), uint64(b.spend.CompletionTokens))
+		used += fmt.Sprintf(", about $%.2f of $%.2f", cost, b.cost)
+	}
+	return used
+}
+
+// rollover starts counting anew on a new day; b.mu must be held
+func (b *dailyBudget) rollover(now time.Time) {
+	day := now.In(b.location).Format(time.DateOnly)
+	if b.spend.Day != day {
+		b.spend = store.DailySpend{Day: day}
+	}
+}
+
+// exhaustedLocked reports whether either cap is reached; b.mu must be held
+func (b *dailyBudget) exhaustedLocked() bool {
+	if b.tokens > 0 && b.spend.PromptTokens+b.spend.CompletionTokens >= b.tokens {
+		return true
+	}
+	if b.cost > 0 {
+		cost, _ := b.prices.cost(uint64(b.spend.PromptTokens), uint64(b.spend.CompletionTokens))
+		return cost >= b.cost
+	}
+	return false
+}
+
+// postBudgetNotice tells BUDGET_ALERT_CHANNEL that translations are paused
+// for the rest of the day
+func (b *Bot) postBudgetNotice(ctx context.Context) {
+	usage := b.budget.usage()
+	b.loggerFor(ctx).Warnf("💸 Daily LLM budget used up (%s), pausing translations until midnight", usage)
+	if b.budgetAlertChannel == "" {
+		return
+	}
+
+	text := fmt.Sprintf("💸 The daily LLM budget is used up (%s). Translations are paused until midnight.", usage)
+	if _, _, err := b.slack.PostMessage(ctx, b.budgetAlertChannel, text); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error posting budget notice to %s: %v", b.budgetAlertChannel, err)
+	}
+}
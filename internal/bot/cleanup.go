@@ -0,0 +1,170 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/store"
+)
+
+const (
+	// cleanupInterval is how often expired translations are looked for
+	cleanupInterval = 5 * time.Minute
+
+	// cleanupBatchSize bounds the deletions of one run, so a large backlog
+	// is worked off gradually
+	cleanupBatchSize = 50
+
+	// cleanupDelay spaces out deletions to stay inside chat.delete's rate
+	// limit tier
+	cleanupDelay = 1200 * time.Millisecond
+)
+
+// Cleanup actions
+const (
+	CleanupDeleted     = "deleted"
+	CleanupWouldDelete = "would delete"
+	CleanupKept        = "kept"
+	CleanupGone        = "gone"
+	CleanupFailed      = "failed"
+)
+
+// CleanupResult is what happened to one expired translation
+type CleanupResult struct {
+	Reply  store.Reply
+	Action string
+	Detail string
+}
+
+// translationTTL returns how long translations are kept in a channel, 0
+// meaning forever
+func (b *Bot) translationTTL(channelID string) time.Duration {
+	if ttl, ok := b.channelTranslationTTLs[channelID]; ok {
+		return ttl
+	}
+	return b.defaultTranslationTTL
+}
+
+// retentionEnabled reports whether any channel has a translation TTL
+func (b *Bot) retentionEnabled() bool {
+	if b.defaultTranslationTTL > 0 {
+		return true
+	}
+	for _, ttl := range b.channelTranslationTTLs {
+		if ttl > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Cleanup deletes the bot's translations that are older than their
+// channel's TTL. Pinned translations and ones humans replied to are kept.
+// Records are pruned once a message is deleted, kept, or turns out to be
+// gone already; with dryRun nothing is deleted or pruned.
+func (b *Bot) Cleanup(ctx context.Context, dryRun bool) ([]CleanupResult, error) {
+	now := time.Now()
+	var results []CleanupResult
+	deleted := 0
+
+	for _, reply := range b.store.Replies() {
+		ttl := b.translationTTL(reply.Channel)
+		if ttl <= 0 || now.Sub(reply.PostedAt) < ttl {
+			continue
+		}
+		if !dryRun && deleted >= cleanupBatchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := b.cleanupReply(ctx, reply, dryRun)
+		results = append(results, result)
+		if result.Action == CleanupDeleted {
+			deleted++
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(cleanupDelay):
+			}
+		}
+	}
+	return results, nil
+}
+
+// cleanupReply handles a single expired translation
+func (b *Bot) cleanupReply(ctx context.Context, reply store.Reply, dryRun bool) CleanupResult {
+	result := CleanupResult{Reply: reply}
+
+	reason, err := b.slack.KeepReason(ctx, reply.Channel, reply.ReplyTS)
+	switch {
+	case err != nil && slackClient.IsPermanentError(err):
+		result.Action, result.Detail = CleanupGone, err.Error()
+	case err != nil:
+		// Probably transient; try again next run
+		result.Action, result.Detail = CleanupFailed, err.Error()
+		return result
+	case reason != "":
+		result.Action, result.Detail = CleanupKept, reason
+	case dryRun:
+		result.Action = CleanupWouldDelete
+		return result
+	default:
+		if err := b.slack.DeleteMessage(ctx, reply.Channel, reply.ReplyTS); err != nil {
+			if !slackClient.IsPermanentError(err) {
+				result.Action, result.Detail = CleanupFailed, err.Error()
+				return result
+			}
+			// Already deleted or no longer allowed to; either way it's done
+			result.Action, result.Detail = CleanupGone, err.Error()
+		} else {
+			result.Action = CleanupDeleted
+		}
+	}
+
+	if !dryRun {
+		if err := b.store.DeleteReply(reply.Channel, reply.ReplyTS); err != nil {
+			b.loggerFor(ctx).Errorf("❌ Error pruning translation record: %v", err)
+		}
+	}
+	return result
+}
+
+// runCleanup deletes expired translations periodically until ctx is done
+func (b *Bot) runCleanup(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		results, err := b.Cleanup(ctx, false)
+		if err != nil && ctx.Err() == nil {
+			b.loggerFor(ctx).Errorf("❌ Error cleaning up translations: %v", err)
+		}
+		for _, result := range results {
+			if result.Action == CleanupFailed {
+				b.loggerFor(ctx).Warnf("🧹 %s", FormatCleanupResult(result))
+			} else {
+				b.loggerFor(ctx).Infof("🧹 %s", FormatCleanupResult(result))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FormatCleanupResult renders a cleanup result as a single log line
+func FormatCleanupResult(result CleanupResult) string {
+	line := fmt.Sprintf("%s: translation %s in %s (posted %s)",
+		result.Action, result.Reply.ReplyTS, result.Reply.Channel, result.Reply.PostedAt.Format(time.RFC3339))
+	if result.Detail != "" {
+		line += " — " + result.Detail
+	}
+	return line
+}
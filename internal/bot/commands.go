@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/command"
+)
+
+// registerCommands adds the bot's /genalpha subcommands
+func (b *Bot) registerCommands() {
+	b.slack.Commands().Register(command.Command{
+		Name:        "status",
+		Description: "show how the bot is set up for this channel",
+		Handler:     b.statusCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "stats",
+		Description: "show how many translations were posted since startup, and for whom",
+		Handler:     b.statsCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "history",
+		Usage:       "[count]",
+		Description: "show the last translations in this channel",
+		Enabled:     b.history.Enabled,
+		Handler:     b.historyCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "leaderboard",
+		Description: "show who was translated most in this channel this week",
+		Enabled:     b.history.Enabled,
+		Handler:     b.leaderboardCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "glossary",
+		Usage:       glossaryUsage,
+		Description: "list the team's terms the translations use, or add one",
+		AdminOnly:   true,
+		Enabled:     b.glossary.Enabled,
+		Handler:     b.glossaryCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "optout",
+		Description: "stop translating your messages",
+		Handler:     b.optOutCommand,
+	})
+	b.slack.Commands().Register(command.Command{
+		Name:        "optin",
+		Description: "translate your messages again",
+		Handler:     b.optInCommand,
+	})
+}
+
+// statusCommand describes the settings that apply to the current channel
+func (b *Bot) statusCommand(ctx context.Context, req command.Request) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("*Settings for <#%s>*", req.ChannelID))
+	lines = append(lines, "• Translation style: `"+b.translationStyle(req.ChannelID).Name+"`")
+	lines = append(lines, "• Output style: `"+b.outputStyle(req.ChannelID)+"`")
+
+	if b.accessible(req.ChannelID) {
+		lines = append(lines, "• Accessible output: on")
+	} else {
+		lines = append(lines, "• Accessible output: off")
+	}
+
+	if b.anonymous(req.ChannelID) {
+		lines = append(lines, "• Anonymous: on")
+	} else {
+		lines = append(lines, "• Anonymous: off")
+	}
+
+	if b.confirmBeforePost(req.ChannelID) {
+		lines = append(lines, "• Approval before posting: on ("+b.approvalRate()+")")
+	} else {
+		lines = append(lines, "• Approval before posting: off")
+	}
+
+	if ttl := b.translationTTL(req.ChannelID); ttl > 0 {
+		lines = append(lines, "• Translations are deleted after "+ttl.String())
+	} else {
+		lines = append(lines, "• Translations are kept")
+	}
+
+	return strings.Join(lines, "\n")
+}
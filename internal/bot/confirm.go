@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/metrics"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/store"
+)
+
+const (
+	// approvalTTL is how long a translation waits for approval before it
+	// is discarded
+	approvalTTL = 30 * time.Minute
+
+	// approvalSweepInterval is how often expired approvals are discarded
+	approvalSweepInterval = time.Minute
+
+	approveActionID = "approve_translation"
+	discardActionID = "discard_translation"
+)
+
+// approvalStats counts how pending translations ended, to help teams decide
+// whether confirmation is still needed
+type approvalStats struct {
+	approved  uint64
+	discarded uint64
+	expired   uint64
+}
+
+// confirmBeforePost reports whether translations in a channel need approval
+// before being posted
+func (b *Bot) confirmBeforePost(channelID string) bool {
+	return b.confirmAll || b.confirmChannels[channelID]
+}
+
+// requestApproval sends a translation privately to the approver, with
+// buttons to post or discard it
+func (b *Bot) requestApproval(ctx context.Context, event *slackClient.IncomingMessage, threadTS, text, style, outputStyle string) error {
+	approver := b.confirmApprover
+	if approver == "" {
+		approver = event.User
+	}
+
+	pending := store.PendingApproval{
+		ID:          fmt.Sprintf("%s-%s-%d", event.Channel, event.Timestamp, time.Now().UnixNano()),
+		Channel:     event.Channel,
+		OriginalTS:  event.Timestamp,
+		ThreadTS:    threadTS,
+		User:        event.User,
+		Approver:    approver,
+		Text:        text,
+		Style:       style,
+		OutputStyle: outputStyle,
+		ExpiresAt:   time.Now().Add(approvalTTL),
+	}
+	if err := b.store.AddPendingApproval(pending); err != nil {
+		return fmt.Errorf("error saving pending approval: %w", err)
+	}
+
+	intro := fmt.Sprintf("Translation of <@%s>'s message, only visible to you. Post it? It's discarded automatically after %s.",
+		event.User, approvalTTL)
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, intro, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.PlainTextType, text, false, false), nil, nil),
+		slack.NewActionBlock("approval",
+			slack.NewButtonBlockElement(approveActionID, pending.ID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(discardActionID, pending.ID,
+				slack.NewTextBlockObject(slack.PlainTextType, "Discard", false, false)),
+		),
+	}
+
+	if err := b.slack.PostEphemeralBlocks(ctx, event.Channel, approver, "Translation awaiting your approval: "+text, blocks...); err != nil {
+		return fmt.Errorf("error sending translation for approval: %w", err)
+	}
+
+	b.slack.Decisions().Step(event.Channel, event.Timestamp, "approval", true, "sent to <@"+approver+"> for approval")
+	return nil
+}
+
+// handleApprovalAction posts or discards a pending translation
+func (b *Bot) handleApprovalAction(ctx context.Context, callback slack.InteractionCallback, action *slack.BlockAction) {
+	pending, ok, err := b.store.TakePendingApproval(action.Value, time.Now())
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error loading pending approval: %v", err)
+	}
+
+	var reply string
+	switch {
+	case !ok:
+		reply = "⌛ This translation expired or was already handled."
+	case action.ActionID == discardActionID:
+		atomic.AddUint64(&b.approvals.discarded, 1)
+		b.slack.Decisions().Step(pending.Channel, pending.OriginalTS, "approval", false, "discarded by <@"+callback.User.ID+">")
+		b.loggerFor(ctx).Infof("🗑️ Translation of %s in %s discarded by %s", pending.OriginalTS, pending.Channel, callback.User.ID)
+		reply = "🗑️ Translation discarded."
+	default:
+		if _, err := b.postReply(ctx, pending.Channel, pending.ThreadTS, pending.OriginalTS, pending.User, pending.Text); err != nil {
+			b.loggerFor(ctx).Errorf("❌ Error posting approved translation: %v", err)
+			reply = "❌ Couldn't post the translation: " + err.Error()
+			break
+		}
+
+		atomic.AddUint64(&b.approvals.approved, 1)
+		b.slack.Decisions().Step(pending.Channel, pending.OriginalTS, "approval", true, "approved by <@"+callback.User.ID+">")
+		b.translations.Inc(metrics.Labels{Channel: pending.Channel, Persona: pending.Style, Model: b.translator.Model()})
+		outputStyle := pending.OutputStyle
+		if outputStyle == "" {
+			// Held for approval before output styles were recorded
+			outputStyle = config.OutputStyleTranslation
+		}
+		b.stats.recordTranslation(pending.Channel, pending.User, outputStyle, pending.Text)
+		b.loggerFor(ctx).Infof("✅ Translation of %s in %s approved by %s", pending.OriginalTS, pending.Channel, callback.User.ID)
+		reply = "✅ Translation posted."
+	}
+
+	if err := b.slack.ReplaceInteractiveMessage(ctx, callback.ResponseURL, reply); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error updating approval message: %v", err)
+	}
+}
+
+// expireApprovals discards pending translations nobody acted on in time,
+// until ctx is done
+func (b *Bot) expireApprovals(ctx context.Context) {
+	ticker := time.NewTicker(approvalSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := b.store.ExpirePendingApprovals(time.Now())
+			if err != nil {
+				b.loggerFor(ctx).Errorf("❌ Error expiring pending approvals: %v", err)
+			}
+			for _, pending := range expired {
+				atomic.AddUint64(&b.approvals.expired, 1)
+				b.slack.Decisions().Step(pending.Channel, pending.OriginalTS, "approval", false, "expired without a decision")
+				b.loggerFor(ctx).Infof("⌛ Translation of %s in %s expired without approval", pending.OriginalTS, pending.Channel)
+			}
+		}
+	}
+}
+
+// approvalRate renders the share of decided translations that were
+// approved, e.g. "3 of 4 approved (75%), 1 expired"
+func (b *Bot) approvalRate() string {
+	approved := atomic.LoadUint64(&b.approvals.approved)
+	discarded := atomic.LoadUint64(&b.approvals.discarded)
+	expired := atomic.LoadUint64(&b.approvals.expired)
+
+	decided := approved + discarded
+	if decided == 0 {
+		return fmt.Sprintf("no decisions yet, %d expired", expired)
+	}
+	return fmt.Sprintf("%d of %d approved (%d%%), %d expired", approved, decided, approved*100/decided, expired)
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/logging"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// channelCooldown spaces out translations in a channel: after one is posted,
+// messages are skipped for the cooldown period. Optionally the most recent
+// skipped message is translated once the period is over. A period of 0
+// turns it off. It is safe for concurrent use.
+type channelCooldown struct {
+	period      time.Duration
+	queueLatest bool
+	logger      *logging.Logger
+
+	mu       sync.Mutex
+	channels map[string]*cooldownState
+	stopped  bool
+
+	// Queued messages being translated, waited for by stop
+	inFlight sync.WaitGroup
+}
+
+// cooldownState is the cooldown of one channel
+type cooldownState struct {
+	// No translation starts before until
+	until time.Time
+	// A translation is in flight; previous is until from before it, put
+	// back if nothing gets posted
+	busy     bool
+	previous time.Time
+
+	// The most recent skipped message, translated when the timer fires
+	pending *queuedMessage
+	timer   *time.Timer
+}
+
+// queuedMessage is a message held until the cooldown is over. ctx outlives
+// the processing of the message it came with, so process has to bring its
+// own deadline.
+type queuedMessage struct {
+	ctx     context.Context
+	event   *slackClient.IncomingMessage
+	user    *slack.User
+	process slackClient.Processor
+}
+
+func newChannelCooldown(period time.Duration, queueLatest bool, logger *logging.Logger) *channelCooldown {
+	return &channelCooldown{
+		period:      period,
+		queueLatest: queueLatest,
+		logger:      logger,
+		channels:    make(map[string]*cooldownState),
+	}
+}
+
+// start claims the channel for a translation, reporting false when the
+// channel is cooling down or another translation is in flight. Every
+// successful start must be followed by finish.
+func (cd *channelCooldown) start(channelID string, now time.Time) bool {
+	if cd.period <= 0 {
+		return true
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	state := cd.state(channelID)
+	if state.busy || now.Before(state.until) {
+		return false
+	}
+	state.busy = true
+	state.previous = state.until
+	return true
+}
+
+// finish releases the channel after a translation. Posting starts the
+// cooldown; otherwise the channel is left as it was before start.
+func (cd *channelCooldown) finish(channelID string, posted bool, now time.Time) {
+	if cd.period <= 0 {
+		return
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	state := cd.state(channelID)
+	state.busy = false
+	if posted {
+		state.until = now.Add(cd.period)
+	} else {
+		state.until = state.previous
+	}
+	if state.pending != nil && state.timer == nil && !cd.stopped {
+		cd.arm(channelID, state, now)
+	}
+}
+
+// queue holds a skipped message to be passed to process when the cooldown
+// is over, replacing any message held before. It reports whether the
+// message was queued; nothing is once stop was called.
+func (cd *channelCooldown) queue(ctx context.Context, event *slackClient.IncomingMessage, user *slack.User, process slackClient.Processor) bool {
+	if !cd.queueLatest {
+		return false
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	if cd.stopped {
+		return false
+	}
+	state := cd.state(event.Channel)
+	state.pending = &queuedMessage{ctx: context.WithoutCancel(ctx), event: event, user: user, process: process}
+	if state.timer == nil && !state.busy {
+		cd.arm(event.Channel, state, time.Now())
+	}
+	return true
+}
+
+// arm starts the timer translating the pending message when the cooldown
+// ends. A translation in flight arms it again when it finishes.
+func (cd *channelCooldown) arm(channelID string, state *cooldownState, now time.Time) {
+	state.timer = time.AfterFunc(state.until.Sub(now), func() {
+		cd.mu.Lock()
+		state := cd.state(channelID)
+		message := state.pending
+		state.pending = nil
+		state.timer = nil
+		if message == nil || cd.stopped {
+			cd.mu.Unlock()
+			return
+		}
+		cd.inFlight.Add(1)
+		cd.mu.Unlock()
+		defer cd.inFlight.Done()
+
+		logging.FromContext(message.ctx, cd.logger).Debugf("Cooldown over in %s, translating queued message %s", channelID, message.event.Timestamp)
+		if err := message.process(message.ctx, message.event, message.user); err != nil {
+			logging.FromContext(message.ctx, cd.logger).Errorf("❌ Error processing queued message: %v", err)
+		}
+	})
+}
+
+// stop drops the queued messages, so nothing new is translated during
+// shutdown, and waits for queued messages being translated
+func (cd *channelCooldown) stop() {
+	cd.mu.Lock()
+	cd.stopped = true
+	var dropped int
+	for _, state := range cd.channels {
+		if state.timer != nil {
+			state.timer.Stop()
+			state.timer = nil
+		}
+		if state.pending != nil {
+			dropped++
+			state.pending = nil
+		}
+	}
+	cd.mu.Unlock()
+
+	if dropped > 0 {
+		cd.logger.Infof("Dropped %d queued messages on shutdown", dropped)
+	}
+	cd.inFlight.Wait()
+}
+
+func (cd *channelCooldown) state(channelID string) *cooldownState {
+	state, ok := cd.channels[channelID]
+	if !ok {
+		state = &cooldownState{}
+		cd.channels[channelID] = state
+	}
+	return state
+}
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The message queued during a cooldown is translated after the message it
+// was skipped in favour of, so MESSAGE_PROCESSING_TIMEOUT must not have
+// run out on it
+func TestCooldownQueueWithProcessingTimeout(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{
+		"CHANNEL_COOLDOWN":           "100ms",
+		"CHANNEL_COOLDOWN_QUEUE":     "true",
+		"MESSAGE_PROCESSING_TIMEOUT": "5s",
+	})
+	defer b.cooldown.stop()
+	process := b.slack.WithDeadline(b.process)
+
+	for _, text := range []string{"first message no cap", "second message during the cooldown"} {
+		if err := process(context.Background(), testMessage(server, text), testAuthor()); err != nil {
+			t.Fatalf("processing %q: %v", text, err)
+		}
+	}
+	if got := len(postsOf(server)); got != 1 {
+		t.Fatalf("%d posts during the cooldown, want 1", got)
+	}
+
+	server.WaitFor(5*time.Second, "the queued message to be posted", func() bool { return len(postsOf(server)) == 2 })
+	if last := postsOf(server)[1]; !strings.Contains(last.Text, "second message") {
+		t.Errorf("second post %q isn't the queued message's translation", last.Text)
+	}
+	if got := len(translator.Messages()); got != 2 {
+		t.Errorf("%d translations, want 2", got)
+	}
+}
+
+// Messages still queued at shutdown are dropped
+func TestCooldownStopDropsQueuedMessages(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{
+		"CHANNEL_COOLDOWN":       "100ms",
+		"CHANNEL_COOLDOWN_QUEUE": "true",
+	})
+
+	for _, text := range []string{"first message no cap", "second message during the cooldown"} {
+		if err := b.process(context.Background(), testMessage(server, text), testAuthor()); err != nil {
+			t.Fatalf("processing %q: %v", text, err)
+		}
+	}
+	b.cooldown.stop()
+
+	time.Sleep(300 * time.Millisecond)
+	if got := len(translator.Messages()); got != 1 {
+		t.Errorf("%d translations after stop, want only the first message's", got)
+	}
+	if b.cooldown.queue(context.Background(), testMessage(server, "after shutdown"), testAuthor(), b.process) {
+		t.Errorf("message queued after stop")
+	}
+}
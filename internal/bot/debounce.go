@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/logging"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// messageTypeDebounced marks a message combined from fragments a user sent
+// in quick succession. It takes the timestamp of the last fragment, whose
+// thread the translation is posted in.
+const messageTypeDebounced = "debounced"
+
+// messageDebouncer holds messages per user and channel for a window, so
+// fragments sent in quick succession are translated together. Every
+// message restarts the window, and a batch is translated as soon as it
+// would grow past maxLength characters. A window of 0 turns it off. It is
+// safe for concurrent use.
+type messageDebouncer struct {
+	window    time.Duration
+	maxLength int
+	logger    *logging.Logger
+
+	mu      sync.Mutex
+	batches map[string]*debounceBatch
+	stopped bool
+
+	// Batches being translated, waited for by stop
+	inFlight sync.WaitGroup
+}
+
+// debounceBatch is the messages held for one user in one channel
+type debounceBatch struct {
+	events []*slackClient.IncomingMessage
+	length int
+	timer  *time.Timer
+
+	// From the latest message, to translate the batch with. ctx outlives
+	// the processing of the message it came with, so process has to bring
+	// its own deadline.
+	ctx     context.Context
+	user    *slack.User
+	process slackClient.Processor
+}
+
+func newMessageDebouncer(window time.Duration, maxLength int, logger *logging.Logger) *messageDebouncer {
+	return &messageDebouncer{
+		window:    window,
+		maxLength: maxLength,
+		logger:    logger,
+		batches:   make(map[string]*debounceBatch),
+	}
+}
+
+type releasedKey struct{}
+
+// applies reports whether a message may be held. Only new messages picked
+// up by the filters are; explicit requests, watched messages,
+// announcements, edits and messages the debouncer released are not.
+func (d *messageDebouncer) applies(ctx context.Context, event *slackClient.IncomingMessage) bool {
+	released, _ := ctx.Value(releasedKey{}).(bool)
+	return d.window > 0 && !released && !slackClient.IsOnDemand(event) && !slackClient.IsWatched(event) &&
+		!slackClient.IsAnnouncement(event) && !slackClient.IsEdit(event)
+}
+
+// hold adds a message to its author's batch in the channel and restarts the
+// window. A batch the message would make too long is translated first.
+// A message too long on its own isn't held, and hold reports false so it
+// is translated right away; so is every message once stop was called.
+func (d *messageDebouncer) hold(ctx context.Context, event *slackClient.IncomingMessage, user *slack.User, process slackClient.Processor) bool {
+	length := utf8.RuneCountInString(event.Text)
+	key := event.Channel + "/" + event.User
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return false
+	}
+
+	batch := d.batches[key]
+	if batch != nil && batch.length+length > d.maxLength {
+		d.release(key, batch)
+		batch = nil
+	}
+	if length > d.maxLength {
+		return false
+	}
+
+	if batch == nil {
+		batch = &debounceBatch{}
+		d.batches[key] = batch
+		batch.timer = time.AfterFunc(d.window, func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			// The batch may have been released since the timer fired
+			if d.batches[key] == batch {
+				d.release(key, batch)
+			}
+		})
+	} else {
+		batch.timer.Reset(d.window)
+	}
+
+	batch.events = append(batch.events, event)
+	batch.length += length
+	batch.ctx, batch.user, batch.process = context.WithoutCancel(ctx), user, process
+	return true
+}
+
+// release translates a batch in the background. d.mu must be held.
+func (d *messageDebouncer) release(key string, batch *debounceBatch) {
+	batch.timer.Stop()
+	delete(d.batches, key)
+
+	d.inFlight.Add(1)
+	go func() {
+		defer d.inFlight.Done()
+
+		event := batch.events[len(batch.events)-1]
+		if len(batch.events) > 1 {
+			event = combineMessages(batch.events)
+			logging.FromContext(batch.ctx, d.logger).Debugf("Translating %d messages from %s in %s together", len(batch.events), event.User, event.Channel)
+		}
+
+		ctx := context.WithValue(batch.ctx, releasedKey{}, true)
+		if err := batch.process(ctx, event, batch.user); err != nil {
+			logging.FromContext(batch.ctx, d.logger).Errorf("❌ Error processing debounced messages: %v", err)
+		}
+	}()
+}
+
+// stop drops the messages still held, so nothing new is translated during
+// shutdown, and waits for batches being translated
+func (d *messageDebouncer) stop() {
+	d.mu.Lock()
+	d.stopped = true
+	var dropped int
+	for key, batch := range d.batches {
+		batch.timer.Stop()
+		dropped += len(batch.events)
+		delete(d.batches, key)
+	}
+	d.mu.Unlock()
+
+	if dropped > 0 {
+		d.logger.Infof("Dropped %d held messages on shutdown", dropped)
+	}
+	d.inFlight.Wait()
+}
+
+// combineMessages joins fragments into one message: the last one, with the
+// text, attachments and files of all of them
+func combineMessages(events []*slackClient.IncomingMessage) *slackClient.IncomingMessage {
+	combined := *events[len(events)-1]
+	combined.Type = messageTypeDebounced
+	combined.Attachments, combined.Files = nil, nil
+
+	texts := make([]string, len(events))
+	for i, event := range events {
+		texts[i] = event.Text
+		combined.Attachments = append(combined.Attachments, event.Attachments...)
+		combined.Files = append(combined.Files, event.Files...)
+	}
+	combined.Text = strings.Join(texts, "\n")
+	return &combined
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// A held batch is translated after the message that started it was
+// processed, so MESSAGE_PROCESSING_TIMEOUT must not have run out on it
+func TestDebounceWithProcessingTimeout(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{
+		"DEBOUNCE_WINDOW":            "50ms",
+		"MESSAGE_PROCESSING_TIMEOUT": "5s",
+	})
+	defer b.debounce.stop()
+	process := b.slack.WithDeadline(b.process)
+
+	for _, text := range []string{"so i was thinking", "maybe we redo the deploy script"} {
+		if err := process(context.Background(), testMessage(server, text), testAuthor()); err != nil {
+			t.Fatalf("processing %q: %v", text, err)
+		}
+	}
+	if len(postsOf(server)) != 0 {
+		t.Fatalf("fragments translated before the window was over")
+	}
+
+	server.WaitFor(5*time.Second, "the batch to be posted", func() bool { return len(postsOf(server)) == 1 })
+	messages := translator.Messages()
+	if len(messages) != 1 || messages[0] != "so i was thinking\nmaybe we redo the deploy script" {
+		t.Errorf("translated %q, want both fragments together", messages)
+	}
+}
+
+// Each released batch gets a deadline of its own
+func TestDebouncedBatchTimesOut(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{
+		"DEBOUNCE_WINDOW":            "20ms",
+		"MESSAGE_PROCESSING_TIMEOUT": "100ms",
+	})
+	defer b.debounce.stop()
+	translator.TranslateFunc = func(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error) {
+		<-ctx.Done()
+		return translate.TranslationResult{}, ctx.Err()
+	}
+
+	if err := b.slack.WithDeadline(b.process)(context.Background(), testMessage(server, "this batch never comes back"), testAuthor()); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	server.WaitFor(5*time.Second, "the batch to be translated", func() bool { return len(translator.Messages()) == 1 })
+
+	done := make(chan struct{})
+	go func() {
+		b.debounce.stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("released batch still translating long after MESSAGE_PROCESSING_TIMEOUT")
+	}
+	for _, post := range postsOf(server) {
+		if strings.Contains(post.Text, "never comes back") {
+			t.Errorf("timed out batch posted: %q", post.Text)
+		}
+	}
+}
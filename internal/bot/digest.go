@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// digestTally sums up the translations between two daily digests
+type digestTally struct {
+	count uint64
+	users map[string]uint64
+	// best is the longest translation, the digest's highlight
+	best digestHighlight
+}
+
+// digestHighlight is a translation quoted in the digest
+type digestHighlight struct {
+	channel string
+	user    string
+	text    string
+}
+
+func newDigestTally() digestTally {
+	return digestTally{users: make(map[string]uint64)}
+}
+
+// add counts a posted translation
+func (t *digestTally) add(channelID, userID, translated string) {
+	t.count++
+	t.users[userID]++
+	if utf8.RuneCountInString(translated) > utf8.RuneCountInString(t.best.text) {
+		t.best = digestHighlight{channel: channelID, user: userID, text: translated}
+	}
+}
+
+// runDigest posts the daily digest at the configured time until ctx is done
+func (b *Bot) runDigest(ctx context.Context) {
+	for {
+		next := nextDigest(time.Now(), b.digestTime, b.digestLocation)
+		b.loggerFor(ctx).Debugf("Next daily digest at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		b.postDigest(ctx)
+	}
+}
+
+// nextDigest returns the first time after now at the time of day at, an
+// offset from midnight in location
+func nextDigest(now time.Time, at time.Duration, location *time.Location) time.Time {
+	now = now.In(location)
+	hour, minute := int(at/time.Hour), int(at%time.Hour/time.Minute)
+
+	year, month, day := now.Date()
+	next := time.Date(year, month, day, hour, minute, 0, 0, location)
+	if !next.After(now) {
+		next = time.Date(year, month, day+1, hour, minute, 0, 0, location)
+	}
+	return next
+}
+
+// postDigest posts the recap of the translations since the last digest.
+// Nothing is posted when there were none.
+func (b *Bot) postDigest(ctx context.Context) {
+	tally := b.stats.takeDigest()
+	if tally.count == 0 {
+		b.loggerFor(ctx).Infof("📊 No translations since the last digest, skipping it")
+		return
+	}
+
+	var bestChannel string
+	if tally.best.text != "" {
+		bestChannel = b.channelMention(ctx, tally.best.channel)
+	}
+	if _, _, err := b.slack.PostMessage(ctx, b.digestChannel, formatDigest(tally, bestChannel)); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error posting daily digest to %s: %v", b.digestChannel, err)
+		return
+	}
+	b.loggerFor(ctx).Infof("📊 Posted daily digest of %d translations to %s", tally.count, b.digestChannel)
+}
+
+// formatDigest renders a tally as the digest message, linking the
+// highlight's channel with bestChannel
+func formatDigest(tally digestTally, bestChannel string) string {
+	messages := "messages"
+	if tally.count == 1 {
+		messages = "message"
+	}
+	top := sortedCounts(tally.users)[0]
+
+	text := fmt.Sprintf("📊 Today the bot translated %d %s; top victim: <@%s>", tally.count, messages, top.ID)
+	if tally.best.text != "" {
+		text += fmt.Sprintf("; best one, for <@%s> in %s:\n> %s", tally.best.user, bestChannel, oneLine(tally.best.text))
+	}
+	return text
+}
@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/user/slack-bot-api/config"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/store"
+)
+
+// editedTranslation returns the translation to replace for an edited
+// message. Edits are skipped when they're ignored by configuration, or when
+// the bot never translated the original (or has forgotten it).
+func (b *Bot) editedTranslation(event *slackClient.IncomingMessage) (store.Reply, bool) {
+	if b.editedMessages == config.EditedMessagesIgnore {
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "edit", false, "EDITED_MESSAGES is ignore")
+		return store.Reply{}, false
+	}
+
+	previous, ok := b.store.ReplyTo(event.Channel, event.Timestamp)
+	if !ok {
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "edit", false, "no earlier translation to replace")
+		return store.Reply{}, false
+	}
+
+	b.slack.Decisions().Step(event.Channel, event.Timestamp, "edit", true, b.editedMessages)
+	return previous, true
+}
+
+// postEditedTranslation replaces the earlier translation of an edited
+// message, or posts the new one in the message's thread
+func (b *Bot) postEditedTranslation(ctx context.Context, event *slackClient.IncomingMessage, previous store.Reply, text string) error {
+	if b.editedMessages == config.EditedMessagesThread {
+		threadTS := event.ThreadTimestamp
+		if threadTS == "" {
+			threadTS = event.Timestamp
+		}
+		_, err := b.postReply(ctx, event.Channel, threadTS, event.Timestamp, event.User, "✏️ Updated translation: "+text)
+		return err
+	}
+
+	if err := b.slack.UpdateMessage(ctx, previous.Channel, previous.ReplyTS, text, b.replyBlocks(ctx, previous.Channel, previous.OriginalTS, event.User, text)...); err != nil {
+		return fmt.Errorf("error updating translation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/user/slack-bot-api/internal/codeblock"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/slackfmt"
+	"github.com/user/slack-bot-api/internal/threadctx"
+)
+
+// messageFilter skips messages not worth translating, like "ok" or a lone
+// 👍. Each filter is off when its setting is zero or false.
+type messageFilter struct {
+	minLength int
+	minWords  int
+	emojiOnly bool
+	urlOnly   bool
+	// maxTokens skips messages too long to be worth their cost, even when
+	// someone asked for them
+	maxTokens int
+
+	// SKIP_PATTERNS and REQUIRE_PATTERNS, matched against the text with
+	// Slack markup resolved
+	skipPatterns    []*regexp.Regexp
+	requirePatterns []*regexp.Regexp
+}
+
+// emojiCode matches Slack's :emoji_name: codes, including skin tones like
+// :+1::skin-tone-2:
+var emojiCode = regexp.MustCompile(`:[a-z0-9_+\-']+:`)
+
+// linkMarkup matches Slack's <https://...> link markup
+var linkMarkup = regexp.MustCompile(`<(?:https?|mailto):[^>]*>`)
+
+// skipReason returns why a message isn't translated, or "" when it is.
+// Messages someone asked to have translated and watched messages are never
+// skipped, except for being only code or far too long.
+func (f messageFilter) skipReason(event *slackClient.IncomingMessage) string {
+	if codeblock.Protect(event.Text).OnlyCode() {
+		return "message is only code"
+	}
+	if f.maxTokens > 0 && threadctx.EstimateTokens(event.Text) > f.maxTokens {
+		return "message is longer than SKIP_MESSAGE_TOKENS"
+	}
+	if slackClient.IsOnDemand(event) || slackClient.IsWatched(event) {
+		return ""
+	}
+
+	text := strings.TrimSpace(event.Text)
+	if f.urlOnly && text != "" && strings.TrimSpace(linkMarkup.ReplaceAllString(text, "")) == "" {
+		return "message is only links"
+	}
+	if f.emojiOnly && text != "" && isEmojiOnly(text) {
+		return "message is only emoji"
+	}
+
+	// Length is measured on the text as shown, not on the markup
+	plain := strings.TrimSpace(slackfmt.Resolve(text, slackfmt.Resolver{}))
+	if f.minLength > 0 && utf8.RuneCountInString(plain) < f.minLength {
+		return "message is shorter than MIN_MESSAGE_LENGTH"
+	}
+	if f.minWords > 0 && len(strings.Fields(plain)) < f.minWords {
+		return "message has fewer words than MIN_MESSAGE_WORDS"
+	}
+	return ""
+}
+
+// matchesPatterns reports whether a message is subject to the skip and
+// require patterns. Like the other filters, they leave messages someone
+// asked to have translated and watched messages alone.
+func (f messageFilter) matchesPatterns(event *slackClient.IncomingMessage) bool {
+	return (len(f.skipPatterns) > 0 || len(f.requirePatterns) > 0) &&
+		!slackClient.IsOnDemand(event) && !slackClient.IsWatched(event)
+}
+
+// patternSkipReason returns why a message's resolved text isn't
+// translated because of the skip or require patterns, naming the pattern
+// responsible, or "" when it is
+func (f messageFilter) patternSkipReason(text string) string {
+	for _, pattern := range f.skipPatterns {
+		if pattern.MatchString(text) {
+			return fmt.Sprintf("message matches SKIP_PATTERNS pattern %q", pattern)
+		}
+	}
+	if len(f.requirePatterns) == 0 {
+		return ""
+	}
+	for _, pattern := range f.requirePatterns {
+		if pattern.MatchString(text) {
+			return ""
+		}
+	}
+	return "message matches no REQUIRE_PATTERNS pattern"
+}
+
+// isEmojiOnly reports whether text is nothing but emoji and :emoji_codes:
+func isEmojiOnly(text string) bool {
+	text = strings.TrimSpace(emojiCode.ReplaceAllString(text, " "))
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !isEmoji(r) && !isEmojiPart(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// Messages over MAX_MESSAGE_TOKENS are sent to be cut short, and those over
+// SKIP_MESSAGE_TOKENS aren't translated at all, even when asked for
+func TestSkipMessageTokens(t *testing.T) {
+	sentence := "The cache cluster failed over and the API returned errors. "
+	long := strings.Repeat(sentence, 14)    // about 210 tokens
+	tooLong := strings.Repeat(sentence, 40) // about 600 tokens
+
+	b, server, translator := newTestBot(t, map[string]string{
+		"MAX_MESSAGE_TOKENS":  "100",
+		"SKIP_MESSAGE_TOKENS": "400",
+	})
+
+	mention := testMessage(server, tooLong)
+	mention.Type = slackClient.MessageTypeMention
+	for _, message := range []*slackClient.IncomingMessage{testMessage(server, long), testMessage(server, tooLong), mention} {
+		if err := b.process(context.Background(), message, testAuthor()); err != nil {
+			t.Fatalf("process: %v", err)
+		}
+	}
+
+	requests := translator.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("%d translations, want only the message under SKIP_MESSAGE_TOKENS", len(requests))
+	}
+	if requests[0].Message != long {
+		t.Errorf("translated %q, want the shorter message", requests[0].Message)
+	}
+	if requests[0].MaxMessageTokens != 100 {
+		t.Errorf("MaxMessageTokens = %d, want 100", requests[0].MaxMessageTokens)
+	}
+}
+
+func TestSkipReasonTooLong(t *testing.T) {
+	filter := messageFilter{maxTokens: 8000}
+	postmortem := strings.Repeat("word ", 8000*4/5+5)
+
+	if got, want := filter.skipReason(&slackClient.IncomingMessage{Text: postmortem}), "message is longer than SKIP_MESSAGE_TOKENS"; got != want {
+		t.Errorf("skipReason = %q, want %q", got, want)
+	}
+	if got := filter.skipReason(&slackClient.IncomingMessage{Text: postmortem[:8000*4]}); got != "" {
+		t.Errorf("skipReason of a message of exactly SKIP_MESSAGE_TOKENS = %q, want none", got)
+	}
+}
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/command"
+	"github.com/user/slack-bot-api/internal/glossary"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// glossaryUsage explains the glossary command's arguments
+const glossaryUsage = "[add <term> = <meaning>]"
+
+// withGlossary adds the team's glossary to a style's system prompt
+func (b *Bot) withGlossary(style translate.Style) translate.Style {
+	section, _ := b.glossary.Prompt()
+	return style.WithGlossary(section)
+}
+
+// glossaryCommand lists the glossary, or adds a term to it with
+// "add <term> = <meaning>"
+func (b *Bot) glossaryCommand(ctx context.Context, req command.Request) string {
+	if len(req.Args) == 0 {
+		return formatGlossary(b.glossary.Entries())
+	}
+
+	if strings.ToLower(req.Args[0]) != "add" {
+		return fmt.Sprintf("🤔 Usage: `%s glossary %s`", req.Prefix, glossaryUsage)
+	}
+	term, meaning, ok := strings.Cut(strings.Join(req.Args[1:], " "), "=")
+	if !ok || strings.TrimSpace(term) == "" || strings.TrimSpace(meaning) == "" {
+		return fmt.Sprintf("🤔 Usage: `%s glossary %s`", req.Prefix, glossaryUsage)
+	}
+
+	if err := b.glossary.Add(term, meaning); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error adding %q to the glossary: %v", term, err)
+		return "⚠️ Couldn't save the glossary: " + err.Error()
+	}
+	term = strings.TrimSpace(term)
+	b.loggerFor(ctx).Infof("📖 %s added %q to the glossary", req.UserID, term)
+
+	reply := fmt.Sprintf("📖 Added *%s* to the glossary.", term)
+	if _, omitted := b.glossary.Prompt(); omitted > 0 {
+		reply += fmt.Sprintf(" %d terms no longer fit in GLOSSARY_MAX_TOKENS and are left out of the prompt.", omitted)
+	}
+	return reply
+}
+
+// formatGlossary lists the glossary's terms, one per line
+func formatGlossary(entries []glossary.Entry) string {
+	if len(entries) == 0 {
+		return "📖 The glossary is empty."
+	}
+	lines := []string{"*Glossary*"}
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("• *%s*: %s", entry.Term, entry.Meaning))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/user/slack-bot-api/internal/command"
+	"github.com/user/slack-bot-api/internal/history"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	// historyDefault and historyMax bound how many translations the
+	// history command lists
+	historyDefault = 5
+	historyMax     = 20
+
+	// oneLineLength is how much of a message is shown when listing it
+	oneLineLength = 150
+
+	// leaderboardSize is how many users the leaderboard lists
+	leaderboardSize = 10
+)
+
+// recordHistory adds a translation to the history database. Failing to
+// write it is logged and otherwise ignored.
+func (b *Bot) recordHistory(ctx context.Context, event *slackClient.IncomingMessage, translated string, usage *translate.Usage) {
+	prompt, completion := usage.Tokens()
+	err := b.history.Record(ctx, history.Translation{
+		Time:             time.Now(),
+		Channel:          event.Channel,
+		User:             event.User,
+		OriginalTS:       event.Timestamp,
+		Original:         event.Text,
+		Translated:       translated,
+		Model:            b.servedModel(usage),
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+	})
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error writing translation history: %v", err)
+	}
+}
+
+// historyCommand lists the last translations in the current channel
+func (b *Bot) historyCommand(ctx context.Context, req command.Request) string {
+	n := historyDefault
+	if len(req.Args) > 0 {
+		parsed, err := strconv.Atoi(req.Args[0])
+		if err != nil || parsed < 1 {
+			return fmt.Sprintf("🤔 `%s` isn't a number of translations. Try `%s history %d`.", req.Args[0], req.Prefix, historyDefault)
+		}
+		n = min(parsed, historyMax)
+	}
+
+	translations, err := b.history.Recent(ctx, req.ChannelID, n)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error reading translation history: %v", err)
+		return "⚠️ Couldn't read the translation history, please try again."
+	}
+	if len(translations) == 0 {
+		return fmt.Sprintf("📭 No translations recorded in <#%s> yet.", req.ChannelID)
+	}
+
+	lines := []string{fmt.Sprintf("*Last %d translations in <#%s>*", len(translations), req.ChannelID)}
+	for _, t := range translations {
+		lines = append(lines, "",
+			fmt.Sprintf("<@%s> · <!date^%d^{date_short_pretty} {time}|%s>", t.User, t.Time.Unix(), t.Time.UTC().Format(time.RFC1123)),
+			"> "+oneLine(t.Original),
+			"🗣️ "+oneLine(t.Translated))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// oneLine shortens a message to fit on one line, for listing it
+func oneLine(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= oneLineLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:oneLineLength-1])) + "…"
+}
+
+// leaderboardCommand ranks the users translated most in the current channel
+// this week
+func (b *Bot) leaderboardCommand(ctx context.Context, req command.Request) string {
+	counts, err := b.history.Leaderboard(ctx, req.ChannelID, b.weekStart(time.Now()), leaderboardSize)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error reading leaderboard: %v", err)
+		return "⚠️ Couldn't read the leaderboard, please try again."
+	}
+	if len(counts) == 0 {
+		return fmt.Sprintf("📭 Nobody in <#%s> has been translated this week yet.", req.ChannelID)
+	}
+
+	lines := []string{fmt.Sprintf("*Most translated in <#%s> this week*", req.ChannelID)}
+	for i, c := range counts {
+		lines = append(lines, fmt.Sprintf("%d. <@%s> — %d", i+1, c.User, c.Count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// weekStart returns the start of the week containing now: Monday at
+// midnight in the bot's TIMEZONE
+func (b *Bot) weekStart(now time.Time) time.Time {
+	if b.schedule.Location != nil {
+		now = now.In(b.schedule.Location)
+	}
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	year, month, day := now.Date()
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/slackfmt"
+)
+
+// resolveMarkup replaces Slack markup in text with what readers see: names
+// instead of user and channel IDs, link labels instead of link syntax. The
+// model handles the plain text far better, and can't echo a mention back
+// that pings someone.
+func (b *Bot) resolveMarkup(ctx context.Context, text string) string {
+	return slackfmt.Resolve(text, slackfmt.Resolver{
+		User: func(id string) string {
+			user, err := b.slack.GetUserInfo(ctx, id)
+			if err != nil {
+				b.loggerFor(ctx).Debugf("Leaving mention of %s unresolved: %v", id, err)
+				return ""
+			}
+			return getDisplayName(user)
+		},
+		Channel: func(id string) string {
+			channel, err := b.slack.ChannelInfo(ctx, id)
+			if err != nil {
+				b.loggerFor(ctx).Debugf("Leaving mention of %s unresolved: %v", id, err)
+				return ""
+			}
+			return channel.Name
+		},
+	})
+}
+
+// resolvedEvent returns a copy of event with the markup in its text and
+// shared messages resolved
+func (b *Bot) resolvedEvent(ctx context.Context, event *slackClient.IncomingMessage) *slackClient.IncomingMessage {
+	resolved := *event
+	resolved.Text = b.resolveMarkup(ctx, event.Text)
+
+	if len(event.Attachments) > 0 {
+		resolved.Attachments = make([]slack.Attachment, len(event.Attachments))
+		copy(resolved.Attachments, event.Attachments)
+		for i := range resolved.Attachments {
+			resolved.Attachments[i].Text = b.resolveMarkup(ctx, resolved.Attachments[i].Text)
+		}
+	}
+	return &resolved
+}
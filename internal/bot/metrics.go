@@ -0,0 +1,6 @@
+package bot
+
+import "github.com/user/slack-bot-api/internal/metrics"
+
+var concurrencyLimit = metrics.NewGauge("slackbot_translation_concurrency_limit",
+	"Current limit on concurrent translation requests, adapted to LLM latency")
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/user/slack-bot-api/config"
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// moderationNoticeText replaces a flagged translation with
+// MODERATION_ACTION=notice
+const moderationNoticeText = "translation withheld 🙈"
+
+// moderate checks a translation before it's posted. It returns the text to
+// post, the translation itself or the notice replacing it, and false when
+// nothing should be posted at all. The translation is posted when the
+// moderation endpoint fails, an outage there shouldn't stop the bot.
+func (b *Bot) moderate(ctx context.Context, event *slackClient.IncomingMessage, text string) (string, bool) {
+	if b.moderator == nil {
+		return text, true
+	}
+
+	scores, err := b.moderator.Moderate(ctx, text)
+	if err != nil {
+		b.loggerFor(ctx).Warnf("⚠️ Couldn't moderate the translation of %s in %s, posting it anyway: %v", event.Timestamp, event.Channel, err)
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "moderation", true, "check failed")
+		return text, true
+	}
+
+	flagged := flaggedCategories(scores, b.moderationThreshold)
+	if len(flagged) == 0 {
+		b.slack.Decisions().Step(event.Channel, event.Timestamp, "moderation", true, "")
+		return text, true
+	}
+
+	detail := "flagged for " + strings.Join(flagged, ", ")
+	b.slack.Decisions().Step(event.Channel, event.Timestamp, "moderation", false, detail)
+	if b.moderationAction == config.ModerationActionNotice {
+		b.loggerFor(ctx).Warnf("🙈 Translation of %s in %s %s, posting a notice instead", event.Timestamp, event.Channel, detail)
+		return moderationNoticeText, true
+	}
+	b.loggerFor(ctx).Warnf("🙈 Translation of %s in %s %s, not posting it", event.Timestamp, event.Channel, detail)
+	return "", false
+}
+
+// flaggedCategories returns the categories scoring threshold or more, with
+// their scores, in alphabetical order
+func flaggedCategories(scores map[string]float64, threshold float64) []string {
+	var flagged []string
+	for category, score := range scores {
+		if score >= threshold {
+			flagged = append(flagged, fmt.Sprintf("%s (%.2f)", category, score))
+		}
+	}
+	sort.Strings(flagged)
+	return flagged
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// onboardingCardData holds the values available to the onboarding card
+type onboardingCardData struct {
+	User    string
+	Channel string
+}
+
+// onboard posts the onboarding card under a translation the first time the
+// author is translated in a channel. Direct messages and anonymous channels
+// don't get one, and a card that fails to post is tried again on the
+// author's next translation.
+func (b *Bot) onboard(ctx context.Context, event *slackClient.IncomingMessage, threadTS, replyTS string) {
+	if b.onboardingCard == nil || event.User == "" || strings.HasPrefix(event.Channel, "D") || b.anonymous(event.Channel) {
+		return
+	}
+
+	first, err := b.store.MarkOnboarded(event.User, event.Channel, time.Now())
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error recording onboarding of %s in %s: %v", event.User, event.Channel, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	var card strings.Builder
+	if err := b.onboardingCard.Execute(&card, onboardingCardData{User: "<@" + event.User + ">", Channel: "<#" + event.Channel + ">"}); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error rendering onboarding card: %v", err)
+		return
+	}
+
+	// Threads can't nest, so a translation in a thread gets the card in
+	// the same thread
+	if threadTS == "" {
+		threadTS = replyTS
+	}
+	if _, _, err := b.slack.PostMessage(ctx, event.Channel, card.String(), slack.MsgOptionTS(threadTS)); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error posting onboarding card: %v", err)
+		if err := b.store.UnmarkOnboarded(event.User, event.Channel); err != nil {
+			b.loggerFor(ctx).Errorf("❌ Error resetting onboarding of %s in %s: %v", event.User, event.Channel, err)
+		}
+		return
+	}
+
+	b.loggerFor(ctx).Infof("👋 Posted onboarding card for %s in %s", event.User, event.Channel)
+}
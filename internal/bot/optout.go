@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/command"
+)
+
+// optedOut reports whether a user's messages are left untranslated at
+// their request
+func (b *Bot) optedOut(userID string) bool {
+	return !b.optOutExempt[userID] && b.store.OptedOut(userID)
+}
+
+// optOutCommand stops translating the invoking user's messages
+func (b *Bot) optOutCommand(ctx context.Context, req command.Request) string {
+	if b.optOutExempt[req.UserID] {
+		return "🙅 Nice try, but you can't opt out. Your messages are too iconic."
+	}
+
+	changed, err := b.store.OptOut(req.UserID, time.Now())
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error opting out %s: %v", req.UserID, err)
+		return "⚠️ Couldn't opt you out, please try again."
+	}
+	if !changed {
+		return "👍 You've already opted out. `" + req.Prefix + " optin` turns translations back on."
+	}
+
+	b.loggerFor(ctx).Infof("🚪 %s opted out of translations", req.UserID)
+	return "👋 Done, your messages won't be translated anymore. `" + req.Prefix + " optin` turns them back on."
+}
+
+// optInCommand translates the invoking user's messages again
+func (b *Bot) optInCommand(ctx context.Context, req command.Request) string {
+	changed, err := b.store.OptIn(req.UserID)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error opting in %s: %v", req.UserID, err)
+		return "⚠️ Couldn't opt you back in, please try again."
+	}
+	if !changed {
+		return "👍 Your messages are already being translated."
+	}
+
+	b.loggerFor(ctx).Infof("🚪 %s opted back in to translations", req.UserID)
+	return "✨ Welcome back, your messages will be translated again."
+}
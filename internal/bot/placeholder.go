@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	// placeholderText is posted right away while a translation is underway
+	placeholderText = "✨ translating…"
+
+	// placeholderFailedText replaces the placeholder when translating fails
+	placeholderFailedText = "⚠️ couldn't translate this one, sorry"
+
+	// streamInterval is how often a streamed translation is edited into
+	// its placeholder, well within chat.update's rate limit
+	streamInterval = 1500 * time.Millisecond
+)
+
+// postPlaceholder posts the placeholder a translation is later edited into
+// and returns its timestamp. Failing to post it isn't fatal, the
+// translation is then posted as a new message; an empty timestamp is
+// returned in that case.
+func (b *Bot) postPlaceholder(ctx context.Context, channelID, threadTS string) string {
+	var postOptions []slack.MsgOption
+	if threadTS != "" {
+		postOptions = append(postOptions, slack.MsgOptionTS(threadTS))
+	}
+
+	_, ts, err := b.slack.PostMessage(ctx, channelID, placeholderText, postOptions...)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error posting placeholder, posting the translation when it's ready instead: %v", err)
+		return ""
+	}
+	return ts
+}
+
+// finishPlaceholder edits the translation into the placeholder. If that
+// fails the placeholder is removed and the translation posted as a new
+// message, so it's never lost. It returns the timestamp of the translation.
+func (b *Bot) finishPlaceholder(ctx context.Context, channelID, threadTS, placeholderTS, originalTS, userID, text string) (string, error) {
+	if err := b.slack.UpdateMessage(ctx, channelID, placeholderTS, text, b.replyBlocks(ctx, channelID, originalTS, userID, text)...); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error updating placeholder, posting the translation instead: %v", err)
+		if err := b.slack.DeleteMessage(ctx, channelID, placeholderTS); err != nil {
+			b.loggerFor(ctx).Errorf("❌ Error deleting placeholder: %v", err)
+		}
+		return b.postReply(ctx, channelID, threadTS, originalTS, userID, text)
+	}
+
+	b.recordReply(channelID, originalTS, placeholderTS, userID)
+	return placeholderTS, nil
+}
+
+// failPlaceholder replaces the placeholder with a short error, or deletes
+// it when it can't be edited, so it isn't left dangling. This happens even
+// when the translation failed because MESSAGE_PROCESSING_TIMEOUT passed.
+func (b *Bot) failPlaceholder(ctx context.Context, channelID, placeholderTS string) {
+	ctx = context.WithoutCancel(ctx)
+	if err := b.slack.UpdateMessage(ctx, channelID, placeholderTS, placeholderFailedText); err == nil {
+		return
+	}
+	if err := b.slack.DeleteMessage(ctx, channelID, placeholderTS); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error removing placeholder after failed translation: %v", err)
+	}
+}
+
+// streamInto edits the partial translations streamed with the returned
+// context into the placeholder as they grow, at most every
+// streamInterval. stop ends the edits, waiting for one underway so it
+// can't overwrite the finished translation; it must be called once the
+// translation is done.
+func (b *Bot) streamInto(ctx context.Context, channelID, placeholderTS string) (streamCtx context.Context, stop func()) {
+	partials := make(chan string, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(streamInterval)
+		defer ticker.Stop()
+
+		var latest, shown string
+		for {
+			select {
+			case text, ok := <-partials:
+				if !ok {
+					return
+				}
+				latest = text
+			case <-ticker.C:
+				if latest == shown {
+					continue
+				}
+				if err := b.slack.UpdateMessage(ctx, channelID, placeholderTS, latest+" …"); err != nil {
+					b.loggerFor(ctx).Debugf("Error updating streamed translation: %v", err)
+				}
+				shown = latest
+			}
+		}
+	}()
+
+	return translate.WithPartials(ctx, partials), func() {
+		close(partials)
+		<-done
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// sharedMessages extracts the messages that were shared/forwarded into a
+// message. Slack represents these as attachments carrying the original
+// author and a timestamp, as opposed to link unfurls which have neither.
+func sharedMessages(attachments []slack.Attachment) []translate.QuotedMessage {
+	var quotes []translate.QuotedMessage
+	for _, attachment := range attachments {
+		if attachment.Text == "" || attachment.Ts == "" {
+			continue
+		}
+		if attachment.AuthorName == "" && attachment.AuthorID == "" && attachment.AuthorSubname == "" {
+			continue
+		}
+
+		author := attachment.AuthorSubname
+		if author == "" {
+			author = attachment.AuthorName
+		}
+		if author == "" {
+			author = attachment.AuthorID
+		}
+
+		quotes = append(quotes, translate.QuotedMessage{
+			Author: author,
+			Text:   attachment.Text,
+		})
+	}
+	return quotes
+}
+
+// formatQuotedTranslation appends a translated quote to the reply, visually
+// separated from the commentary as a Slack blockquote
+func formatQuotedTranslation(reply string, quote translate.QuotedMessage, translatedQuote string) string {
+	var b strings.Builder
+	b.WriteString(reply)
+	b.WriteString("\n\n> 🔁 *")
+	b.WriteString(quote.Author)
+	b.WriteString(" (quoted):*")
+	for _, line := range strings.Split(translatedQuote, "\n") {
+		b.WriteString("\n> ")
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/translate"
+	"github.com/user/slack-bot-api/internal/translatetest"
+)
+
+// sharedAttachments loads the attachments of a message sharing two
+// messages, as Slack sends them, along with a link unfurl
+func sharedAttachments(t *testing.T) []slack.Attachment {
+	t.Helper()
+	data, err := os.ReadFile("testdata/shared_messages.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attachments []slack.Attachment
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		t.Fatalf("decoding attachments: %v", err)
+	}
+	return attachments
+}
+
+func TestSharedMessages(t *testing.T) {
+	got := sharedMessages(sharedAttachments(t))
+	want := []translate.QuotedMessage{
+		{Author: "bob", Text: "the deploy script is cursed, do not touch"},
+		{Author: "Deploy Bot", Text: "deploy of billing failed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sharedMessages = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatQuotedTranslation(t *testing.T) {
+	got := formatQuotedTranslation("bestie said no cap",
+		translate.QuotedMessage{Author: "bob", Text: "the deploy script is cursed\ndo not touch"},
+		"deploy script is lowkey haunted\nhands off fr")
+	want := "bestie said no cap\n\n" +
+		"> 🔁 *bob (quoted):*\n" +
+		"> deploy script is lowkey haunted\n" +
+		"> hands off fr"
+	if got != want {
+		t.Errorf("formatQuotedTranslation =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQuotedMessagesInReply(t *testing.T) {
+	tests := []struct {
+		mode string
+		// want is the reply after the commentary's translation
+		want string
+	}{
+		{mode: "reference", want: ""},
+		{mode: "both", want: "\n\n> 🔁 *bob (quoted):*\n> " + translatetest.Translation("the deploy script is cursed, do not touch") +
+			"\n\n> 🔁 *Deploy Bot (quoted):*\n> " + translatetest.Translation("deploy of billing failed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			b, server, translator := newTestBot(t, map[string]string{"QUOTE_MODE": tt.mode, "PLAIN_TEXT_REPLIES": "true"})
+			message := testMessage(server, "look what bob said about the deploy")
+			message.Attachments = sharedAttachments(t)
+			if err := b.process(context.Background(), message, testAuthor()); err != nil {
+				t.Fatalf("process: %v", err)
+			}
+
+			requests := translator.Requests()
+			if len(requests) == 0 || len(requests[0].Quotes) != 2 {
+				t.Fatalf("translation requests %+v, want the message with both quotes as context", requests)
+			}
+			posts := postsOf(server)
+			if len(posts) != 1 {
+				t.Fatalf("%d posts, want 1", len(posts))
+			}
+			commentary := translatetest.Translation(message.Text)
+			_, after, ok := strings.Cut(posts[0].Text, commentary)
+			if !ok {
+				t.Fatalf("reply %q doesn't contain the translation %q", posts[0].Text, commentary)
+			}
+			if !strings.HasPrefix(after, tt.want) || (tt.want == "" && strings.Contains(after, "(quoted)")) {
+				t.Errorf("reply after the translation = %q, want %q", after, tt.want)
+			}
+		})
+	}
+}
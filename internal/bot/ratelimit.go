@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket allows a burst of up to capacity events, refilled evenly over
+// an hour
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (tb *tokenBucket) refill(capacity int, now time.Time) {
+	elapsed := now.Sub(tb.updated)
+	tb.tokens += elapsed.Hours() * float64(capacity)
+	if tb.tokens > float64(capacity) {
+		tb.tokens = float64(capacity)
+	}
+	tb.updated = now
+}
+
+// translationRateLimit caps translations per user and across all users, per
+// hour. A limit of 0 is no limit. It is safe for concurrent use.
+type translationRateLimit struct {
+	perUser int
+	global  int
+
+	mu    sync.Mutex
+	users map[string]*tokenBucket
+	all   tokenBucket
+}
+
+func newTranslationRateLimit(perUser, global int, now time.Time) *translationRateLimit {
+	return &translationRateLimit{
+		perUser: perUser,
+		global:  global,
+		users:   make(map[string]*tokenBucket),
+		all:     tokenBucket{tokens: float64(global), updated: now},
+	}
+}
+
+// allow takes a token for a translation of userID's message. It returns
+// false, taking nothing, with the limit that was hit when either bucket is
+// empty.
+func (rl *translationRateLimit) allow(userID string, now time.Time) (bool, string) {
+	if rl.perUser <= 0 && rl.global <= 0 {
+		return true, ""
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	var user *tokenBucket
+	if rl.perUser > 0 {
+		user = rl.users[userID]
+		if user == nil {
+			user = &tokenBucket{tokens: float64(rl.perUser), updated: now}
+			rl.users[userID] = user
+		}
+		user.refill(rl.perUser, now)
+		if user.tokens < 1 {
+			return false, "MAX_TRANSLATIONS_PER_USER_PER_HOUR"
+		}
+	}
+	if rl.global > 0 {
+		rl.all.refill(rl.global, now)
+		if rl.all.tokens < 1 {
+			return false, "MAX_TRANSLATIONS_PER_HOUR"
+		}
+		rl.all.tokens--
+	}
+	if user != nil {
+		user.tokens--
+	}
+	return true, ""
+}
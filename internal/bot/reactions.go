@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"context"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// Reactions added to the original message to show translation progress
+const (
+	reactionWorking = "hourglass_flowing_sand"
+	reactionDone    = "white_check_mark"
+	reactionFailed  = "x"
+)
+
+// react adds a reaction to the original message. Failures, such as a
+// missing reactions:write scope, are only logged so they never hold up the
+// translation.
+func (b *Bot) react(ctx context.Context, event *slackClient.IncomingMessage, name string) {
+	if err := b.slack.AddReaction(ctx, event.Channel, event.Timestamp, name); err != nil {
+		b.loggerFor(ctx).Warnf("⚠️ Error adding :%s: reaction: %v", name, err)
+	}
+}
+
+// finishProgress swaps the ⏳ reaction for ✅ once the translation is
+// posted, or ❌ when it failed. Translations held for approval just lose
+// the ⏳.
+func (b *Bot) finishProgress(ctx context.Context, event *slackClient.IncomingMessage, posted bool, err error) {
+	if err := b.slack.RemoveReaction(ctx, event.Channel, event.Timestamp, reactionWorking); err != nil {
+		b.loggerFor(ctx).Warnf("⚠️ Error removing :%s: reaction: %v", reactionWorking, err)
+	}
+
+	switch {
+	case err != nil:
+		b.react(ctx, event, reactionFailed)
+	case posted:
+		b.react(ctx, event, reactionDone)
+	}
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const removeActionID = "remove_translation"
+
+// handleRemoveAction deletes the translation the Remove button was clicked
+// on
+func (b *Bot) handleRemoveAction(ctx context.Context, callback slack.InteractionCallback, action *slack.BlockAction) {
+	b.removeTranslation(ctx, callback.Channel.ID, callback.Message.Timestamp, callback.User.ID, "button")
+}
+
+// handleRemoveReaction deletes a translation the remove reaction was added
+// to. The reaction on any other message is ignored.
+func (b *Bot) handleRemoveReaction(ctx context.Context, reaction *slackevents.ReactionAddedEvent) {
+	botUserID, err := b.slack.BotUserID(ctx)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error handling remove reaction: %v", err)
+		return
+	}
+	if reaction.ItemUser != botUserID {
+		return
+	}
+
+	b.removeTranslation(ctx, reaction.Item.Channel, reaction.Item.Timestamp, reaction.User, "reaction")
+}
+
+// removeTranslation deletes one of the bot's messages at the request of a
+// user. Only the author of the translated message and admins may remove a
+// translation; anyone else is told so privately. A message the state store
+// doesn't know can only be removed by an admin.
+func (b *Bot) removeTranslation(ctx context.Context, channelID, replyTS, userID, via string) {
+	reply, known := b.store.Reply(channelID, replyTS)
+	if !b.slack.IsAdmin(userID) && (!known || reply.User != userID) {
+		b.loggerFor(ctx).Infof("🙅 %s may not remove %s in %s", userID, replyTS, channelID)
+		refusal := "🙅 Only an admin can remove this message."
+		if known {
+			refusal = "🙅 Only <@" + reply.User + "> or an admin can remove this translation."
+		}
+		b.replyToRemoval(ctx, channelID, userID, refusal)
+		return
+	}
+
+	if err := b.slack.DeleteMessage(ctx, channelID, replyTS); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error removing translation %s in %s: %v", replyTS, channelID, err)
+		b.replyToRemoval(ctx, channelID, userID, "⚠️ Couldn't remove the translation, please try again.")
+		return
+	}
+	if err := b.store.DeleteReply(channelID, replyTS); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error forgetting removed translation: %v", err)
+	}
+
+	b.loggerFor(ctx).Infof("🗑️ Translation %s in %s removed by %s with the %s", replyTS, channelID, userID, via)
+}
+
+// replyToRemoval tells the user who asked for a removal why nothing
+// happened
+func (b *Bot) replyToRemoval(ctx context.Context, channelID, userID, text string) {
+	if err := b.slack.PostEphemeral(ctx, channelID, userID, text); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error replying to removal: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// replyData holds the values available to the reply template
+type replyData struct {
+	DisplayName string
+	Translation string
+	Original    string
+	Channel     string
+}
+
+// renderReply lays out a translation with the reply template. A template
+// that fails to render is logged and the bare translation posted instead.
+func (b *Bot) renderReply(ctx context.Context, event *slackClient.IncomingMessage, displayName, translation string) string {
+	var reply strings.Builder
+	err := b.replyTemplate.Execute(&reply, replyData{
+		DisplayName: displayName,
+		Translation: translation,
+		Original:    event.Text,
+		Channel:     "<#" + event.Channel + ">",
+	})
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error rendering reply template, posting the bare translation: %v", err)
+		return translation
+	}
+	return reply.String()
+}
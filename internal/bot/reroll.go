@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	rerollActionID = "reroll_translation"
+
+	// maxRerollable bounds how many translations are remembered for
+	// re-rolling; the oldest are forgotten first
+	maxRerollable = 1000
+)
+
+// rerollable is a posted translation and what's needed to translate its
+// message again the same way
+type rerollable struct {
+	event            *slackClient.IncomingMessage
+	displayName      string
+	style            string
+	translationStyle translate.Style
+	accessible       bool
+	// Replies from the other translations the model offered, used by the
+	// next re-rolls before asking it again
+	candidates []string
+
+	// Users who re-rolled it, in order; one entry per re-roll
+	rerolledBy []string
+	// A re-roll is in flight
+	busy bool
+}
+
+// translationRerolls remembers recent translations so the re-roll button
+// under them can translate the message again, up to max times. A max of 0
+// turns the button off. It is safe for concurrent use.
+type translationRerolls struct {
+	max int
+
+	mu       sync.Mutex
+	messages map[string]*rerollable
+	order    []string
+}
+
+func newTranslationRerolls(max int) *translationRerolls {
+	return &translationRerolls{max: max, messages: make(map[string]*rerollable)}
+}
+
+func rerollKey(channelID, originalTS string) string {
+	return channelID + "/" + originalTS
+}
+
+// remember makes the translation of a message re-rollable. A message that
+// was translated before, e.g. after an edit, keeps its re-roll count.
+func (r *translationRerolls) remember(channelID, originalTS string, message rerollable) {
+	if r.max == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rerollKey(channelID, originalTS)
+	if previous, ok := r.messages[key]; ok {
+		message.rerolledBy, message.busy = previous.rerolledBy, previous.busy
+		r.messages[key] = &message
+		return
+	}
+
+	r.messages[key] = &message
+	r.order = append(r.order, key)
+	if len(r.order) > maxRerollable {
+		delete(r.messages, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// state returns who re-rolled a translation and whether it can be
+// re-rolled again. Translations that can't be re-rolled at all report ok
+// false.
+func (r *translationRerolls) state(channelID, originalTS string) (rerolledBy []string, left bool, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[rerollKey(channelID, originalTS)]
+	if !ok {
+		return nil, false, false
+	}
+	return append([]string(nil), message.rerolledBy...), len(message.rerolledBy) < r.max, true
+}
+
+// start claims a re-roll of a translation, returning a copy of it, or the
+// reason it can't be re-rolled. Every successful start must be followed by
+// finish.
+func (r *translationRerolls) start(channelID, originalTS string) (rerollable, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[rerollKey(channelID, originalTS)]
+	switch {
+	case !ok:
+		return rerollable{}, "⌛ This translation is too old to re-roll."
+	case message.busy:
+		return rerollable{}, "🔁 Already re-rolling, hang on."
+	case len(message.rerolledBy) >= r.max:
+		return rerollable{}, "🙅 No re-rolls left for this one."
+	}
+	message.busy = true
+	return *message, ""
+}
+
+// takeCandidate removes and returns the next unused candidate of a
+// translation being re-rolled
+func (r *translationRerolls) takeCandidate(channelID, originalTS string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[rerollKey(channelID, originalTS)]
+	if !ok || len(message.candidates) == 0 {
+		return "", false
+	}
+	candidate := message.candidates[0]
+	message.candidates = message.candidates[1:]
+	return candidate, true
+}
+
+// setCandidates replaces the unused candidates of a translation after a
+// re-roll asked the model again
+func (r *translationRerolls) setCandidates(channelID, originalTS string, candidates []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message, ok := r.messages[rerollKey(channelID, originalTS)]; ok {
+		message.candidates = candidates
+	}
+}
+
+// finish releases a translation after a re-roll, counting it for userID
+// when it produced a new translation
+func (r *translationRerolls) finish(channelID, originalTS, userID string, rerolled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[rerollKey(channelID, originalTS)]
+	if !ok {
+		return
+	}
+	message.busy = false
+	if rerolled {
+		message.rerolledBy = append(message.rerolledBy, userID)
+	}
+}
+
+// handleRerollAction translates a message again and replaces the
+// translation the button was clicked on
+func (b *Bot) handleRerollAction(ctx context.Context, callback slack.InteractionCallback, action *slack.BlockAction) {
+	channelID := callback.Channel.ID
+	originalTS := action.Value
+	replyTS := callback.Message.Timestamp
+	userID := callback.User.ID
+
+	message, refusal := b.rerolls.start(channelID, originalTS)
+	if refusal != "" {
+		b.replyToReroll(ctx, channelID, userID, refusal)
+		return
+	}
+
+	// A candidate the model offered last time costs nothing; otherwise a
+	// re-roll is a new translation, never the cached one
+	ctx, usage := translate.WithUsage(translate.WithoutCache(ctx))
+	start := time.Now()
+	text, err := b.rerollText(ctx, channelID, originalTS, message)
+	if errors.Is(err, errBudgetExhausted) {
+		b.rerolls.finish(channelID, originalTS, userID, false)
+		b.replyToReroll(ctx, channelID, userID, "💸 The daily translation budget is used up, try again tomorrow.")
+		return
+	}
+	if err == nil && message.accessible {
+		text = accessibleText(text)
+	}
+	b.rerolls.finish(channelID, originalTS, userID, err == nil)
+	b.recordUsage(ctx, usage)
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error re-rolling translation of %s in %s: %v", originalTS, channelID, err)
+		b.replyToReroll(ctx, channelID, userID, "⚠️ Couldn't re-roll the translation, please try again.")
+		return
+	}
+	text, ok := b.moderate(ctx, message.event, text)
+	if !ok {
+		b.replyToReroll(ctx, channelID, userID, "🙈 The new translation was withheld, the current one stays.")
+		return
+	}
+	b.recordAudit(ctx, message.event, text, usage, time.Since(start))
+	text = b.renderReply(ctx, message.event, message.displayName, text)
+
+	if err := b.slack.UpdateMessage(ctx, channelID, replyTS, text, b.replyBlocks(ctx, channelID, originalTS, message.event.User, text)...); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error updating re-rolled translation: %v", err)
+		b.replyToReroll(ctx, channelID, userID, fmt.Sprintf("⚠️ Couldn't update the translation: %v", err))
+		return
+	}
+	b.loggerFor(ctx).Infof("🔁 Translation of %s in %s re-rolled by %s", originalTS, channelID, userID)
+}
+
+// errBudgetExhausted refuses a re-roll that needs the model once the day's
+// budget is used up
+var errBudgetExhausted = errors.New("daily budget exhausted")
+
+// rerollText returns the next reply for a re-roll: an unused candidate, or
+// a new translation whose other candidates replace the unused ones
+func (b *Bot) rerollText(ctx context.Context, channelID, originalTS string, message rerollable) (string, error) {
+	if candidate, ok := b.rerolls.takeCandidate(channelID, originalTS); ok {
+		b.loggerFor(ctx).Debugf("Re-rolling %s in %s with a candidate from the last translation", originalTS, channelID)
+		return candidate, nil
+	}
+	if b.budget.exhausted(time.Now()) {
+		return "", errBudgetExhausted
+	}
+
+	text, candidates, err := b.buildReply(ctx, message.event, message.displayName, message.style, message.translationStyle)
+	if err != nil {
+		return "", err
+	}
+	b.rerolls.setCandidates(channelID, originalTS, candidates)
+	return text, nil
+}
+
+// replyToReroll tells the user who clicked the button why nothing happened
+func (b *Bot) replyToReroll(ctx context.Context, channelID, userID, text string) {
+	if err := b.slack.PostEphemeral(ctx, channelID, userID, text); err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error replying to re-roll: %v", err)
+	}
+}
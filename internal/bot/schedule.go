@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+)
+
+// scheduleActive reports whether automatic translations run at t, within
+// the configured active hours and days
+func scheduleActive(schedule config.Schedule, t time.Time) bool {
+	if schedule.Location != nil {
+		t = t.In(schedule.Location)
+	}
+
+	day := t.Weekday()
+	if schedule.Hours {
+		year, month, date := t.Date()
+		offset := t.Sub(time.Date(year, month, date, 0, 0, 0, 0, t.Location()))
+		start, end := schedule.HoursStart, schedule.HoursEnd
+
+		switch {
+		case start < end:
+			if offset < start || offset >= end {
+				return false
+			}
+		case offset >= start:
+			// Evening part of a window crossing midnight
+		case offset < end:
+			// Early morning part, which belongs to the day before
+			day = (day + 6) % 7
+		default:
+			return false
+		}
+	}
+
+	return schedule.Days == nil || schedule.Days[day]
+}
@@ -0,0 +1,275 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/command"
+	"github.com/user/slack-bot-api/internal/translate"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+// statsTopUsers is how many users the stats command lists
+const statsTopUsers = 5
+
+// translationStats counts translations since startup, by user, channel and
+// output style, for the stats command and /status. It is safe for
+// concurrent use.
+type translationStats struct {
+	started time.Time
+
+	mu           sync.Mutex
+	total        uint64
+	users        map[string]uint64
+	channels     map[string]uint64
+	languages    map[string]uint64
+	styles       map[string]uint64
+	openAIErrors uint64
+
+	// Tokens of every LLM request since startup, and what they cost
+	promptTokens     uint64
+	completionTokens uint64
+	prices           tokenPrices
+
+	// Translations since the last daily digest
+	digest digestTally
+}
+
+// tokenPrices are the prices per 1K prompt and completion tokens the spend
+// is estimated with. A nil price counts as free; without either there is
+// no estimate.
+type tokenPrices struct {
+	prompt     *float64
+	completion *float64
+}
+
+// cost estimates what the tokens cost, reporting false without prices
+func (p tokenPrices) cost(promptTokens, completionTokens uint64) (float64, bool) {
+	if p.prompt == nil && p.completion == nil {
+		return 0, false
+	}
+
+	var cost float64
+	if p.prompt != nil {
+		cost += float64(promptTokens) / 1000 * *p.prompt
+	}
+	if p.completion != nil {
+		cost += float64(completionTokens) / 1000 * *p.completion
+	}
+	return cost, true
+}
+
+func newTranslationStats(now time.Time, prices tokenPrices) *translationStats {
+	return &translationStats{
+		started:   now,
+		prices:    prices,
+		users:     make(map[string]uint64),
+		channels:  make(map[string]uint64),
+		languages: make(map[string]uint64),
+		styles:    make(map[string]uint64),
+		digest:    newDigestTally(),
+	}
+}
+
+// recordTranslation counts a posted translation of userID's message, in
+// OUTPUT_STYLE style
+func (s *translationStats) recordTranslation(channelID, userID, style, translated string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total++
+	s.users[userID]++
+	s.channels[channelID]++
+	s.styles[style]++
+	s.digest.add(channelID, userID, translated)
+}
+
+// recordLanguage counts a translated message detected to be in language
+func (s *translationStats) recordLanguage(language string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.languages[language]++
+}
+
+// takeDigest returns the translations since the last digest and starts
+// counting anew
+func (s *translationStats) takeDigest() digestTally {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tally := s.digest
+	s.digest = newDigestTally()
+	return tally
+}
+
+// recordOpenAIError counts a failed OpenAI request
+func (s *translationStats) recordOpenAIError() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.openAIErrors++
+}
+
+// recordUsage counts the tokens of LLM requests
+func (s *translationStats) recordUsage(promptTokens, completionTokens int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.promptTokens += uint64(promptTokens)
+	s.completionTokens += uint64(completionTokens)
+}
+
+// snapshot returns the counts, users and channels by most translations
+func (s *translationStats) snapshot(now time.Time) v1.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := v1.Stats{
+		SchemaVersion:    v1.SchemaVersion,
+		StartedAt:        s.started,
+		UptimeSeconds:    int64(now.Sub(s.started) / time.Second),
+		Translations:     s.total,
+		OpenAIErrors:     s.openAIErrors,
+		PromptTokens:     s.promptTokens,
+		CompletionTokens: s.completionTokens,
+		Users:            sortedCounts(s.users),
+		Channels:         sortedCounts(s.channels),
+		Languages:        sortedCounts(s.languages),
+		OutputStyles:     sortedCounts(s.styles),
+	}
+	if cost, ok := s.prices.cost(s.promptTokens, s.completionTokens); ok {
+		stats.EstimatedCost = &cost
+	}
+	return stats
+}
+
+// sortedCounts lists counts by ID, highest first, ties by ID
+func sortedCounts(counts map[string]uint64) []v1.Count {
+	sorted := make([]v1.Count, 0, len(counts))
+	for id, n := range counts {
+		sorted = append(sorted, v1.Count{ID: id, Count: n})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// recordUsage counts the tokens a reply took into the totals since startup
+// and the daily budget, logging them when LOGS is on
+func (b *Bot) recordUsage(ctx context.Context, usage *translate.Usage) {
+	prompt, completion := usage.Tokens()
+	b.stats.recordUsage(prompt, completion)
+
+	exhausted, err := b.budget.add(prompt, completion, time.Now())
+	if err != nil {
+		b.loggerFor(ctx).Errorf("❌ Error saving daily budget usage: %v", err)
+	}
+	if exhausted {
+		b.postBudgetNotice(ctx)
+	}
+
+	if b.logs {
+		line := fmt.Sprintf("🪙 Used %d prompt and %d completion tokens", prompt, completion)
+		if cost, ok := b.stats.prices.cost(uint64(prompt), uint64(completion)); ok {
+			line += fmt.Sprintf(", about $%.4f", cost)
+		}
+		b.loggerFor(ctx).Infof("%s", line)
+	}
+}
+
+// Stats returns the translation counts since startup and the state of the
+// connection and filters, served at /status
+func (b *Bot) Stats() v1.Stats {
+	now := time.Now()
+	stats := b.stats.snapshot(now)
+	stats.Panics = b.slack.Panics()
+	stats.Connection = v1.Connection{
+		Connected:    b.slack.Connected(),
+		Failures:     b.slack.ConnectionFailures(),
+		StartupPhase: b.slack.Phase(),
+	}
+	if t := b.slack.LastConnectedAt(); !t.IsZero() {
+		stats.Connection.LastConnectedAt = &t
+	}
+	if channels := len(b.slack.MonitoredChannels()); channels > 0 {
+		stats.MonitoredChannels = &channels
+	}
+	if users, ok := b.slack.TargetUsers(); ok {
+		stats.TargetUsers = &users
+	}
+	stats.BudgetExhausted = b.budget.exhausted(now)
+	stats.TranslationConcurrencyLimit = b.limiter.Limit()
+	return stats
+}
+
+// statsCommand summarizes the translations since startup
+func (b *Bot) statsCommand(ctx context.Context, req command.Request) string {
+	return formatStats(b.Stats(), func(channelID string) string { return b.channelMention(ctx, channelID) })
+}
+
+// channelMention links a channel in a message. The link is labeled with
+// the channel's name for readers whose client can't resolve it, like those
+// outside a private channel.
+func (b *Bot) channelMention(ctx context.Context, channelID string) string {
+	return fmt.Sprintf("<#%s|%s>", channelID, b.slack.ChannelName(ctx, channelID))
+}
+
+// formatStats renders stats as a Slack message with the top users
+func formatStats(stats v1.Stats, channelMention func(channelID string) string) string {
+	var lines []string
+	lines = append(lines, "*Translation stats*")
+	lines = append(lines, fmt.Sprintf("• %d translations in %s since startup", stats.Translations,
+		(time.Duration(stats.UptimeSeconds)*time.Second).String()))
+	lines = append(lines, fmt.Sprintf("• %d OpenAI errors", stats.OpenAIErrors))
+	if stats.Panics > 0 {
+		lines = append(lines, fmt.Sprintf("• %d events recovered from a panic, check the logs", stats.Panics))
+	}
+	tokens := fmt.Sprintf("• %d prompt and %d completion tokens", stats.PromptTokens, stats.CompletionTokens)
+	if stats.EstimatedCost != nil {
+		tokens += fmt.Sprintf(", about $%.2f", *stats.EstimatedCost)
+	}
+	lines = append(lines, tokens)
+
+	if len(stats.Users) > 0 {
+		lines = append(lines, "", "*Most translated*")
+		for i, user := range stats.Users {
+			if i == statsTopUsers {
+				lines = append(lines, fmt.Sprintf("_…and %d more_", len(stats.Users)-statsTopUsers))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("%d. <@%s> — %d", i+1, user.ID, user.Count))
+		}
+	}
+
+	if len(stats.Channels) > 0 {
+		lines = append(lines, "", "*By channel*")
+		for _, channel := range stats.Channels {
+			lines = append(lines, fmt.Sprintf("• %s — %d", channelMention(channel.ID), channel.Count))
+		}
+	}
+
+	if len(stats.OutputStyles) > 0 {
+		lines = append(lines, "", "*By output style*")
+		for _, style := range stats.OutputStyles {
+			lines = append(lines, fmt.Sprintf("• %s — %d", style.ID, style.Count))
+		}
+	}
+
+	if len(stats.Languages) > 0 {
+		lines = append(lines, "", "*By language*")
+		for _, language := range stats.Languages {
+			lines = append(lines, fmt.Sprintf("• %s — %d", translate.LanguageName(language.ID), language.Count))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
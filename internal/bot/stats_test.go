@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/slack-bot-api/internal/httpserver"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+// Translations are counted by the output style they were posted in
+func TestStatsByOutputStyle(t *testing.T) {
+	b, server, _ := newTestBot(t, map[string]string{
+		"OUTPUT_STYLE":          "vibecheck",
+		"CHANNEL_OUTPUT_STYLES": "C0000002:both,C0000003:translation",
+	})
+
+	for _, channel := range []string{testChannel, testChannel, "C0000002", "C0000003"} {
+		message := testMessage(server, "we shipped it on a friday "+channel)
+		message.Channel = channel
+		if err := b.process(context.Background(), message, testAuthor()); err != nil {
+			t.Fatalf("process in %s: %v", channel, err)
+		}
+	}
+
+	stats := b.Stats()
+	want := []v1.Count{{ID: "vibecheck", Count: 2}, {ID: "both", Count: 1}, {ID: "translation", Count: 1}}
+	if !reflect.DeepEqual(stats.OutputStyles, want) {
+		t.Errorf("OutputStyles = %+v, want %+v", stats.OutputStyles, want)
+	}
+	if text := formatStats(stats, func(id string) string { return id }); !strings.Contains(text, "*By output style*\n• vibecheck — 2\n• both — 1\n• translation — 1") {
+		t.Errorf("stats command doesn't list the output styles:\n%s", text)
+	}
+}
+
+// /status is served without authentication, so it must never carry the
+// tokens and keys the bot runs with
+func TestStatusHasNoSecrets(t *testing.T) {
+	b, _, _ := newTestBot(t, map[string]string{"ANTHROPIC_API_KEY": "sk-ant-test", "OPENAI_ORG_ID": "org-test"})
+	server := httptest.NewServer(httpserver.New(":0", b, b, testLogger()).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stats v1.Stats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("decoding /status: %v", err)
+	}
+	for _, secret := range []string{"xoxb-test", "xapp-test", "sk-test", "sk-ant-test", "org-test"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("/status contains %q", secret)
+		}
+	}
+}
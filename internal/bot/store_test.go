@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/store"
+)
+
+func TestRecoverStoreAfterOutage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	state, err := store.Open(filepath.Join(dir, "state.json"), testLogger())
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	b := &Bot{store: state, logger: testLogger()}
+
+	// Writes fail until the store degrades
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; !state.Degraded(); i++ {
+		if i == 10 {
+			t.Fatalf("store not degraded after %d failed writes", i)
+		}
+		b.recordReply("C0000001", "1.000001", "2.000001", "U0000001")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.recoverStore(ctx, 5*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Still degraded while the disk is gone
+	time.Sleep(50 * time.Millisecond)
+	if !state.Degraded() {
+		t.Fatalf("store recovered while writes still fail")
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for state.Degraded() {
+		if time.Now().After(deadline) {
+			t.Fatalf("recoverStore didn't bring the store back once writes succeed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	reopened, err := store.Open(state.Path(), testLogger())
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	if !reopened.RepliedTo("C0000001", "1.000001") {
+		t.Errorf("reply recorded during the outage wasn't written after recovery")
+	}
+}
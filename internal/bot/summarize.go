@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+const (
+	// summarySystemPrompt asks for the gist of a message too long to
+	// translate whole
+	summarySystemPrompt = "You summarize Slack messages for busy coworkers. Reply with a summary of the message in 2 to 3 plain sentences, " +
+		"in the message's own language, keeping names, numbers and decisions but leaving out code, greetings and sign-offs. " +
+		"Reply with the summary only."
+
+	// summaryMaxTokens caps the summary, plenty for three sentences
+	summaryMaxTokens = 200
+
+	// summaryLabel introduces the translation of a summary, so nobody
+	// takes it for the whole message
+	summaryLabel = "_tl;dr in Gen Alpha:_"
+)
+
+// summarizeLong summarizes a message longer than SUMMARIZE_MESSAGE_TOKENS,
+// for the summary to be translated instead. Its tokens count towards the
+// usage of the translation.
+func (b *Bot) summarizeLong(ctx context.Context, text string) (string, error) {
+	var summary string
+	err := b.limited(ctx, func() error {
+		var err error
+		summary, err = b.translator.Complete(ctx, summarySystemPrompt, text,
+			translate.MaxTokens(summaryMaxTokens), translate.Temperature(0.3))
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error summarizing message: %w", err)
+	}
+	return strings.TrimSpace(summary), nil
+}
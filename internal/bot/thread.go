@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+	"github.com/user/slack-bot-api/internal/threadctx"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// threadContextLimit bounds how many earlier thread messages are looked at
+// when picking context
+const threadContextLimit = 100
+
+// threadContext picks the earlier messages of the thread a reply belongs to
+// as context for translating it, within the configured token budget. The
+// parent is kept whoever posted it, since it sets the topic; other bot
+// messages, including earlier translations, are left out. Failing to fetch
+// the thread only costs the context.
+func (b *Bot) threadContext(ctx context.Context, event *slackClient.IncomingMessage, displayName string) []translate.QuotedMessage {
+	if b.threadContextTokens <= 0 || event.ThreadTimestamp == "" || event.ThreadTimestamp == event.Timestamp {
+		return nil
+	}
+
+	messages, err := b.slack.ThreadMessages(ctx, event.Channel, event.ThreadTimestamp, event.Timestamp, threadContextLimit)
+	if err != nil {
+		b.loggerFor(ctx).Warnf("⚠️ Translating without thread context: %v", err)
+		return nil
+	}
+
+	// Anonymous channels name nobody, not even the thread's other authors
+	anonymous := b.anonymous(event.Channel)
+	var parent threadctx.Message
+	var replies []threadctx.Message
+	for _, m := range messages {
+		isParent := m.Timestamp == event.ThreadTimestamp
+		if !isParent && (m.BotID != "" || m.SubType == "bot_message" || m.Text == "") {
+			continue
+		}
+		var author string
+		if !anonymous {
+			author = b.messageAuthor(ctx, m)
+		}
+		message := threadctx.Message{Author: author, Text: b.resolveMarkup(ctx, m.Text)}
+		if isParent {
+			parent = message
+		} else {
+			replies = append(replies, message)
+		}
+	}
+
+	selected := threadctx.Select(parent, replies, threadctx.Message{Author: displayName, Text: event.Text}, b.threadContextTokens)
+	b.loggerFor(ctx).Debugf("Including %d of %d earlier thread messages as context", len(selected), len(replies)+1)
+
+	quotes := make([]translate.QuotedMessage, 0, len(selected))
+	for _, m := range selected {
+		quotes = append(quotes, translate.QuotedMessage{Author: m.Author, Text: m.Text})
+	}
+	return quotes
+}
+
+// messageAuthor names who posted a thread message: a bot by its name, a
+// person by their display name
+func (b *Bot) messageAuthor(ctx context.Context, m slack.Message) string {
+	if m.User == "" || m.SubType == "bot_message" {
+		if m.BotProfile != nil && m.BotProfile.Name != "" {
+			return m.BotProfile.Name
+		}
+		return m.Username
+	}
+	return b.authorName(ctx, m.User)
+}
+
+// authorName returns the display name of a user, or their ID when they
+// can't be looked up
+func (b *Bot) authorName(ctx context.Context, userID string) string {
+	user, err := b.slack.GetUserInfo(ctx, userID)
+	if err != nil {
+		return userID
+	}
+	return getDisplayName(user)
+}
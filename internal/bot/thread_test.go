@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+// A thread started by a bot still has that post as its parent, rather than
+// the first human reply standing in for it
+func TestThreadContextKeepsBotParent(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{"THREAD_CONTEXT_TOKENS": "1000"})
+	parentTS := server.NextTS()
+	server.Handle("conversations.replies", func(form url.Values) any {
+		return map[string]any{"ok": true, "messages": []any{
+			map[string]any{"type": "message", "ts": parentTS, "bot_id": "B0000002", "subtype": "bot_message",
+				"username": "Deploy Bot", "text": "deploy of the billing service failed"},
+			map[string]any{"type": "message", "ts": server.NextTS(), "thread_ts": parentTS, "user": "U0000002",
+				"text": "looking into the billing deploy now"},
+			map[string]any{"type": "message", "ts": server.NextTS(), "thread_ts": parentTS, "bot_id": "B0000003",
+				"text": "fr fr an earlier translation"},
+		}}
+	})
+
+	event := testMessage(server, "billing is back up after the rollback")
+	event.ThreadTimestamp = parentTS
+	if err := b.process(context.Background(), event, testAuthor()); err != nil {
+		t.Fatalf("processing reply: %v", err)
+	}
+
+	requests := translator.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("%d translations, want 1", len(requests))
+	}
+	thread := requests[0].Thread
+	if len(thread) != 2 {
+		t.Fatalf("thread context = %+v, want the parent and the human reply", thread)
+	}
+	if thread[0].Author != "Deploy Bot" || thread[0].Text != "deploy of the billing service failed" {
+		t.Errorf("thread context starts with %+v, want the bot-posted parent", thread[0])
+	}
+	if thread[1].Text != "looking into the billing deploy now" {
+		t.Errorf("second context message = %+v, want the human reply", thread[1])
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	slackClient "github.com/user/slack-bot-api/internal/slack"
+)
+
+// Watch rules guarantee a translation, so a watched message goes through
+// whatever holds back regular messages
+func TestWatchedMessagesBypassThrottling(t *testing.T) {
+	tomorrow := time.Now().UTC().Add(24 * time.Hour).Weekday().String()
+	tests := []struct {
+		name string
+		env  map[string]string
+		// regular messages sent first, of which the first translated
+		// ones leave the next one held back
+		regular    int
+		translated int
+	}{
+		{name: "quiet hours", env: map[string]string{"ACTIVE_DAYS": tomorrow}, regular: 1, translated: 0},
+		{name: "channel cooldown", env: map[string]string{"CHANNEL_COOLDOWN": "1h"}, regular: 2, translated: 1},
+		{name: "per-user rate limit", env: map[string]string{"MAX_TRANSLATIONS_PER_USER_PER_HOUR": "1"}, regular: 2, translated: 1},
+		{name: "global rate limit", env: map[string]string{"MAX_TRANSLATIONS_PER_HOUR": "1"}, regular: 2, translated: 1},
+		{name: "debounce window", env: map[string]string{"DEBOUNCE_WINDOW": "1h"}, regular: 1, translated: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, server, translator := newTestBot(t, tt.env)
+			defer b.debounce.stop()
+			ctx := context.Background()
+
+			for i := 0; i < tt.regular; i++ {
+				if err := b.process(ctx, testMessage(server, "regular message no cap"), testAuthor()); err != nil {
+					t.Fatalf("processing regular message %d: %v", i+1, err)
+				}
+			}
+			if got := len(postsOf(server)); got != tt.translated {
+				t.Fatalf("%d regular messages translated, want %d", got, tt.translated)
+			}
+
+			watched := testMessage(server, "watched message from the standup bot")
+			watched.Type = slackClient.MessageTypeWatchRule
+			if err := b.process(ctx, watched, testAuthor()); err != nil {
+				t.Fatalf("processing watched message: %v", err)
+			}
+
+			posts := postsOf(server)
+			if len(posts) != tt.translated+1 {
+				t.Fatalf("watched message wasn't translated: %d posts, want %d", len(posts), tt.translated+1)
+			}
+			if last := posts[len(posts)-1]; !strings.Contains(last.Text, "watched message") {
+				t.Errorf("last post %q isn't the watched message's translation", last.Text)
+			}
+			if messages := translator.Messages(); messages[len(messages)-1] != watched.Text {
+				t.Errorf("last translated message = %q, want the watched one", messages[len(messages)-1])
+			}
+		})
+	}
+}
+
+// The daily budget is the one limit watched messages don't get around
+func TestWatchedMessagesRespectBudget(t *testing.T) {
+	b, server, translator := newTestBot(t, map[string]string{"OPENAI_DAILY_TOKEN_BUDGET": "1"})
+	b.budget.add(10, 10, time.Now())
+
+	watched := testMessage(server, "watched message after the budget ran out")
+	watched.Type = slackClient.MessageTypeWatchRule
+	if err := b.process(context.Background(), watched, testAuthor()); err != nil {
+		t.Fatalf("processing watched message: %v", err)
+	}
+	if len(translator.Messages()) != 0 || len(postsOf(server)) != 0 {
+		t.Errorf("watched message translated with the daily budget used up")
+	}
+}
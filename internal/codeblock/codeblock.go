@@ -0,0 +1,150 @@
+// Package codeblock keeps code in a message out of the model's hands: code
+// blocks and inline code are swapped for placeholders before translating
+// and put back verbatim afterwards.
+package codeblock
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Protected is a message with its code replaced by placeholders
+type Protected struct {
+	// Prose is the text to translate, with a placeholder for each piece of
+	// code
+	Prose string
+	// Code holds the code replaced, in order; Code[i] belongs to the
+	// placeholder numbered i+1
+	Code []string
+}
+
+// placeholder returns the placeholder for the code numbered n, from 1
+func placeholder(n int) string {
+	return fmt.Sprintf("[[CODE_%d]]", n)
+}
+
+// placeholderPattern matches placeholders the way the model tends to return
+// them, with case or spacing changed
+var placeholderPattern = regexp.MustCompile(`(?i)\[\[\s*CODE_(\d+)\s*\]\]`)
+
+// Protect replaces the fenced code blocks and inline code spans in text with
+// placeholders. A run of backticks opens code and the next run of the same
+// length closes it; fences of three or more backticks may span lines,
+// inline code may not. Backticks that aren't closed are left as prose.
+func Protect(text string) Protected {
+	var p Protected
+	var prose strings.Builder
+
+	for {
+		start := strings.IndexByte(text, '`')
+		if start < 0 {
+			break
+		}
+		open := backtickRun(text[start:])
+		end, ok := closingRun(text[start+open:], open)
+		if !ok {
+			prose.WriteString(text[:start+open])
+			text = text[start+open:]
+			continue
+		}
+		end += start + open + open
+
+		p.Code = append(p.Code, text[start:end])
+		prose.WriteString(text[:start])
+		prose.WriteString(placeholder(len(p.Code)))
+		text = text[end:]
+	}
+	prose.WriteString(text)
+
+	p.Prose = prose.String()
+	return p
+}
+
+// backtickRun returns the number of backticks s starts with
+func backtickRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	return n
+}
+
+// closingRun finds the run of exactly n backticks closing code opened with
+// n backticks in s, returning its offset. Inline code doesn't span lines.
+func closingRun(s string, n int) (int, bool) {
+	for i := 0; i < len(s); {
+		if s[i] == '\n' && n < 3 {
+			return 0, false
+		}
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := backtickRun(s[i:])
+		if run == n {
+			return i, true
+		}
+		i += run
+	}
+	return 0, false
+}
+
+// HasCode reports whether any code was replaced
+func (p Protected) HasCode() bool {
+	return len(p.Code) > 0
+}
+
+// OnlyCode reports whether the message is nothing but code, leaving no
+// prose to translate
+func (p Protected) OnlyCode() bool {
+	return p.HasCode() && strings.TrimSpace(placeholderPattern.ReplaceAllString(p.Prose, "")) == ""
+}
+
+// Cut returns p with its prose cut to prose, a prefix of it. Code whose
+// placeholder was cut off is dropped rather than appended by Restore.
+func (p Protected) Cut(prose string) Protected {
+	kept := len(placeholderPattern.FindAllString(prose, -1))
+	return Protected{Prose: prose, Code: p.Code[:min(kept, len(p.Code))]}
+}
+
+// Restore puts the code back into the model's output. Placeholders may come
+// back in any order; a placeholder repeated is only filled in once, one
+// the model made up is removed, and code whose placeholder was dropped is
+// appended so it's never lost.
+func (p Protected) Restore(output string) string {
+	output, restored := p.fill(output)
+	for i, code := range p.Code {
+		if !restored[i] {
+			output += "\n" + code
+		}
+	}
+	return output
+}
+
+// RestorePartial puts the code back into the start of the model's output,
+// while the rest is still being received. Code whose placeholder hasn't
+// come yet is left out.
+func (p Protected) RestorePartial(output string) string {
+	output, _ = p.fill(output)
+	return output
+}
+
+// fill replaces the placeholders in output with their code, and reports
+// which code was put back
+func (p Protected) fill(output string) (string, []bool) {
+	restored := make([]bool, len(p.Code))
+	output = placeholderPattern.ReplaceAllStringFunc(output, func(match string) string {
+		n, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(match)[1])
+		if err != nil || n < 1 || n > len(p.Code) {
+			return ""
+		}
+		if restored[n-1] {
+			return ""
+		}
+		restored[n-1] = true
+		return p.Code[n-1]
+	})
+	return output, restored
+}
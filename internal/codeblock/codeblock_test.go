@@ -0,0 +1,135 @@
+package codeblock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtect(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		prose string
+		code  []string
+	}{
+		{
+			name:  "no code",
+			text:  "the deploy is cooked",
+			prose: "the deploy is cooked",
+		},
+		{
+			name:  "inline code",
+			text:  "run `make deploy` again",
+			prose: "run [[CODE_1]] again",
+			code:  []string{"`make deploy`"},
+		},
+		{
+			name:  "multiple blocks",
+			text:  "first ```\ngo build ./...\n``` then `go vet` and ```\ngo test ./...\n```",
+			prose: "first [[CODE_1]] then [[CODE_2]] and [[CODE_3]]",
+			code:  []string{"```\ngo build ./...\n```", "`go vet`", "```\ngo test ./...\n```"},
+		},
+		{
+			name:  "backticks nested in a longer run",
+			text:  "use ``a `tick` inside`` here",
+			prose: "use [[CODE_1]] here",
+			code:  []string{"``a `tick` inside``"},
+		},
+		{
+			name:  "inline code in a fence",
+			text:  "see ```\nfmt.Println(`raw`)\n``` for details",
+			prose: "see [[CODE_1]] for details",
+			code:  []string{"```\nfmt.Println(`raw`)\n```"},
+		},
+		{
+			name:  "unclosed backtick",
+			text:  "it's `broken and\nthat's it",
+			prose: "it's `broken and\nthat's it",
+		},
+		{
+			// The first backtick isn't closed on its line, so the next
+			// one opens code
+			name:  "inline code doesn't span lines",
+			text:  "`one\ntwo` and `three`",
+			prose: "`one\ntwo[[CODE_1]]three`",
+			code:  []string{"` and `"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Protect(tt.text)
+			if got.Prose != tt.prose {
+				t.Errorf("Prose = %q, want %q", got.Prose, tt.prose)
+			}
+			if !reflect.DeepEqual(got.Code, tt.code) {
+				t.Errorf("Code = %q, want %q", got.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	p := Protect("run `make deploy` then ```\ngo test ./...\n``` and `git push`")
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "in order",
+			output: "bestie run [[CODE_1]] then [[CODE_2]] and [[CODE_3]] fr",
+			want:   "bestie run `make deploy` then ```\ngo test ./...\n``` and `git push` fr",
+		},
+		{
+			name:   "out of order",
+			output: "[[CODE_3]] after [[CODE_1]], vibe check [[CODE_2]]",
+			want:   "`git push` after `make deploy`, vibe check ```\ngo test ./...\n```",
+		},
+		{
+			name:   "case and spacing changed",
+			output: "run [[ code_1 ]] then [[Code_2]] and [[CODE_3 ]]",
+			want:   "run `make deploy` then ```\ngo test ./...\n``` and `git push`",
+		},
+		{
+			name:   "dropped placeholder appended",
+			output: "run [[CODE_1]] and [[CODE_3]], no cap",
+			want:   "run `make deploy` and `git push`, no cap\n```\ngo test ./...\n```",
+		},
+		{
+			name:   "repeated placeholder filled once",
+			output: "[[CODE_1]] [[CODE_1]] [[CODE_2]] [[CODE_3]]",
+			want:   "`make deploy`  ```\ngo test ./...\n``` `git push`",
+		},
+		{
+			name:   "made up placeholder removed",
+			output: "[[CODE_1]] [[CODE_2]] [[CODE_3]] [[CODE_9]]",
+			want:   "`make deploy` ```\ngo test ./...\n``` `git push` ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Restore(tt.output); got != tt.want {
+				t.Errorf("Restore = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestorePartialLeavesMissingCodeOut(t *testing.T) {
+	p := Protect("run `make deploy` then `git push`")
+	if got, want := p.RestorePartial("run [[CODE_1]] th"), "run `make deploy` th"; got != want {
+		t.Errorf("RestorePartial = %q, want %q", got, want)
+	}
+}
+
+func TestOnlyCode(t *testing.T) {
+	if !Protect("  ```\nls\n```  `pwd` ").OnlyCode() {
+		t.Errorf("code with whitespace around it isn't only code")
+	}
+	if Protect("look `pwd`").OnlyCode() {
+		t.Errorf("code with prose is only code")
+	}
+	if Protect("no code").OnlyCode() {
+		t.Errorf("prose without code is only code")
+	}
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Request is a parsed command invocation
+type Request struct {
+	// Prefix is how the command was invoked, e.g. "/genalpha"; it is shown
+	// in front of command names in help
+	Prefix string
+	// Name is the lowercased command name
+	Name string
+	// Args are the whitespace separated arguments after the name
+	Args      []string
+	UserID    string
+	ChannelID string
+	// IsAdmin reports whether the invoking user is a bot admin
+	IsAdmin bool
+}
+
+// Handler runs a command and returns the reply text
+type Handler func(ctx context.Context, req Request) string
+
+// Command describes a command and how to run it
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+	AdminOnly   bool
+	// Enabled reports whether the feature behind the command is turned on
+	// in this deployment; nil means always enabled
+	Enabled func() bool
+	Handler Handler
+}
+
+func (c Command) enabled() bool {
+	return c.Enabled == nil || c.Enabled()
+}
+
+// Registry holds the available commands
+type Registry struct {
+	commands map[string]Command
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]Command)}
+}
+
+// Register adds a command, replacing any command with the same name
+func (r *Registry) Register(cmd Command) {
+	r.commands[strings.ToLower(cmd.Name)] = cmd
+}
+
+// Parse splits command text into a request without user context
+func Parse(prefix, text string) Request {
+	req := Request{Prefix: prefix}
+	fields := strings.Fields(text)
+	if len(fields) > 0 {
+		req.Name = strings.ToLower(fields[0])
+		req.Args = fields[1:]
+	}
+	return req
+}
+
+// Dispatch runs the requested command. Help, no command at all and unknown
+// commands reply with the help text; commands the user may not run, or
+// whose feature is disabled, are treated as unknown.
+func (r *Registry) Dispatch(ctx context.Context, req Request) string {
+	if req.Name == "" || req.Name == "help" {
+		return r.Help(req.Prefix, req.IsAdmin)
+	}
+
+	cmd, ok := r.commands[req.Name]
+	if !ok || !cmd.enabled() || (cmd.AdminOnly && !req.IsAdmin) {
+		if suggestion := r.Suggest(req.Name, req.IsAdmin); suggestion != "" {
+			return fmt.Sprintf("🤔 Unknown command `%s`. Did you mean `%s %s`?\n\n%s", req.Name, req.Prefix, suggestion, r.Help(req.Prefix, req.IsAdmin))
+		}
+		return fmt.Sprintf("🤔 Unknown command `%s`.\n\n%s", req.Name, r.Help(req.Prefix, req.IsAdmin))
+	}
+
+	return cmd.Handler(ctx, req)
+}
+
+// Has reports whether name is help or a command the user may run, for
+// telling commands apart from other text addressed to the bot
+func (r *Registry) Has(name string, isAdmin bool) bool {
+	name = strings.ToLower(name)
+	if name == "help" {
+		return true
+	}
+	cmd, ok := r.commands[name]
+	return ok && cmd.enabled() && (!cmd.AdminOnly || isAdmin)
+}
+
+// typoDistance is how many letters a word may differ from a command name
+// and still be taken for a mistyped command: one for short names, which
+// are otherwise close to everyday words like "hello", two for longer ones
+func typoDistance(name string) int {
+	if len([]rune(name)) <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// Suggest returns the help or command name the user may run that name is
+// most likely a typo of, or "" when none is close. Names shorter than four
+// letters are too easily close to an unrelated word to guess at.
+func (r *Registry) Suggest(name string, isAdmin bool) string {
+	name = strings.ToLower(name)
+	if len([]rune(name)) < 4 {
+		return ""
+	}
+
+	best, bestDistance := "", typoDistance(name)+1
+	consider := func(candidate string) {
+		distance := editDistance(name, candidate)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	consider("help")
+	for candidate := range r.commands {
+		if r.Has(candidate, isAdmin) {
+			consider(candidate)
+		}
+	}
+	return best
+}
+
+// Resembles reports whether name looks like a command the user got wrong:
+// a command they may not run, or a typo of one they may. Text addressed to
+// the bot that resembles a command is answered with a hint rather than
+// handled as a message.
+func (r *Registry) Resembles(name string, isAdmin bool) bool {
+	if _, ok := r.commands[strings.ToLower(name)]; ok {
+		return true
+	}
+	return r.Suggest(name, isAdmin) != ""
+}
+
+// editDistance counts the letters to insert, delete, change or swap with
+// their neighbour to turn a into b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ar)][len(br)]
+}
+
+// Help renders the commands available to a user, one per line
+func (r *Registry) Help(prefix string, isAdmin bool) string {
+	var names []string
+	for name, cmd := range r.commands {
+		if cmd.enabled() && (!cmd.AdminOnly || isAdmin) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("*Available commands*\n")
+	fmt.Fprintf(&b, "• `%s help` — show this list\n", prefix)
+	for _, name := range names {
+		cmd := r.commands[name]
+		usage := cmd.Name
+		if cmd.Usage != "" {
+			usage += " " + cmd.Usage
+		}
+		fmt.Fprintf(&b, "• `%s %s` — %s", prefix, usage, cmd.Description)
+		if cmd.AdminOnly {
+			b.WriteString(" _(admin)_")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,160 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// testRegistry has a command always there, one with usage, one behind a
+// feature that glossary turns on and an admin-only one
+func testRegistry(glossary *bool) *Registry {
+	reply := func(text string) Handler {
+		return func(context.Context, Request) string { return text }
+	}
+	r := NewRegistry()
+	r.Register(Command{Name: "stats", Description: "show translation stats", Handler: reply("stats")})
+	r.Register(Command{Name: "style", Usage: "<name>", Description: "pick a translation style", Handler: reply("style")})
+	r.Register(Command{Name: "glossary", Description: "manage the glossary", Enabled: func() bool { return *glossary }, Handler: reply("glossary")})
+	r.Register(Command{Name: "purge", Description: "forget stored translations", AdminOnly: true, Handler: reply("purge")})
+	return r
+}
+
+func TestHelp(t *testing.T) {
+	tests := []struct {
+		name     string
+		glossary bool
+		isAdmin  bool
+		want     []string
+	}{
+		{
+			name: "user",
+			want: []string{
+				"*Available commands*",
+				"• `/genalpha help` — show this list",
+				"• `/genalpha stats` — show translation stats",
+				"• `/genalpha style <name>` — pick a translation style",
+			},
+		},
+		{
+			name:     "user with the glossary on",
+			glossary: true,
+			want: []string{
+				"*Available commands*",
+				"• `/genalpha help` — show this list",
+				"• `/genalpha glossary` — manage the glossary",
+				"• `/genalpha stats` — show translation stats",
+				"• `/genalpha style <name>` — pick a translation style",
+			},
+		},
+		{
+			name:    "admin",
+			isAdmin: true,
+			want: []string{
+				"*Available commands*",
+				"• `/genalpha help` — show this list",
+				"• `/genalpha purge` — forget stored translations _(admin)_",
+				"• `/genalpha stats` — show translation stats",
+				"• `/genalpha style <name>` — pick a translation style",
+			},
+		},
+		{
+			name:     "admin with the glossary on",
+			glossary: true,
+			isAdmin:  true,
+			want: []string{
+				"*Available commands*",
+				"• `/genalpha help` — show this list",
+				"• `/genalpha glossary` — manage the glossary",
+				"• `/genalpha purge` — forget stored translations _(admin)_",
+				"• `/genalpha stats` — show translation stats",
+				"• `/genalpha style <name>` — pick a translation style",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			glossary := tt.glossary
+			got := testRegistry(&glossary).Help("/genalpha", tt.isAdmin)
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Help =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name    string
+		isAdmin bool
+		want    string
+	}{
+		{name: "stast", want: "stats"},
+		{name: "STATZ", want: "stats"},
+		{name: "styel", want: "style"},
+		{name: "hlep", want: "help"},
+		{name: "glosary", want: ""},
+		{name: "purg", want: ""},
+		{name: "purg", isAdmin: true, want: "purge"},
+		{name: "hello", want: ""},
+		{name: "sta", want: ""},
+		{name: "weather", want: ""},
+	}
+	for _, tt := range tests {
+		glossary := false
+		if got := testRegistry(&glossary).Suggest(tt.name, tt.isAdmin); got != tt.want {
+			t.Errorf("Suggest(%q, admin %v) = %q, want %q", tt.name, tt.isAdmin, got, tt.want)
+		}
+	}
+}
+
+func TestResembles(t *testing.T) {
+	tests := []struct {
+		name    string
+		isAdmin bool
+		want    bool
+	}{
+		{name: "stats", want: true},
+		{name: "stast", want: true},
+		// Commands the user may not run still look like commands
+		{name: "purge", want: true},
+		{name: "glossary", want: true},
+		{name: "hello", want: false},
+		{name: "translate", want: false},
+	}
+	for _, tt := range tests {
+		glossary := false
+		if got := testRegistry(&glossary).Resembles(tt.name, tt.isAdmin); got != tt.want {
+			t.Errorf("Resembles(%q, admin %v) = %v, want %v", tt.name, tt.isAdmin, got, tt.want)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	glossary := false
+	r := testRegistry(&glossary)
+	help := r.Help("/genalpha", false)
+
+	tests := []struct {
+		text    string
+		isAdmin bool
+		want    string
+	}{
+		{text: "stats", want: "stats"},
+		{text: "STYLE pirate", want: "style"},
+		{text: "", want: help},
+		{text: "help", want: help},
+		{text: "stast", want: "🤔 Unknown command `stast`. Did you mean `/genalpha stats`?\n\n" + help},
+		{text: "purge", want: "🤔 Unknown command `purge`.\n\n" + help},
+		{text: "purge", isAdmin: true, want: "purge"},
+		{text: "glossary", want: "🤔 Unknown command `glossary`.\n\n" + help},
+	}
+	for _, tt := range tests {
+		req := Parse("/genalpha", tt.text)
+		req.IsAdmin = tt.isAdmin
+		if got := r.Dispatch(context.Background(), req); got != tt.want {
+			t.Errorf("Dispatch(%q, admin %v) = %q, want %q", tt.text, tt.isAdmin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Outcome classifies a finished call for the limiter
+type Outcome int
+
+const (
+	// Success means the call completed normally
+	Success Outcome = iota
+	// Failure means the call failed for a reason unrelated to load
+	Failure
+	// Overload means the call timed out or was rejected for load (429/5xx)
+	Overload
+)
+
+// windowSize is the number of healthy samples required before the limit is
+// increased by one
+const windowSize = 10
+
+// Limiter bounds the number of concurrent calls, adjusting the bound between
+// min and max using AIMD: the limit increases by one after a window of healthy
+// calls and is halved whenever a call is overloaded.
+type Limiter struct {
+	mu       sync.Mutex
+	min      int
+	max      int
+	limit    int
+	inFlight int
+
+	// priorityWaiting counts AcquirePriority callers waiting for a slot;
+	// regular callers don't take a slot while any are waiting
+	priorityWaiting int
+
+	// latencyTarget is the average latency above which a window is not
+	// considered healthy enough to increase the limit
+	latencyTarget time.Duration
+
+	// samples holds the latencies of the current healthy window
+	samples []time.Duration
+
+	// lastDecrease prevents one burst of concurrent failures from halving
+	// the limit several times in a row
+	lastDecrease time.Time
+
+	// changed is closed and replaced whenever a slot may have become free
+	changed chan struct{}
+
+	onChange func(limit int)
+	now      func() time.Time
+}
+
+// NewAdaptive creates a limiter that starts at min and adapts up to max
+func NewAdaptive(min, max int, latencyTarget time.Duration, onChange func(limit int)) *Limiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+
+	return &Limiter{
+		min:           min,
+		max:           max,
+		limit:         min,
+		latencyTarget: latencyTarget,
+		changed:       make(chan struct{}),
+		onChange:      onChange,
+		now:           time.Now,
+	}
+}
+
+// NewFixed creates a limiter pinned at n concurrent calls
+func NewFixed(n int) *Limiter {
+	return NewAdaptive(n, n, 0, nil)
+}
+
+// Acquire blocks until a slot is available or the context is done
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit && l.priorityWaiting == 0 {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// AcquirePriority is like Acquire, but gets the next free slot ahead of
+// regular callers
+func (l *Limiter) AcquirePriority(ctx context.Context) error {
+	l.mu.Lock()
+	l.priorityWaiting++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.priorityWaiting--
+		// Regular callers may be waiting for the priority queue to empty
+		close(l.changed)
+		l.changed = make(chan struct{})
+		l.mu.Unlock()
+	}()
+
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wait := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Release frees a slot and feeds the call's latency and outcome back into
+// the limit calculation
+func (l *Limiter) Release(latency time.Duration, outcome Outcome) {
+	l.mu.Lock()
+	l.inFlight--
+
+	previous := l.limit
+	if l.min != l.max {
+		l.record(latency, outcome)
+	}
+	current := l.limit
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+	l.mu.Unlock()
+
+	if current != previous && l.onChange != nil {
+		l.onChange(current)
+	}
+}
+
+// record updates the limit for one sample; callers must hold l.mu
+func (l *Limiter) record(latency time.Duration, outcome Outcome) {
+	switch outcome {
+	case Overload:
+		l.samples = l.samples[:0]
+		// Only decrease once per latency target so the in-flight calls of
+		// the same incident don't collapse the limit straight to min
+		if now := l.now(); now.Sub(l.lastDecrease) >= l.latencyTarget {
+			l.lastDecrease = now
+			l.limit = l.limit / 2
+			if l.limit < l.min {
+				l.limit = l.min
+			}
+		}
+	case Failure:
+		// Not a load signal, but not evidence of health either
+		l.samples = l.samples[:0]
+	case Success:
+		l.samples = append(l.samples, latency)
+		if len(l.samples) < windowSize {
+			return
+		}
+
+		var total time.Duration
+		for _, sample := range l.samples {
+			total += sample
+		}
+		l.samples = l.samples[:0]
+
+		if total/windowSize <= l.latencyTarget && l.limit < l.max {
+			l.limit++
+		}
+	}
+}
+
+// Limit returns the current concurrency limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// InFlight returns the number of calls currently holding a slot
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
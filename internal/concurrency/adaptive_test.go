@@ -0,0 +1,150 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// backend simulates an LLM API that slows down as concurrency grows and
+// rejects calls beyond its capacity
+type backend struct {
+	capacity   int
+	perRequest time.Duration
+}
+
+func (b backend) call(concurrency int) (time.Duration, Outcome) {
+	if concurrency > b.capacity {
+		return 2 * b.perRequest * time.Duration(concurrency), Overload
+	}
+	return b.perRequest * time.Duration(concurrency), Success
+}
+
+// run drives l with rounds of as many concurrent calls as the limit allows,
+// against backends[round] (the last one once they run out), and returns
+// the limit after every round
+func run(t *testing.T, l *Limiter, rounds int, backends func(round int) backend) []int {
+	t.Helper()
+
+	clock := time.Unix(0, 0)
+	l.now = func() time.Time { return clock }
+
+	limits := make([]int, 0, rounds)
+	for round := 0; round < rounds; round++ {
+		n := l.Limit()
+		for i := 0; i < n; i++ {
+			if err := l.Acquire(context.Background()); err != nil {
+				t.Fatalf("round %d: Acquire: %v", round, err)
+			}
+		}
+		if got := l.InFlight(); got != n {
+			t.Fatalf("round %d: InFlight() = %d, want %d", round, got, n)
+		}
+		b := backends(round)
+		for i := 0; i < n; i++ {
+			l.Release(b.call(n))
+		}
+		clock = clock.Add(time.Second)
+		limits = append(limits, l.Limit())
+	}
+	return limits
+}
+
+func TestAdaptiveSettlesBelowLatencyTarget(t *testing.T) {
+	// 20ms per concurrent call: 5 calls average 100ms, 6 average 120ms
+	l := NewAdaptive(1, 20, 100*time.Millisecond, nil)
+	limits := run(t, l, 200, func(int) backend {
+		return backend{capacity: 50, perRequest: 20 * time.Millisecond}
+	})
+
+	for round, limit := range limits[100:] {
+		if limit != 6 {
+			t.Fatalf("round %d: limit = %d, want it settled at 6", round+100, limit)
+		}
+	}
+}
+
+func TestAdaptiveOscillatesAroundCapacity(t *testing.T) {
+	var changes []int
+	l := NewAdaptive(1, 20, time.Second, func(limit int) { changes = append(changes, limit) })
+	limits := run(t, l, 500, func(int) backend {
+		return backend{capacity: 8, perRequest: time.Millisecond}
+	})
+
+	for round, limit := range limits[100:] {
+		if limit < 4 || limit > 9 {
+			t.Fatalf("round %d: limit = %d, want between half the capacity and one above it", round+100, limit)
+		}
+	}
+	if len(changes) == 0 || changes[len(changes)-1] != l.Limit() {
+		t.Errorf("onChange calls %v don't end at the current limit %d", changes, l.Limit())
+	}
+}
+
+func TestAdaptiveFollowsDegradingBackend(t *testing.T) {
+	l := NewAdaptive(1, 20, time.Second, nil)
+	limits := run(t, l, 600, func(round int) backend {
+		if round < 300 {
+			return backend{capacity: 12, perRequest: time.Millisecond}
+		}
+		return backend{capacity: 3, perRequest: time.Millisecond}
+	})
+
+	if peak := maxOf(limits[200:300]); peak < 10 {
+		t.Errorf("limit peaked at %d before the backend degraded, want at least 10", peak)
+	}
+	for round, limit := range limits[320:] {
+		if limit > 4 {
+			t.Fatalf("round %d: limit = %d after the backend degraded to 3, want at most 4", round+320, limit)
+		}
+	}
+}
+
+func TestAdaptiveOverloadHalvesOncePerIncident(t *testing.T) {
+	l := NewAdaptive(1, 16, time.Second, nil)
+	l.limit = 16
+	clock := time.Unix(100, 0)
+	l.now = func() time.Time { return clock }
+
+	for i := 0; i < 16; i++ {
+		l.Acquire(context.Background())
+	}
+	for i := 0; i < 16; i++ {
+		l.Release(5*time.Second, Overload)
+	}
+	if got := l.Limit(); got != 8 {
+		t.Errorf("limit after a burst of overloads = %d, want 8", got)
+	}
+
+	clock = clock.Add(time.Second)
+	l.Acquire(context.Background())
+	l.Release(5*time.Second, Overload)
+	if got := l.Limit(); got != 4 {
+		t.Errorf("limit after the next overload = %d, want 4", got)
+	}
+}
+
+func TestFixedNeverMoves(t *testing.T) {
+	l := NewFixed(3)
+	limits := run(t, l, 50, func(round int) backend {
+		if round%2 == 0 {
+			return backend{capacity: 1, perRequest: time.Second}
+		}
+		return backend{capacity: 50, perRequest: time.Millisecond}
+	})
+	for round, limit := range limits {
+		if limit != 3 {
+			t.Fatalf("round %d: limit = %d, want 3", round, limit)
+		}
+	}
+}
+
+func maxOf(values []int) int {
+	peak := values[0]
+	for _, v := range values[1:] {
+		if v > peak {
+			peak = v
+		}
+	}
+	return peak
+}
@@ -0,0 +1,49 @@
+package conformance
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/slacktest"
+)
+
+var seed = flag.Int64("conformance.seed", 0, "first seed of the conformance runs, to replay a failure; 0 picks one")
+
+// features are the configurations the guarantees must hold under
+var features = []struct {
+	name string
+	env  map[string]string
+}{
+	{"defaults", nil},
+	{"everyone", map[string]string{"SLACK_TARGET_USERS": "*"}},
+	{"watching itself", map[string]string{"WATCH_RULES": `[{"channel":"` + channelID(0) + `","author":"` + slacktest.BotUserID + `"}]`}},
+	{"one worker", map[string]string{"WORKER_POOL_SIZE": "1"}},
+	{"unordered", map[string]string{"PRESERVE_CHANNEL_ORDER": "false", "WORKER_POOL_SIZE": "8"}},
+	{"placeholder", map[string]string{"TRANSLATION_PLACEHOLDER": "true"}},
+	{"catch-up", map[string]string{"CATCHUP": "true"}},
+	{"progress reactions", map[string]string{"PROGRESS_REACTIONS": "true"}},
+}
+
+func TestConformance(t *testing.T) {
+	runs, messages, restarts := 3, 12, 3
+	if testing.Short() {
+		runs, messages, restarts = 1, 6, 1
+	}
+	first := *seed
+	if first == 0 {
+		first = time.Now().UnixNano()
+	}
+
+	for _, feature := range features {
+		t.Run(feature.name, func(t *testing.T) {
+			for i := 0; i < runs; i++ {
+				s := first + int64(i)
+				t.Run(fmt.Sprintf("seed=%d", s), func(t *testing.T) {
+					Run(t, Options{Seed: s, Messages: messages, Restarts: restarts, Env: feature.env})
+				})
+			}
+		})
+	}
+}
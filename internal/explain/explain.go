@@ -0,0 +1,217 @@
+package explain
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Step is a single filter decision made for a message
+type Step struct {
+	Filter string
+	Passed bool
+	Detail string
+}
+
+// Record is the decision trail for one message
+type Record struct {
+	Channel    string
+	Timestamp  string
+	User       string
+	ReceivedAt time.Time
+	Steps      []Step
+	Translated bool
+	Model      string
+	Latency    time.Duration
+	Error      string
+}
+
+// Log keeps the decision records of the most recent messages in a bounded
+// ring buffer keyed by channel and timestamp
+type Log struct {
+	mu       sync.Mutex
+	capacity int
+	order    []string
+	next     int
+	records  map[string]*Record
+}
+
+// NewLog creates a decision log holding up to capacity messages
+func NewLog(capacity int) *Log {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Log{
+		capacity: capacity,
+		order:    make([]string, 0, capacity),
+		records:  make(map[string]*Record, capacity),
+	}
+}
+
+func key(channel, ts string) string {
+	return channel + "/" + ts
+}
+
+// record returns the record for a message, creating it and evicting the
+// oldest one if needed; callers must hold l.mu
+func (l *Log) record(channel, ts string) *Record {
+	k := key(channel, ts)
+	if r, ok := l.records[k]; ok {
+		return r
+	}
+
+	r := &Record{Channel: channel, Timestamp: ts, ReceivedAt: time.Now()}
+	if len(l.order) < l.capacity {
+		l.order = append(l.order, k)
+	} else {
+		delete(l.records, l.order[l.next])
+		l.order[l.next] = k
+		l.next = (l.next + 1) % l.capacity
+	}
+	l.records[k] = r
+	return r
+}
+
+// Step appends a filter decision to a message's trail
+func (l *Log) Step(channel, ts, filter string, passed bool, detail string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := l.record(channel, ts)
+	r.Steps = append(r.Steps, Step{Filter: filter, Passed: passed, Detail: detail})
+}
+
+// SetUser records the author of a message
+func (l *Log) SetUser(channel, ts, user string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.record(channel, ts).User = user
+}
+
+// Translated records a successful translation of a message
+func (l *Log) Translated(channel, ts, model string, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := l.record(channel, ts)
+	r.Translated = true
+	r.Model = model
+	r.Latency = latency
+}
+
+// Failed records an error that stopped a message from being translated
+func (l *Log) Failed(channel, ts string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.record(channel, ts).Error = err.Error()
+}
+
+// Get returns a copy of the record for a message
+func (l *Log) Get(channel, ts string) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.records[key(channel, ts)]
+	if !ok {
+		return Record{}, false
+	}
+	return copyRecord(r), true
+}
+
+// Latest returns the most recently received record for a channel
+func (l *Log) Latest(channel string) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var latest *Record
+	for _, r := range l.records {
+		if r.Channel == channel && (latest == nil || r.ReceivedAt.After(latest.ReceivedAt)) {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return Record{}, false
+	}
+	return copyRecord(latest), true
+}
+
+// Recent returns copies of all retained records, oldest first
+func (l *Log) Recent() []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]Record, 0, len(l.order))
+	for i := range l.order {
+		k := l.order[(l.next+i)%len(l.order)]
+		records = append(records, copyRecord(l.records[k]))
+	}
+	return records
+}
+
+func copyRecord(r *Record) Record {
+	c := *r
+	c.Steps = append([]Step(nil), r.Steps...)
+	return c
+}
+
+// permalinkPattern matches the channel and packed timestamp of a Slack
+// message permalink, e.g. https://acme.slack.com/archives/C0123/p1700000000123456
+var permalinkPattern = regexp.MustCompile(`/archives/([A-Z0-9]+)/p(\d{10})(\d{6})`)
+
+// ParsePermalink extracts the channel ID and message timestamp from a Slack
+// message permalink. Slack wraps links in angle brackets, which are ignored.
+func ParsePermalink(link string) (channel, ts string, err error) {
+	link = strings.Trim(strings.TrimSpace(link), "<>")
+	if i := strings.Index(link, "|"); i >= 0 {
+		link = link[:i]
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid permalink %q: %w", link, err)
+	}
+
+	m := permalinkPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", "", fmt.Errorf("not a Slack message permalink: %q", link)
+	}
+	return m[1], m[2] + "." + m[3], nil
+}
+
+// Format renders a decision trail for a Slack message
+func Format(r Record) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Decision trail for message %s in <#%s>*", r.Timestamp, r.Channel)
+	if r.User != "" {
+		fmt.Fprintf(&b, " from <@%s>", r.User)
+	}
+	b.WriteString("\n")
+
+	for _, step := range r.Steps {
+		mark := "✅"
+		if !step.Passed {
+			mark = "⛔"
+		}
+		fmt.Fprintf(&b, "%s %s", mark, step.Filter)
+		if step.Detail != "" {
+			fmt.Fprintf(&b, " — %s", step.Detail)
+		}
+		b.WriteString("\n")
+	}
+
+	switch {
+	case r.Translated:
+		fmt.Fprintf(&b, "🗣️ Translated with `%s` in %s", r.Model, r.Latency.Round(time.Millisecond))
+	case r.Error != "":
+		fmt.Fprintf(&b, "❌ Translation failed: %s", r.Error)
+	default:
+		b.WriteString("⏩ Not translated")
+	}
+	return b.String()
+}
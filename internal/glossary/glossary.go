@@ -0,0 +1,198 @@
+// Package glossary holds the team's own slang and names, like "the kraken"
+// for the CI, which the model is told about so translations use them
+// correctly.
+package glossary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/user/slack-bot-api/internal/threadctx"
+)
+
+// Entry is a term and what it means
+type Entry struct {
+	Term    string
+	Meaning string
+}
+
+// Glossary is a set of terms loaded from a YAML or JSON file of term →
+// meaning pairs, which terms added later are written back to. It is safe
+// for concurrent use.
+type Glossary struct {
+	path string
+	// maxTokens caps the prompt section
+	maxTokens int
+
+	mu    sync.RWMutex
+	terms map[string]string
+}
+
+// Load reads the glossary at path, a YAML file when it ends in .yaml or
+// .yml and JSON otherwise. A file that doesn't exist yet is an empty
+// glossary, created when the first term is added. An empty path is a
+// glossary that is turned off.
+func Load(path string, maxTokens int) (*Glossary, error) {
+	g := &Glossary{path: path, maxTokens: maxTokens, terms: make(map[string]string)}
+	if path == "" {
+		return g, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading glossary: %w", err)
+	}
+
+	if g.isYAML() {
+		err = yaml.Unmarshal(data, &g.terms)
+	} else {
+		err = json.Unmarshal(data, &g.terms)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing glossary %s: %w", path, err)
+	}
+	if g.terms == nil {
+		// An empty YAML file
+		g.terms = make(map[string]string)
+	}
+	for term, meaning := range g.terms {
+		if strings.TrimSpace(term) == "" || strings.TrimSpace(meaning) == "" {
+			return nil, fmt.Errorf("error parsing glossary %s: term %q needs a term and a meaning", path, term)
+		}
+	}
+	return g, nil
+}
+
+// Enabled reports whether GLOSSARY_PATH is set
+func (g *Glossary) Enabled() bool {
+	return g.path != ""
+}
+
+// Len returns the number of terms
+func (g *Glossary) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.terms)
+}
+
+// Entries returns the terms sorted alphabetically
+func (g *Glossary) Entries() []Entry {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(g.terms))
+	for term, meaning := range g.terms {
+		entries = append(entries, Entry{Term: term, Meaning: meaning})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Term) < strings.ToLower(entries[j].Term)
+	})
+	return entries
+}
+
+// Add sets the meaning of a term, replacing a term spelled the same
+// regardless of case, and writes the glossary back to its file. Nothing
+// changes when writing fails.
+func (g *Glossary) Add(term, meaning string) error {
+	term, meaning = oneLine(term), oneLine(meaning)
+	if term == "" || meaning == "" {
+		return errors.New("a term needs a meaning")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	terms := make(map[string]string, len(g.terms)+1)
+	for existing, existingMeaning := range g.terms {
+		if !strings.EqualFold(existing, term) {
+			terms[existing] = existingMeaning
+		}
+	}
+	terms[term] = meaning
+
+	if err := g.write(terms); err != nil {
+		return err
+	}
+	g.terms = terms
+	return nil
+}
+
+// Prompt renders the glossary as a section of the system prompt, or ""
+// when it's empty. Terms are added alphabetically until the section would
+// take more than the configured tokens; the number left out is returned
+// too.
+func (g *Glossary) Prompt() (string, int) {
+	entries := g.Entries()
+	if len(entries) == 0 {
+		return "", 0
+	}
+
+	var section strings.Builder
+	section.WriteString("Glossary of the team's own terms. Use them where they fit, with these meanings:")
+	for i, entry := range entries {
+		line := fmt.Sprintf("\n- %s: %s", oneLine(entry.Term), oneLine(entry.Meaning))
+		if threadctx.EstimateTokens(section.String()+line) > g.maxTokens {
+			if i == 0 {
+				return "", len(entries)
+			}
+			return section.String(), len(entries) - i
+		}
+		section.WriteString(line)
+	}
+	return section.String(), 0
+}
+
+// write saves terms to the glossary's file, replacing it in one step
+func (g *Glossary) write(terms map[string]string) error {
+	var data []byte
+	var err error
+	if g.isYAML() {
+		data, err = yaml.Marshal(terms)
+	} else {
+		data, err = json.MarshalIndent(terms, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding glossary: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(g.path), filepath.Base(g.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error writing glossary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing glossary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing glossary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), g.path); err != nil {
+		return fmt.Errorf("error writing glossary: %w", err)
+	}
+	return nil
+}
+
+func (g *Glossary) isYAML() bool {
+	ext := strings.ToLower(filepath.Ext(g.path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// oneLine trims text and collapses its whitespace, so an entry stays on
+// its line of the prompt
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
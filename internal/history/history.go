@@ -0,0 +1,66 @@
+// Package history keeps a queryable record of posted translations, for the
+// history and leaderboard commands.
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// Translation is one recorded translation
+type Translation struct {
+	Time             time.Time
+	Channel          string
+	User             string
+	OriginalTS       string
+	Original         string
+	Translated       string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UserCount is how many translations a user had
+type UserCount struct {
+	User  string
+	Count int
+}
+
+// Store records translations and answers questions about them
+type Store interface {
+	// Enabled reports whether translations are actually kept
+	Enabled() bool
+	// Record adds a translation
+	Record(ctx context.Context, t Translation) error
+	// Recent returns the last n translations in a channel, newest first
+	Recent(ctx context.Context, channelID string, n int) ([]Translation, error)
+	// Leaderboard returns the n users translated most in a channel since
+	// the given time, most first
+	Leaderboard(ctx context.Context, channelID string, since time.Time, n int) ([]UserCount, error)
+	// Close releases the store
+	Close() error
+}
+
+// Open opens the SQLite database at path, creating and migrating it as
+// needed. An empty path returns a store that keeps nothing.
+func Open(path string) (Store, error) {
+	if path == "" {
+		return discard{}, nil
+	}
+	return openSQLite(path)
+}
+
+// discard is the store used without a database
+type discard struct{}
+
+func (discard) Enabled() bool                                   { return false }
+func (discard) Record(ctx context.Context, t Translation) error { return nil }
+func (discard) Close() error                                    { return nil }
+
+func (discard) Recent(ctx context.Context, channelID string, n int) ([]Translation, error) {
+	return nil, nil
+}
+
+func (discard) Leaderboard(ctx context.Context, channelID string, since time.Time, n int) ([]UserCount, error) {
+	return nil, nil
+}
@@ -0,0 +1,155 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations create and upgrade the schema; a database at version n still
+// needs every migration after index n-1. Never edit or reorder released
+// steps, only append new ones.
+var migrations = []string{
+	`CREATE TABLE translations (
+		id                INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at        INTEGER NOT NULL,
+		channel           TEXT NOT NULL,
+		user              TEXT NOT NULL,
+		original_ts       TEXT NOT NULL,
+		original          TEXT NOT NULL,
+		translated        TEXT NOT NULL,
+		model             TEXT NOT NULL,
+		prompt_tokens     INTEGER NOT NULL,
+		completion_tokens INTEGER NOT NULL
+	);
+	CREATE INDEX translations_channel_created ON translations (channel, created_at);`,
+}
+
+// sqliteStore keeps translations in a SQLite database
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLite(path string) (*sqliteStore, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening history database: %w", err)
+	}
+	// SQLite allows one writer at a time; sharing a single connection
+	// avoids "database is locked" errors between the bot's workers
+	db.SetMaxOpenConns(1)
+
+	s := &sqliteStore{db: db}
+	if err := s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate brings the schema up to date, one transaction per step
+func (s *sqliteStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("error creating history migrations table: %w", err)
+	}
+
+	var version int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("error reading history schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("history database is at schema version %d, newer than this bot's %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("error migrating history database: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error migrating history database to version %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, i+1, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error migrating history database to version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error migrating history database to version %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Enabled() bool { return true }
+
+func (s *sqliteStore) Record(ctx context.Context, t Translation) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO translations
+		(created_at, channel, user, original_ts, original, translated, model, prompt_tokens, completion_tokens)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Time.Unix(), t.Channel, t.User, t.OriginalTS, t.Original, t.Translated, t.Model, t.PromptTokens, t.CompletionTokens)
+	if err != nil {
+		return fmt.Errorf("error recording translation: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Recent(ctx context.Context, channelID string, n int) ([]Translation, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT created_at, channel, user, original_ts, original, translated, model, prompt_tokens, completion_tokens
+		FROM translations WHERE channel = ? ORDER BY created_at DESC, id DESC LIMIT ?`, channelID, n)
+	if err != nil {
+		return nil, fmt.Errorf("error querying translation history: %w", err)
+	}
+	defer rows.Close()
+
+	var translations []Translation
+	for rows.Next() {
+		var t Translation
+		var created int64
+		if err := rows.Scan(&created, &t.Channel, &t.User, &t.OriginalTS, &t.Original, &t.Translated, &t.Model, &t.PromptTokens, &t.CompletionTokens); err != nil {
+			return nil, fmt.Errorf("error reading translation history: %w", err)
+		}
+		t.Time = time.Unix(created, 0)
+		translations = append(translations, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading translation history: %w", err)
+	}
+	return translations, nil
+}
+
+func (s *sqliteStore) Leaderboard(ctx context.Context, channelID string, since time.Time, n int) ([]UserCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT user, COUNT(*) AS n FROM translations
+		WHERE channel = ? AND created_at >= ?
+		GROUP BY user ORDER BY n DESC, user LIMIT ?`, channelID, since.Unix(), n)
+	if err != nil {
+		return nil, fmt.Errorf("error querying leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []UserCount
+	for rows.Next() {
+		var c UserCount
+		if err := rows.Scan(&c.User, &c.Count); err != nil {
+			return nil, fmt.Errorf("error reading leaderboard: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading leaderboard: %w", err)
+	}
+	return counts, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,71 @@
+// Package httpclient builds the HTTP client every outgoing request goes
+// through, so the bot works behind a corporate proxy with a private CA.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// New creates the client shared by the Slack and LLM APIs. Requests go
+// through the proxy in HTTPS_PROXY or HTTP_PROXY, except for hosts in
+// NO_PROXY, and trust the CA at OPENAI_CA_CERT_PATH on top of the system
+// roots. OPENAI_INSECURE_SKIP_VERIFY turns certificate checks off
+// altogether. The client has no timeout of its own; see WithTimeout.
+func New(cfg *config.Config, logger *logging.Logger) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.OpenAICACertPath != "" {
+		roots, err := rootsWith(cfg.OpenAICACertPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = roots
+		logger.Infof("🔐 Trusting the CA in %s for outgoing requests", cfg.OpenAICACertPath)
+	}
+	if cfg.OpenAIInsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+		logger.Warnf("🚨🚨🚨 OPENAI_INSECURE_SKIP_VERIFY=true: TLS certificates of OpenAI, Slack and every other API are NOT verified. Anyone on the network path can read and change requests, API keys included. Use OPENAI_CA_CERT_PATH instead! 🚨🚨🚨")
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// WithTimeout returns a client sharing client's transport, and so its
+// proxy, TLS settings and connections, whose requests time out after
+// timeout
+func WithTimeout(client *http.Client, timeout time.Duration) *http.Client {
+	withTimeout := *client
+	withTimeout.Timeout = timeout
+	return &withTimeout
+}
+
+// rootsWith returns the system's root CAs plus the PEM certificates in
+// path
+func rootsWith(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OPENAI_CA_CERT_PATH: %w", err)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		// No system roots, e.g. in a scratch container: the given CA is
+		// all there is
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("OPENAI_CA_CERT_PATH %s has no PEM certificates", path)
+	}
+	return roots, nil
+}
@@ -0,0 +1,155 @@
+// Package httpserver serves the bot's HTTP endpoints: health checks for the
+// platform it runs on, stats, metrics and debugging state.
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/metrics"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+// HealthChecker reports whether the bot is alive and ready for events
+type HealthChecker interface {
+	Health() v1.Health
+	Readiness() v1.Health
+}
+
+// StatsProvider sums up the translations since startup
+type StatsProvider interface {
+	Stats() v1.Stats
+}
+
+// DebugProvider exposes the bot's internal state for troubleshooting
+type DebugProvider interface {
+	DebugState() v1.DebugState
+	SlackUsage() v1.SlackUsage
+}
+
+// Server serves the bot's endpoints on its own mux
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+	logger *logging.Logger
+}
+
+// New creates a server listening on addr, like ":8080", with the health,
+// readiness, status and metrics routes registered. More routes can be
+// added with Handle and the Register methods before Start.
+func New(addr string, health HealthChecker, stats StatsProvider, logger *logging.Logger) *Server {
+	mux := http.NewServeMux()
+	s := &Server{
+		mux:    mux,
+		server: &http.Server{Addr: addr, Handler: mux},
+		logger: logger,
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Gen Alpha Slack Bot is running! 🤖"))
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		s.writeHealth(w, health.Health())
+	})
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		s.writeHealth(w, health.Readiness())
+	})
+	build := buildInfo()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status := stats.Stats()
+		status.Build = build
+		s.writeJSON(w, "stats", status)
+	})
+	mux.Handle("/metrics", metrics.Default.Handler())
+	return s
+}
+
+// RegisterDebug adds the /debug routes
+func (s *Server) RegisterDebug(debug DebugProvider) {
+	s.mux.HandleFunc("/debug/state", func(w http.ResponseWriter, r *http.Request) {
+		s.writeJSON(w, "debug state", debug.DebugState())
+	})
+	s.mux.HandleFunc("/debug/slack-usage", func(w http.ResponseWriter, r *http.Request) {
+		s.writeJSON(w, "Slack usage", debug.SlackUsage())
+	})
+}
+
+// Handle adds a route
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the handler serving every route, for tests and for
+// mounting the routes elsewhere
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start listens on the server's address and serves in the background until
+// Shutdown. It fails right away when the address can't be listened on.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", s.server.Addr, err)
+	}
+
+	s.logger.Infof("Starting HTTP server on %s...", listener.Addr())
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Errorf("HTTP server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the requests being
+// served to finish, until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// buildInfo reads the version of the running binary from the build
+// information Go embeds
+func buildInfo() v1.Build {
+	build := v1.Build{GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	build.Version = info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Commit = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
+
+// writeHealth writes a health response, with status 503 unless it's ok
+func (s *Server) writeHealth(w http.ResponseWriter, health v1.Health) {
+	w.Header().Set("Content-Type", "application/json")
+	if health.Status != v1.HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		s.logger.Errorf("Error encoding health: %v", err)
+	}
+}
+
+// writeJSON writes a JSON response, logging what failed to encode
+func (s *Server) writeJSON(w http.ResponseWriter, what string, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Errorf("Error encoding %s: %v", what, err)
+	}
+}
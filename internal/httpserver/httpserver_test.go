@@ -0,0 +1,223 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+	v1 "github.com/user/slack-bot-api/pkg/api/v1"
+)
+
+// fakeBot answers every provider interface with canned responses
+type fakeBot struct {
+	health, readiness v1.Health
+	stats             v1.Stats
+	debugState        v1.DebugState
+	slackUsage        v1.SlackUsage
+}
+
+func (f *fakeBot) Health() v1.Health         { return f.health }
+func (f *fakeBot) Readiness() v1.Health      { return f.readiness }
+func (f *fakeBot) Stats() v1.Stats           { return f.stats }
+func (f *fakeBot) DebugState() v1.DebugState { return f.debugState }
+func (f *fakeBot) SlackUsage() v1.SlackUsage { return f.slackUsage }
+
+func newTestServer(t *testing.T, bot *fakeBot) *httptest.Server {
+	t.Helper()
+	logger := logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+	s := New(":0", bot, bot, logger)
+	s.RegisterDebug(bot)
+	server := httptest.NewServer(s.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// get fetches path and decodes its JSON body into v, returning the status
+func get(t *testing.T, server *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s: Content-Type %q, want application/json", path, ct)
+	}
+	decoder := json.NewDecoder(resp.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("GET %s: decoding: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+// Responses decode back into the v1 types they were encoded from
+func TestResponsesRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	cost := 0.42
+	channels := 3
+	bot := &fakeBot{
+		debugState: v1.DebugState{
+			SchemaVersion:               v1.SchemaVersion,
+			StartupPhase:                "ready",
+			TranslationConcurrencyLimit: 4,
+			TranslationsInFlight:        1,
+			MetricLabelOverflow:         2,
+			DuplicateEventsDropped:      5,
+			Approvals:                   v1.ApprovalStats{Approved: 1, Discarded: 2, Expired: 3},
+			Decisions: []v1.DecisionRecord{{
+				Channel:    "C0000001",
+				Timestamp:  "1700000000.000100",
+				User:       "U0000001",
+				ReceivedAt: at,
+				Steps:      []v1.DecisionStep{{Filter: "target user", Passed: true}, {Filter: "worth translating", Detail: "emoji only"}},
+				Error:      "boom",
+			}},
+		},
+		slackUsage: v1.SlackUsage{
+			SchemaVersion:   v1.SchemaVersion,
+			WindowSeconds:   3600,
+			LatencyBoundsMS: []int64{50, 100, 250},
+			Methods: []v1.MethodUsage{
+				{Method: "chat.postMessage", Calls: 12, RateLimited: 1, AvgLatencyMS: 80, Histogram: []uint64{3, 8, 1, 0}},
+			},
+		},
+		stats: v1.Stats{
+			SchemaVersion:     v1.SchemaVersion,
+			StartedAt:         at,
+			UptimeSeconds:     60,
+			Connection:        v1.Connection{Connected: true, LastConnectedAt: &at, StartupPhase: "ready"},
+			MonitoredChannels: &channels,
+			Translations:      7,
+			EstimatedCost:     &cost,
+			Users:             []v1.Count{{ID: "U0000001", Count: 7}},
+			Channels:          []v1.Count{{ID: "C0000001", Count: 7}},
+			Languages:         []v1.Count{{ID: "en", Count: 7}},
+		},
+	}
+	server := newTestServer(t, bot)
+
+	var state v1.DebugState
+	get(t, server, "/debug/state", &state)
+	if !reflect.DeepEqual(state, bot.debugState) {
+		t.Errorf("/debug/state = %+v, want %+v", state, bot.debugState)
+	}
+
+	var usage v1.SlackUsage
+	get(t, server, "/debug/slack-usage", &usage)
+	if !reflect.DeepEqual(usage, bot.slackUsage) {
+		t.Errorf("/debug/slack-usage = %+v, want %+v", usage, bot.slackUsage)
+	}
+
+	var stats v1.Stats
+	get(t, server, "/status", &stats)
+	if stats.Build.GoVersion == "" {
+		t.Errorf("/status has no build information")
+	}
+	stats.Build = v1.Build{}
+	if !reflect.DeepEqual(stats, bot.stats) {
+		t.Errorf("/status = %+v, want %+v", stats, bot.stats)
+	}
+}
+
+// /status has every field schema.json requires of Stats and nothing it
+// doesn't describe
+func TestStatusMatchesSchema(t *testing.T) {
+	data, err := os.ReadFile("../../pkg/api/v1/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Definitions map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("decoding schema.json: %v", err)
+	}
+	definition := schema.Definitions["Stats"]
+
+	server := newTestServer(t, &fakeBot{stats: v1.Stats{SchemaVersion: v1.SchemaVersion}})
+	var stats v1.Stats
+	if status := get(t, server, "/status", &stats); status != http.StatusOK {
+		t.Errorf("/status returned %d, want 200", status)
+	}
+
+	var fields map[string]json.RawMessage
+	get(t, server, "/status", &fields)
+	for _, name := range definition.Required {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("/status is missing required field %q", name)
+		}
+	}
+	for name := range fields {
+		if _, ok := definition.Properties[name]; !ok {
+			t.Errorf("/status has field %q that schema.json doesn't describe", name)
+		}
+	}
+	for _, name := range []string{"monitored_channels", "target_users"} {
+		if string(fields[name]) != "null" {
+			t.Errorf("%s = %s, want null when unset", name, fields[name])
+		}
+	}
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		health v1.Health
+		want   int
+	}{
+		{name: "healthy", path: "/health", health: v1.Health{Status: v1.HealthOK, Connected: true}, want: http.StatusOK},
+		{name: "unhealthy", path: "/health", health: v1.Health{Status: v1.HealthUnhealthy, Problem: "disconnected: too many failures"}, want: http.StatusServiceUnavailable},
+		{name: "ready", path: "/ready", health: v1.Health{Status: v1.HealthOK, Connected: true}, want: http.StatusOK},
+		{name: "not ready", path: "/ready", health: v1.Health{Status: v1.HealthNotReady, Problem: "startup phase verifying"}, want: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The other endpoint answers the opposite, so a mixup shows
+			other := v1.Health{Status: v1.HealthOK}
+			if tt.health.Status == v1.HealthOK {
+				other.Status = v1.HealthUnhealthy
+			}
+			bot := &fakeBot{health: tt.health, readiness: other}
+			if tt.path == "/ready" {
+				bot.health, bot.readiness = other, tt.health
+			}
+			server := newTestServer(t, bot)
+
+			var got v1.Health
+			if status := get(t, server, tt.path, &got); status != tt.want {
+				t.Errorf("GET %s returned %d, want %d", tt.path, status, tt.want)
+			}
+			if !reflect.DeepEqual(got, tt.health) {
+				t.Errorf("GET %s = %+v, want %+v", tt.path, got, tt.health)
+			}
+		})
+	}
+}
+
+func TestStatusAddsBuild(t *testing.T) {
+	server := newTestServer(t, &fakeBot{stats: v1.Stats{SchemaVersion: v1.SchemaVersion, Translations: 3}})
+
+	var stats v1.Stats
+	if status := get(t, server, "/status", &stats); status != http.StatusOK {
+		t.Errorf("/status returned %d, want 200", status)
+	}
+	if stats.Translations != 3 {
+		t.Errorf("translations = %d, want the provider's 3", stats.Translations)
+	}
+	if stats.Build.GoVersion != runtime.Version() {
+		t.Errorf("build.go_version = %q, want %q", stats.Build.GoVersion, runtime.Version())
+	}
+}
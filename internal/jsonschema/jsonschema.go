@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema document node
+type Schema map[string]any
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generator builds JSON Schemas from Go types using their json and
+// description struct tags. Named struct types are emitted once under
+// definitions and referenced with $ref.
+type Generator struct {
+	Definitions map[string]Schema
+}
+
+// NewGenerator creates an empty generator
+func NewGenerator() *Generator {
+	return &Generator{Definitions: make(map[string]Schema)}
+}
+
+// Reflect returns the schema for t, registering any struct types it uses
+func (g *Generator) Reflect(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		if _, ok := g.Definitions[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.Definitions[t.Name()] = Schema{}
+			g.Definitions[t.Name()] = g.structSchema(t)
+		}
+		return Schema{"$ref": "#/definitions/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.Reflect(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.Reflect(t.Elem())}
+	default:
+		return Schema{}
+	}
+}
+
+// structSchema builds the object schema of a struct type
+func (g *Generator) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.Reflect(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			// Copy so a shared $ref node isn't mutated
+			described := Schema{"description": description}
+			for k, v := range property {
+				described[k] = v
+			}
+			property = described
+		}
+		properties[name] = property
+
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
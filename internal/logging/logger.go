@@ -0,0 +1,261 @@
+// Package logging is a small leveled logger that writes either the plain
+// text lines of the standard log package or structured JSON through
+// log/slog.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Level is the minimum severity a Logger writes
+type Level int
+
+const (
+	// LevelDebug includes raw event payloads and message text
+	LevelDebug Level = iota
+	// LevelInfo writes one line per processed message plus lifecycle events
+	LevelInfo
+	// LevelWarn writes only problems the bot recovers from, and errors
+	LevelWarn
+	// LevelError writes only errors
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String returns the level's name as accepted by ParseLevel
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// slogLevel is the log/slog equivalent of the level
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		name = "warn"
+	}
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+// Supported log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Field names shared by every package, so JSON logs can be queried the same
+// way whichever component wrote them
+const (
+	KeyChannel     = "channel"
+	KeyChannelName = "channel_name"
+	KeyUser        = "user"
+	KeyEventType   = "event_type"
+	KeyDurationMS  = "duration_ms"
+	KeyError       = "error"
+	KeyComponent   = "component"
+)
+
+// Channel is the channel field
+func Channel(id string) slog.Attr { return slog.String(KeyChannel, id) }
+
+// ChannelName is the channel name field, for channels whose name is known
+func ChannelName(name string) slog.Attr { return slog.String(KeyChannelName, name) }
+
+// User is the user field
+func User(id string) slog.Attr { return slog.String(KeyUser, id) }
+
+// EventType is the Slack event type field
+func EventType(t string) slog.Attr { return slog.String(KeyEventType, t) }
+
+// Duration is the duration field, in milliseconds
+func Duration(d time.Duration) slog.Attr {
+	return slog.Float64(KeyDurationMS, float64(d.Microseconds())/1000)
+}
+
+// Err is the error field
+func Err(err error) slog.Attr { return slog.String(KeyError, err.Error()) }
+
+// Logger writes messages at or above its level, as text lines to a standard
+// logger or as JSON records. Fields added with With only appear in JSON.
+type Logger struct {
+	out     *log.Logger
+	handler slog.Handler
+	level   Level
+	attrs   []slog.Attr
+}
+
+// New returns a Logger writing text lines to out at the given level
+func New(out *log.Logger, level Level) *Logger {
+	return &Logger{out: out, level: level}
+}
+
+// NewJSON returns a Logger writing one JSON object per line to stdout at the
+// given level
+func NewJSON(level Level) *Logger {
+	return &Logger{
+		handler: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource: true,
+			Level:     level.slogLevel(),
+		}),
+		level: level,
+	}
+}
+
+// With returns a Logger that adds the given fields to every message
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
+	with := *l
+	with.attrs = append(append([]slog.Attr(nil), l.attrs...), attrs...)
+	return &with
+}
+
+// contextKey carries a Logger with the fields of the event being handled
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, so code handling the
+// same event logs with the same fields
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the Logger carried by ctx, or fallback when there is
+// none
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// Level returns the minimum level the logger writes
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// Enabled reports whether messages at level are written, for skipping work
+// that only feeds a log line
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+// StdLogger returns a standard logger for libraries that take one. Its
+// lines are written at info level and tagged with component.
+func (l *Logger) StdLogger(component string) *log.Logger {
+	if l.handler == nil {
+		return log.New(l.out.Writer(), component+": ", l.out.Flags())
+	}
+	return log.New(lineWriter{l.With(slog.String(KeyComponent, component))}, "", 0)
+}
+
+// lineWriter turns each write of a standard logger into an info message.
+// The source reported is the caller of the standard logger's Printf.
+type lineWriter struct {
+	logger *Logger
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w.logger.output(LevelInfo, 3, strings.TrimRight(string(p), "\n"), nil)
+	return len(p), nil
+}
+
+// Debugf logs raw payloads and message text, for troubleshooting
+func (l *Logger) Debugf(format string, v ...any) {
+	l.logf(LevelDebug, format, v...)
+}
+
+// Infof logs normal operation
+func (l *Logger) Infof(format string, v ...any) {
+	l.logf(LevelInfo, format, v...)
+}
+
+// Warnf logs problems the bot works around
+func (l *Logger) Warnf(format string, v ...any) {
+	l.logf(LevelWarn, format, v...)
+}
+
+// Errorf logs failures
+func (l *Logger) Errorf(format string, v ...any) {
+	l.logf(LevelError, format, v...)
+}
+
+// Fatalf logs regardless of level and exits
+func (l *Logger) Fatalf(format string, v ...any) {
+	l.output(LevelError, 1, fmt.Sprintf(format, v...), v)
+	os.Exit(1)
+}
+
+func (l *Logger) logf(level Level, format string, v ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.output(level, 2, fmt.Sprintf(format, v...), v)
+}
+
+// output writes msg; skip is the number of frames between output and the
+// call site reported as the source. An error among args becomes the error
+// field.
+func (l *Logger) output(level Level, skip int, msg string, args []any) {
+	if l.handler == nil {
+		l.out.Output(skip+2, strings.ToUpper(level.String())+" "+msg)
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+	record := slog.NewRecord(time.Now(), level.slogLevel(), plainMessage(msg), pcs[0])
+	record.AddAttrs(l.attrs...)
+	if !l.hasAttr(KeyError) {
+		for _, arg := range args {
+			if err, ok := arg.(error); ok && err != nil {
+				record.AddAttrs(Err(err))
+				break
+			}
+		}
+	}
+	l.handler.Handle(context.Background(), record)
+}
+
+func (l *Logger) hasAttr(key string) bool {
+	for _, attr := range l.attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// plainMessage drops the emoji and padding the text lines start with, which
+// only get in the way of querying JSON logs
+func plainMessage(msg string) string {
+	return strings.TrimLeftFunc(msg, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import "sync"
+
+// CounterVec is a counter broken down by Labels. Every increment goes
+// through the label policy, so new metrics can't bypass cardinality limits.
+type CounterVec struct {
+	mu     sync.Mutex
+	policy *LabelPolicy
+	values map[Labels]uint64
+}
+
+// NewCounterVec creates a counter governed by policy
+func NewCounterVec(policy *LabelPolicy) *CounterVec {
+	return &CounterVec{
+		policy: policy,
+		values: make(map[Labels]uint64),
+	}
+}
+
+// Inc increments the counter for the given labels
+func (c *CounterVec) Inc(l Labels) {
+	l = c.policy.Apply(l)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[l]++
+}
+
+// Values returns a snapshot of the counter's series
+func (c *CounterVec) Values() map[Labels]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make(map[Labels]uint64, len(c.values))
+	for l, v := range c.values {
+		values[l] = v
+	}
+	return values
+}
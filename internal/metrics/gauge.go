@@ -0,0 +1,34 @@
+package metrics
+
+import "sync"
+
+// Gauge is a value without labels that can go up and down
+type Gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// NewGauge creates a gauge and registers it with the default registry
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	Default.register(name, help, "gauge", g.write)
+	return g
+}
+
+// Set replaces the gauge's value
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = v
+}
+
+// Value returns the current value
+func (g *Gauge) Value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+func (g *Gauge) write(w *textWriter, name string) {
+	w.sample(name, nil, g.Value())
+}
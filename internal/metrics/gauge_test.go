@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGaugeWritesLatestValue(t *testing.T) {
+	r := NewRegistry()
+	g := &Gauge{}
+	r.register("slackbot_test_limit", "A test gauge", "gauge", g.write)
+
+	g.Set(4)
+	g.Set(2)
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := "# HELP slackbot_test_limit A test gauge\n# TYPE slackbot_test_limit gauge\nslackbot_test_limit 2\n"
+	if out.String() != want {
+		t.Errorf("WriteText wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the default histogram buckets for request latencies,
+// in seconds
+var LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30}
+
+// Counter is a counter without labels
+type Counter struct {
+	mu    sync.Mutex
+	value uint64
+}
+
+// NewCounter creates a counter and registers it with the default registry
+func NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	Default.register(name, help, "counter", c.write)
+	return c
+}
+
+// Inc increments the counter
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value++
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+func (c *Counter) write(w *textWriter, name string) {
+	w.sample(name, nil, float64(c.Value()))
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewHistogram creates a histogram with the given upper bounds and
+// registers it with the default registry
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+	Default.register(name, help, "histogram", h.write)
+	return h
+}
+
+// Observe records a value
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveDuration records a duration in seconds
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+func (h *Histogram) write(w *textWriter, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		w.sample(name+"_bucket", []label{{"le", formatFloat(bound)}}, float64(cumulative))
+	}
+	w.sample(name+"_bucket", []label{{"le", "+Inf"}}, float64(h.count))
+	w.sample(name+"_sum", nil, h.sum)
+	w.sample(name+"_count", nil, float64(h.count))
+}
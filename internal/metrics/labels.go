@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+)
+
+// OtherLabel is the bucket for label values that are not tracked
+// individually
+const OtherLabel = "other"
+
+// Labels are the dimensions metrics may be broken down by. There is
+// deliberately no user dimension: per-user series are unbounded and would
+// turn the metrics endpoint into a who-said-what log.
+type Labels struct {
+	Channel string
+	Persona string
+	Model   string
+}
+
+// LabelPolicy governs metric label cardinality. Channels get their own
+// series only when explicitly configured, everything else is bucketed as
+// "other", and the total number of label combinations is capped.
+type LabelPolicy struct {
+	mu        sync.Mutex
+	channels  map[string]bool
+	maxSeries int
+	seen      map[Labels]bool
+	overflow  uint64
+}
+
+// NewLabelPolicy creates a policy that tracks the given channels
+// individually and at most maxSeries label combinations
+func NewLabelPolicy(configuredChannels []string, maxSeries int) *LabelPolicy {
+	channels := make(map[string]bool)
+	for _, id := range configuredChannels {
+		if id = strings.TrimSpace(id); id != "" {
+			channels[id] = true
+		}
+	}
+
+	return &LabelPolicy{
+		channels:  channels,
+		maxSeries: maxSeries,
+		seen:      make(map[Labels]bool),
+	}
+}
+
+// Apply returns the labels a metric should actually be recorded with. Once
+// the cap is reached, new combinations are collapsed into a single overflow
+// series and counted.
+func (p *LabelPolicy) Apply(l Labels) Labels {
+	if !p.channels[l.Channel] {
+		l.Channel = OtherLabel
+	}
+	if l.Persona == "" {
+		l.Persona = OtherLabel
+	}
+	if l.Model == "" {
+		l.Model = OtherLabel
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.seen[l] {
+		return l
+	}
+	if len(p.seen) >= p.maxSeries {
+		p.overflow++
+		return Labels{Channel: OtherLabel, Persona: OtherLabel, Model: OtherLabel}
+	}
+	p.seen[l] = true
+	return l
+}
+
+// Overflow returns how many times a label combination was collapsed
+// because the cap was reached
+func (p *LabelPolicy) Overflow() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.overflow
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import "testing"
+
+// Only configured channels get their own series, and empty labels are
+// bucketed as "other"
+func TestLabelPolicyGroupsChannels(t *testing.T) {
+	p := NewLabelPolicy([]string{"C0000001", " C0000002 ", ""}, 100)
+
+	tests := []struct {
+		in   Labels
+		want Labels
+	}{
+		{
+			in:   Labels{Channel: "C0000001", Persona: "genalpha", Model: "gpt-4"},
+			want: Labels{Channel: "C0000001", Persona: "genalpha", Model: "gpt-4"},
+		},
+		{
+			in:   Labels{Channel: "C0000002", Persona: "pirate", Model: "gpt-4"},
+			want: Labels{Channel: "C0000002", Persona: "pirate", Model: "gpt-4"},
+		},
+		{
+			in:   Labels{Channel: "C0000099", Persona: "genalpha", Model: "gpt-4"},
+			want: Labels{Channel: OtherLabel, Persona: "genalpha", Model: "gpt-4"},
+		},
+		{
+			in:   Labels{},
+			want: Labels{Channel: OtherLabel, Persona: OtherLabel, Model: OtherLabel},
+		},
+	}
+	for _, tt := range tests {
+		if got := p.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	if got := p.Overflow(); got != 0 {
+		t.Errorf("Overflow = %d, want 0", got)
+	}
+}
+
+// Past maxSeries, new combinations collapse into a single series and are
+// counted, while those seen before keep theirs
+func TestLabelPolicyCapsSeries(t *testing.T) {
+	p := NewLabelPolicy([]string{"C0000001"}, 2)
+	first := Labels{Channel: "C0000001", Persona: "genalpha", Model: "gpt-4"}
+	second := Labels{Channel: "C0000001", Persona: "pirate", Model: "gpt-4"}
+	overflow := Labels{Channel: OtherLabel, Persona: OtherLabel, Model: OtherLabel}
+
+	p.Apply(first)
+	p.Apply(second)
+
+	if got := p.Apply(Labels{Channel: "C0000001", Persona: "corporate", Model: "gpt-4"}); got != overflow {
+		t.Errorf("Apply past the cap = %+v, want %+v", got, overflow)
+	}
+	if got := p.Apply(Labels{Channel: "C0000001", Persona: "shakespeare", Model: "gpt-4o-mini"}); got != overflow {
+		t.Errorf("Apply past the cap = %+v, want %+v", got, overflow)
+	}
+	if got := p.Apply(first); got != first {
+		t.Errorf("Apply of a tracked series = %+v, want %+v", got, first)
+	}
+	if got := p.Apply(second); got != second {
+		t.Errorf("Apply of a tracked series = %+v, want %+v", got, second)
+	}
+
+	if got := p.Overflow(); got != 2 {
+		t.Errorf("Overflow = %d, want 2", got)
+	}
+}
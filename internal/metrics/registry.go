@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// Registry holds metrics by name and writes them in the Prometheus text
+// exposition format
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]registered
+}
+
+type registered struct {
+	help  string
+	kind  string
+	write func(w *textWriter, name string)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]registered)}
+}
+
+// register adds a metric, replacing any earlier one with the same name
+func (r *Registry) register(name, help, kind string, write func(w *textWriter, name string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name] = registered{help: help, kind: kind, write: write}
+}
+
+// RegisterCounterVec exposes a labeled counter under name, with only the
+// given label dimensions ("channel", "persona", "model")
+func (r *Registry) RegisterCounterVec(name, help string, c *CounterVec, dims ...string) {
+	r.register(name, help, "counter", func(w *textWriter, name string) {
+		// Series that differ only in hidden dimensions are summed
+		values := make(map[string]uint64)
+		keys := make(map[string][]label)
+		for l, v := range c.Values() {
+			labels := l.pairs(dims)
+			k := labelKey(labels)
+			values[k] += v
+			keys[k] = labels
+		}
+
+		sorted := make([]string, 0, len(values))
+		for k := range values {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			w.sample(name, keys[k], float64(values[k]))
+		}
+	})
+}
+
+// WriteText writes every metric in the Prometheus text format, sorted by
+// name
+func (r *Registry) WriteText(out io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make(map[string]registered, len(r.metrics))
+	for name, m := range r.metrics {
+		metrics[name] = m
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	w := &textWriter{w: bufio.NewWriter(out)}
+	for _, name := range names {
+		m := metrics[name]
+		w.printf("# HELP %s %s\n", name, m.help)
+		w.printf("# TYPE %s %s\n", name, m.kind)
+		m.write(w, name)
+	}
+	return w.flush()
+}
+
+// Handler serves the registry for Prometheus to scrape
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+type label struct {
+	name, value string
+}
+
+// pairs returns the label pairs for the given dimensions
+func (l Labels) pairs(dims []string) []label {
+	labels := make([]label, 0, len(dims))
+	for _, dim := range dims {
+		switch dim {
+		case "channel":
+			labels = append(labels, label{dim, l.Channel})
+		case "persona":
+			labels = append(labels, label{dim, l.Persona})
+		case "model":
+			labels = append(labels, label{dim, l.Model})
+		}
+	}
+	return labels
+}
+
+func labelKey(labels []label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.name)
+		b.WriteByte('=')
+		b.WriteString(l.value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// textWriter writes samples, remembering the first error
+type textWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *textWriter) printf(format string, args ...any) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.w, format, args...)
+	}
+}
+
+func (w *textWriter) sample(name string, labels []label, value float64) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l.name)
+			b.WriteString(`="`)
+			b.WriteString(labelValueEscaper.Replace(l.value))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+	w.printf("%s", b.String())
+}
+
+func (w *textWriter) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
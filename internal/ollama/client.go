@@ -0,0 +1,307 @@
+// Package ollama translates with a model served by a local Ollama server,
+// for running the bot without internet access.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/httpclient"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// maxResponseBytes caps how much of a response body is read
+const maxResponseBytes = 4 * 1024 * 1024
+
+// checkTimeout bounds the startup check of the model list
+const checkTimeout = 5 * time.Second
+
+// Client handles communication with an Ollama server. It implements
+// translate.Translator.
+type Client struct {
+	host        *url.URL
+	model       string
+	limits      translate.OutputLimits
+	maxAttempts int
+	client      *http.Client
+	logger      *logging.Logger
+}
+
+// Message is a single message of a chat request
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Options are the model parameters of a chat request
+type Options struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// ChatRequest represents a request to /api/chat. Streaming is always off,
+// so the reply comes back as a single object.
+type ChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  Options   `json:"options"`
+	// Format is a JSON schema the reply must follow
+	Format json.RawMessage `json:"format,omitempty"`
+}
+
+// ChatResponse represents a response from /api/chat
+type ChatResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+}
+
+// tagsResponse represents a response from /api/tags, the models the server
+// has
+type tagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// errorResponse is the body of an Ollama error
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a new Ollama client
+func New(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) (*Client, error) {
+	host, err := url.Parse(cfg.OllamaHost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OLLAMA_HOST: %w", err)
+	}
+
+	logger.Infof("Initializing Ollama client at %s with model: %s, timeout: %v",
+		host.Redacted(), cfg.OllamaModel, cfg.OllamaTimeout)
+
+	return &Client{
+		host:        host,
+		model:       cfg.OllamaModel,
+		limits:      translate.NewOutputLimits(cfg),
+		maxAttempts: cfg.OpenAIMaxAttempts,
+		client:      httpclient.WithTimeout(httpClient, cfg.OllamaTimeout),
+		logger:      logger,
+	}, nil
+}
+
+// loggerFor returns the logger carrying the fields of the event ctx belongs
+// to
+func (c *Client) loggerFor(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, c.logger)
+}
+
+// Model returns the model used for translations
+func (c *Client) Model() string {
+	return c.model
+}
+
+// CheckModel asks the server for its models and reports an error when it
+// can't be reached or doesn't have the configured one
+func (c *Client) CheckModel(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.host.JoinPath("api", "tags").String(), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reaching Ollama at %s: %w", c.host.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponse(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API error listing models: %s, status code: %d", body, resp.StatusCode)
+	}
+
+	var tags tagsResponse
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return fmt.Errorf("error unmarshaling model list: %w", err)
+	}
+	for _, model := range tags.Models {
+		// Models pulled without a tag are listed as :latest
+		if model.Name == c.model || model.Name == c.model+":latest" {
+			return nil
+		}
+	}
+	return errors.New(c.modelNotFound())
+}
+
+// Translate translates a message into the given style
+func (c *Client) Translate(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error) {
+	c.loggerFor(ctx).Debugf("Translating message to %s style for user: %s", req.Style.Name, req.Username)
+	c.loggerFor(ctx).Debugf("Original message: %s", req.Message)
+
+	result, err := translate.Translate(ctx, req, c.complete)
+	if err != nil {
+		return translate.TranslationResult{}, err
+	}
+
+	c.loggerFor(ctx).Debugf("Translation: %s", result.Text)
+	return result, nil
+}
+
+// VibeCheck summarizes the tone of a message as a single Gen Alpha line
+func (c *Client) VibeCheck(ctx context.Context, message, username string) (string, error) {
+	c.loggerFor(ctx).Debugf("Generating vibe check for user: %s", username)
+	return translate.VibeCheck(ctx, message, username, c.complete)
+}
+
+// Summarize returns a serious TL;DR of an announcement and its translation
+// into the given style, from a single structured call
+func (c *Client) Summarize(ctx context.Context, style translate.Style, message, username string) (translate.Announcement, error) {
+	c.loggerFor(ctx).Debugf("Summarizing announcement in %s style for user: %s", style.Name, username)
+	return translate.Summarize(ctx, style, message, username, c.complete)
+}
+
+// Complete sends a system and user prompt of the caller's own and returns
+// the reply
+func (c *Client) Complete(ctx context.Context, system, user string, opts ...translate.CompleteOption) (string, error) {
+	return c.complete(ctx, translate.NewCompletion(system, user, opts...))
+}
+
+// complete sends a chat request, retrying on load, and returns the reply. A
+// completion with a schema passes it as the reply's format.
+func (c *Client) complete(ctx context.Context, completion translate.Completion) (string, error) {
+	c.loggerFor(ctx).Debugf("Generated prompt for Ollama: %s", completion.User)
+
+	request := ChatRequest{
+		Model: c.model,
+		Messages: []Message{
+			{Role: "system", Content: completion.System},
+			{Role: "user", Content: completion.User},
+		},
+		Options: Options{
+			Temperature: completion.Temperature,
+		},
+	}
+	if completion.Schema != nil {
+		request.Format = completion.Schema.Schema
+	}
+
+	return c.limits.Complete(ctx, c.loggerFor(ctx), "Ollama", completion.MaxTokens, func(maxTokens int) (string, bool, error) {
+		request.Options.NumPredict = maxTokens
+		return c.completeRequest(ctx, request)
+	})
+}
+
+// completeRequest sends a prepared chat request, retrying on load, and
+// returns the reply and whether it was cut off by num_predict
+func (c *Client) completeRequest(ctx context.Context, request ChatRequest) (string, bool, error) {
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return "", false, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	var body []byte
+	err = translate.Retry(ctx, c.loggerFor(ctx), "Ollama", c.maxAttempts, func() error {
+		var err error
+		body, err = c.send(ctx, jsonBody)
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	var response ChatResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", false, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	translate.AddUsage(ctx, c.model, response.PromptEvalCount, response.EvalCount)
+
+	content := strings.TrimSpace(response.Message.Content)
+	if content == "" {
+		return "", false, fmt.Errorf("no content returned from Ollama")
+	}
+	return content, response.DoneReason == "length", nil
+}
+
+// send makes a single chat request and returns the body of a successful
+// response
+func (c *Client) send(ctx context.Context, jsonBody []byte) ([]byte, error) {
+	c.loggerFor(ctx).Debugf("Sending request to Ollama using model: %s", c.model)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.host.JoinPath("api", "chat").String(), bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	startTime := time.Now()
+	resp, err := c.client.Do(req)
+	duration := time.Since(startTime)
+	requestDuration.ObserveDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	logger := c.loggerFor(ctx).With(logging.Duration(duration))
+	logger.Debugf("Received response from Ollama in %v, status code: %d", duration, resp.StatusCode)
+
+	body, err := readResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &translate.StatusError{
+			Provider:   "Ollama",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: translate.ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		// A missing model is a setup problem; as a 404 it isn't retried
+		var errResp errorResponse
+		if resp.StatusCode == http.StatusNotFound && json.Unmarshal(body, &errResp) == nil &&
+			strings.Contains(errResp.Error, "not found") {
+			return nil, fmt.Errorf("%s: %w", c.modelNotFound(), statusErr)
+		}
+		return nil, statusErr
+	}
+	return body, nil
+}
+
+// modelNotFound describes a model the server doesn't have, and how to get
+// it
+func (c *Client) modelNotFound() string {
+	return fmt.Sprintf("Ollama at %s has no model %q, run `ollama pull %s` or set OLLAMA_MODEL",
+		c.host.Redacted(), c.model, c.model)
+}
+
+// readResponse reads a response body, failing instead of buffering more
+// than maxResponseBytes
+func readResponse(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxResponseBytes)
+	}
+	return body, nil
+}
@@ -0,0 +1,6 @@
+package ollama
+
+import "github.com/user/slack-bot-api/internal/metrics"
+
+var requestDuration = metrics.NewHistogram("slackbot_ollama_request_duration_seconds",
+	"Latency of single Ollama requests, retries counted separately", metrics.LatencyBuckets)
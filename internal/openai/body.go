@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxResponseBytes caps how much of an OpenAI response body is read. Chat
+// completions are a few KB; anything near this is a misbehaving proxy.
+const maxResponseBytes = 4 * 1024 * 1024
+
+// maxPooledBufferBytes keeps unusually large request buffers out of the
+// pool so one huge prompt doesn't pin its memory forever
+const maxPooledBufferBytes = 1024 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferBytes {
+		bufferPool.Put(buf)
+	}
+}
+
+// encodeRequest marshals a request body into a pooled buffer, gzipping it
+// when compress is set. The caller returns the buffer with putBuffer.
+func encodeRequest(body any, compress bool) (*bytes.Buffer, error) {
+	jsonBuf := getBuffer()
+	if err := json.NewEncoder(jsonBuf).Encode(body); err != nil {
+		putBuffer(jsonBuf)
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	if !compress {
+		return jsonBuf, nil
+	}
+	defer putBuffer(jsonBuf)
+
+	gzipBuf := getBuffer()
+	zw := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(zw)
+	zw.Reset(gzipBuf)
+
+	if _, err := zw.Write(jsonBuf.Bytes()); err != nil {
+		putBuffer(gzipBuf)
+		return nil, fmt.Errorf("error compressing request: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		putBuffer(gzipBuf)
+		return nil, fmt.Errorf("error compressing request: %w", err)
+	}
+	return gzipBuf, nil
+}
+
+// readResponse reads a response body, failing instead of buffering more
+// than maxResponseBytes
+func readResponse(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("OpenAI response body exceeds %d bytes", maxResponseBytes)
+	}
+	return body, nil
+}
@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+)
+
+// BenchmarkEncodeRequest encodes a request with a long prompt, the case
+// pooling the buffers and gzip writers is for
+func BenchmarkEncodeRequest(b *testing.B) {
+	prompt := strings.Repeat("Quarterly planning sync moved to Thursday, please update your calendars. ", 1000)
+	request := ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []Message{
+			{Role: "system", Content: "Translate the message into Gen Alpha slang."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens: 1024,
+	}
+
+	for _, compress := range []bool{false, true} {
+		name := "plain"
+		if compress {
+			name = "gzip"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(prompt)))
+			for i := 0; i < b.N; i++ {
+				buf, err := encodeRequest(request, compress)
+				if err != nil {
+					b.Fatal(err)
+				}
+				putBuffer(buf)
+			}
+		})
+	}
+}
@@ -5,24 +5,53 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/httpclient"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/translate"
 )
 
-// Client handles communication with the OpenAI API
+// Client handles communication with the OpenAI API, or any API compatible
+// with its chat completions. It implements translate.Translator.
 type Client struct {
-	apiKey    string
-	model     string
-	maxTokens int
-	baseURL   string
-	client    *http.Client
-	logger    *log.Logger
-	debug     bool
-	logs      bool
+	apiKey string
+	model  string
+	// fallbackModel answers when model is out of quota or doesn't exist
+	fallbackModel    string
+	limits           translate.OutputLimits
+	maxAttempts      int
+	compressRequests bool
+	baseURL          string
+	// moderationURL scores translations, only set for OpenAI itself
+	moderationURL string
+	// organization and project bill requests to an OpenAI organization
+	// and project, sent only when set
+	organization string
+	project      string
+	// apiKeyHeader carries the API key; Authorization sends it as a
+	// bearer token, any other header as it is
+	apiKeyHeader string
+	// Sampling parameters, nil when unset; temperature overrides the
+	// prompts' own
+	temperature      *float64
+	topP             *float64
+	frequencyPenalty *float64
+	presencePenalty  *float64
+	// candidates is how many translations to ask for at once, one picked
+	// with pick and the others kept for re-rolls
+	candidates int
+	pick       func(n int) int
+	// stream streams translations, for the bot to show as they grow
+	stream bool
+	client *http.Client
+	logger *logging.Logger
+	debug  bool
 }
 
 // Message represents a single message in the OpenAI chat completion request
@@ -31,148 +60,347 @@ type Message struct {
 	Content string `json:"content"`
 }
 
-// ChatCompletionRequest represents the request to the OpenAI API
+// ChatCompletionRequest represents the request to the OpenAI API. Unset
+// parameters are left out rather than sent as zeros, which would change
+// how the model samples.
 type ChatCompletionRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	MaxTokens   int       `json:"max_tokens"`
-	Temperature float64   `json:"temperature"`
+	Model            string          `json:"model"`
+	Messages         []Message       `json:"messages"`
+	MaxTokens        int             `json:"max_tokens,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
+	N                int             `json:"n,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *StreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
 }
 
 // ChatCompletionResponse represents the response from the OpenAI API
 type ChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Choices []struct {
-		Index        int `json:"index"`
-		Message      Message `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
+	ID      string          `json:"id"`
+	Object  string          `json:"object"`
+	Created int64           `json:"created"`
+	Choices []Choice        `json:"choices"`
+	Usage   completionUsage `json:"usage"`
+}
+
+// Choice is one of the replies of a chat completion response, several
+// with n > 1
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// completionUsage is the token usage reported with a response
+type completionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 // New creates a new OpenAI client
-func New(cfg *config.Config, logger *log.Logger) *Client {
-	if cfg.Logs {
-		logger.Printf("Initializing OpenAI client with model: %s, max tokens: %d", 
-			cfg.OpenAIModel, cfg.OpenAIMaxTokens)
+func New(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) *Client {
+	logger.Infof("Initializing OpenAI client with model: %s, max tokens: %d",
+		cfg.OpenAIModel, cfg.OpenAIMaxTokens)
+
+	c := newClient(cfg, httpClient, logger, cfg.OpenAIAPIKey, cfg.OpenAIModel, "https://api.openai.com/v1/chat/completions")
+	c.fallbackModel = cfg.OpenAIFallbackModel
+	c.moderationURL = "https://api.openai.com/v1/moderations"
+	c.organization = cfg.OpenAIOrgID
+	c.project = cfg.OpenAIProjectID
+	return c
+}
+
+// NewCompatible creates a client for an API compatible with OpenAI's chat
+// completions, like Azure OpenAI, OpenRouter or a local vLLM, at
+// LLM_BASE_URL. The API key is optional, and the retry and request
+// settings are shared with the OpenAI provider.
+func NewCompatible(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) (*Client, error) {
+	endpoint, err := url.Parse(cfg.LLMBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LLM_BASE_URL: %w", err)
 	}
-	
+	// Query parameters, like Azure's api-version, stay on the endpoint
+	endpoint = endpoint.JoinPath("chat", "completions")
+
+	logger.Infof("Initializing OpenAI-compatible client at %s with model: %s, max tokens: %d",
+		endpoint.Redacted(), cfg.LLMModel, cfg.OpenAIMaxTokens)
+
+	c := newClient(cfg, httpClient, logger, cfg.LLMAPIKey, cfg.LLMModel, endpoint.String())
+	c.apiKeyHeader = cfg.LLMAPIKeyHeader
+	return c, nil
+}
+
+// newClient creates a client for the chat completions endpoint at baseURL
+func newClient(cfg *config.Config, httpClient *http.Client, logger *logging.Logger, apiKey, model, baseURL string) *Client {
 	return &Client{
-		apiKey:    cfg.OpenAIAPIKey,
-		model:     cfg.OpenAIModel,
-		maxTokens: cfg.OpenAIMaxTokens,
-		baseURL:   "https://api.openai.com/v1/chat/completions",
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		logger: logger,
-		debug:  cfg.Debug,
-		logs:   cfg.Logs,
-	}
-}
-
-// TranslateToGenAlpha translates a message to Gen Alpha slang
-func (c *Client) TranslateToGenAlpha(ctx context.Context, message, username string) (string, error) {
-	if c.logs {
-		c.logger.Printf("Translating message to Gen Alpha slang for user: %s", username)
-		c.logger.Printf("Original message: %s", message)
-	}
-	
-	// Create the request to OpenAI
-	prompt := fmt.Sprintf(
-		"Translate the following message to Gen Alpha slang/language (TikTok style, with emojis, internet abbreviations, and current youth trends). " +
-		"Make it humorous but keep the original meaning. The message is from %s: \"%s\"", 
-		username, message)
-	
-	if c.logs {
-		c.logger.Printf("Generated prompt for OpenAI: %s", prompt)
-	}
-	
-	messages := []Message{
-		{
-			Role:    "system",
-			Content: "You are a Gen Alpha language translator. Your job is to translate normal messages into Gen Alpha slang and expressions. Be creative, use current youth trends, emojis, and make it funny but still understandable.",
-		},
-		{
-			Role:    "user",
-			Content: prompt,
+		apiKey:           apiKey,
+		model:            model,
+		limits:           translate.NewOutputLimits(cfg),
+		maxAttempts:      cfg.OpenAIMaxAttempts,
+		compressRequests: cfg.OpenAICompressRequests,
+		baseURL:          baseURL,
+		apiKeyHeader:     "Authorization",
+		temperature:      cfg.OpenAITemperature,
+		topP:             cfg.OpenAITopP,
+		frequencyPenalty: cfg.OpenAIFrequencyPenalty,
+		presencePenalty:  cfg.OpenAIPresencePenalty,
+		candidates:       cfg.OpenAICandidates,
+		pick:             rand.Intn,
+		stream:           cfg.OpenAIStream,
+		client:           httpclient.WithTimeout(httpClient, cfg.OpenAITimeout),
+		logger:           logger,
+		debug:            cfg.Debug,
+	}
+}
+
+// loggerFor returns the logger carrying the fields of the event ctx belongs
+// to
+func (c *Client) loggerFor(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, c.logger)
+}
+
+// Model returns the model used for translations
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Translate translates a message into the given style
+func (c *Client) Translate(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error) {
+	c.loggerFor(ctx).Debugf("Translating message to %s style for user: %s", req.Style.Name, req.Username)
+	c.loggerFor(ctx).Debugf("Original message: %s", req.Message)
+
+	result, err := translate.Translate(ctx, req, c.complete)
+	if err != nil {
+		return translate.TranslationResult{}, err
+	}
+
+	c.loggerFor(ctx).Debugf("Successfully translated message to %s style", req.Style.Name)
+	c.loggerFor(ctx).Debugf("Translation: %s", result.Text)
+	return result, nil
+}
+
+// VibeCheck summarizes the tone of a message as a single Gen Alpha line
+func (c *Client) VibeCheck(ctx context.Context, message, username string) (string, error) {
+	c.loggerFor(ctx).Debugf("Generating vibe check for user: %s", username)
+	return translate.VibeCheck(ctx, message, username, c.complete)
+}
+
+// Summarize returns a serious TL;DR of an announcement and its translation
+// into the given style, from a single structured call
+func (c *Client) Summarize(ctx context.Context, style translate.Style, message, username string) (translate.Announcement, error) {
+	c.loggerFor(ctx).Debugf("Summarizing announcement in %s style for user: %s", style.Name, username)
+	return translate.Summarize(ctx, style, message, username, c.complete)
+}
+
+// Complete sends a system and user prompt of the caller's own and returns
+// the reply
+func (c *Client) Complete(ctx context.Context, system, user string, opts ...translate.CompleteOption) (string, error) {
+	return c.complete(ctx, translate.NewCompletion(system, user, opts...))
+}
+
+// complete sends a chat completion request and returns the content of the
+// first choice. A completion with a schema asks for structured output.
+func (c *Client) complete(ctx context.Context, completion translate.Completion) (string, error) {
+	c.loggerFor(ctx).Debugf("Generated prompt for OpenAI: %s", completion.User)
+
+	request := ChatCompletionRequest{
+		Model: c.model,
+		Messages: []Message{
+			{Role: "system", Content: completion.System},
+			{Role: "user", Content: completion.User},
 		},
+		Temperature:      &completion.Temperature,
+		TopP:             c.topP,
+		FrequencyPenalty: c.frequencyPenalty,
+		PresencePenalty:  c.presencePenalty,
+	}
+	if c.temperature != nil {
+		request.Temperature = c.temperature
+	}
+	if completion.Candidates && c.candidates > 1 {
+		request.N = c.candidates
+	}
+	if completion.Schema != nil {
+		request.ResponseFormat = &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchema{Name: completion.Schema.Name, Strict: true, Schema: completion.Schema.Schema},
+		}
 	}
 
-	requestBody := ChatCompletionRequest{
-		Model:       c.model,
-		Messages:    messages,
-		MaxTokens:   c.maxTokens,
-		Temperature: 0.7, // Slightly creative
+	// Translations are capped by their length, and retried when that cap
+	// cut them off
+	send := func(maxTokens int) (string, bool, error) {
+		request.MaxTokens = maxTokens
+		if c.stream && completion.Partials != nil {
+			return c.streamRequest(ctx, request, completion.Partials)
+		}
+		return c.completeRequest(ctx, request)
+	}
+	content, err := c.limits.Complete(ctx, c.loggerFor(ctx), "OpenAI", completion.MaxTokens, send)
+	if err == nil || c.fallbackModel == "" || !modelUnavailable(err) {
+		return content, err
 	}
 
-	// Convert request to JSON
-	jsonBody, err := json.Marshal(requestBody)
+	// The fallback model gets one go; when it fails too, the primary
+	// model's error is the one worth reporting
+	c.loggerFor(ctx).Warnf("⚠️ Model %s unavailable, falling back to %s: %v", c.model, c.fallbackModel, err)
+	fallbacks.Inc()
+	request.Model = c.fallbackModel
+	content, fallbackErr := c.limits.Complete(ctx, c.loggerFor(ctx), "OpenAI", completion.MaxTokens, send)
+	if fallbackErr != nil {
+		c.loggerFor(ctx).Errorf("❌ Fallback model %s failed too: %v", c.fallbackModel, fallbackErr)
+		return "", err
+	}
+	c.loggerFor(ctx).Infof("🔀 Request served by fallback model %s", c.fallbackModel)
+	return content, nil
+}
+
+// completeRequest sends a prepared chat completion request, retrying on
+// load, and returns the content of the first choice and whether it was cut
+// off by max_tokens
+func (c *Client) completeRequest(ctx context.Context, requestBody ChatCompletionRequest) (string, bool, error) {
+	// Convert request to JSON, reused across retries
+	jsonBody, err := encodeRequest(requestBody, c.compressRequests)
+	if err != nil {
+		return "", false, err
+	}
+	defer putBuffer(jsonBody)
+
+	c.loggerFor(ctx).Debugf("Requesting a completion from model: %s", requestBody.Model)
+	var body []byte
+	err = translate.Retry(ctx, c.loggerFor(ctx), "OpenAI", c.maxAttempts, func() error {
+		var err error
+		body, err = c.send(ctx, c.baseURL, jsonBody.Bytes())
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("error marshaling request: %w", err)
+		return "", false, err
 	}
 
-	if c.logs {
-		c.logger.Printf("Sending request to OpenAI API using model: %s", c.model)
+	response, err := parseCompletion(body)
+	if err != nil {
+		return "", false, err
+	}
+	// With n > 1 the completion tokens are those of all choices together
+	translate.AddUsage(ctx, requestBody.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens)
+
+	choice := c.pickChoice(ctx, response)
+	return choice.Message.Content, choice.FinishReason == "length", nil
+}
+
+// pickChoice returns one of a response's choices at random, offering the
+// others that are complete as candidates
+func (c *Client) pickChoice(ctx context.Context, response ChatCompletionResponse) Choice {
+	if len(response.Choices) == 1 {
+		return response.Choices[0]
 	}
+	return chooseChoice(ctx, response, c.pick(len(response.Choices)))
+}
+
+// chooseChoice returns the picked choice of a response, offering the
+// others that are complete as candidates
+func chooseChoice(ctx context.Context, response ChatCompletionResponse, picked int) Choice {
+	var others []string
+	for i, choice := range response.Choices {
+		if i != picked && choice.FinishReason != "length" && strings.TrimSpace(choice.Message.Content) != "" {
+			others = append(others, choice.Message.Content)
+		}
+	}
+	translate.OfferCandidates(ctx, others...)
+	return response.Choices[picked]
+}
+
+// send makes a single request to endpoint, chat completions or
+// moderations, and returns the body of a successful response
+func (c *Client) send(ctx context.Context, endpoint string, jsonBody []byte) ([]byte, error) {
+	resp, err := c.post(ctx, endpoint, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	return readResponse(resp.Body)
+}
+
+// post makes a single request to endpoint and returns the response if it
+// was successful, its body still to be read and closed by the caller
+func (c *Client) post(ctx context.Context, endpoint string, jsonBody []byte) (*http.Response, error) {
+	c.loggerFor(ctx).Debugf("Sending request to OpenAI API")
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if c.compressRequests {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	switch {
+	case c.apiKey == "":
+	case c.apiKeyHeader == "Authorization":
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	default:
+		req.Header.Set(c.apiKeyHeader, c.apiKey)
+	}
+	if c.organization != "" {
+		req.Header.Set("OpenAI-Organization", c.organization)
+	}
+	if c.project != "" {
+		req.Header.Set("OpenAI-Project", c.project)
+	}
 
 	// Make the request
 	startTime := time.Now()
-	if c.logs {
-		c.logger.Printf("Making API request to OpenAI at: %s", startTime.Format(time.RFC3339))
-	}
-	
+	c.loggerFor(ctx).Debugf("Making API request to OpenAI at: %s", startTime.Format(time.RFC3339))
+
 	resp, err := c.client.Do(req)
+	duration := time.Since(startTime)
+	requestDuration.ObserveDuration(duration)
 	if err != nil {
-		return "", fmt.Errorf("error making request to OpenAI: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if c.logs {
-		c.logger.Printf("Received response from OpenAI in %v", time.Since(startTime))
-		c.logger.Printf("Response status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("error making request to OpenAI: %w", err)
 	}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response body: %w", err)
-	}
+	logger := c.loggerFor(ctx).With(logging.Duration(duration))
+	logger.Debugf("Received response from OpenAI in %v", duration)
+	logger.Debugf("Response status code: %d", resp.StatusCode)
 
 	// Check for error status code
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OpenAI API error: %s, status code: %d", string(body), resp.StatusCode)
+		defer resp.Body.Close()
+		body, err := readResponse(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		// OpenAI support asks for the request ID
+		if requestID := resp.Header.Get("x-request-id"); requestID != "" {
+			logger.Debugf("OpenAI request ID of the failed request: %s", requestID)
+		}
+		return nil, newAPIError(resp, body)
 	}
 
+	return resp, nil
+}
+
+// parseCompletion parses a chat completion response with at least one
+// choice
+func parseCompletion(body []byte) (ChatCompletionResponse, error) {
 	// Unmarshal the response
 	var completionResponse ChatCompletionResponse
 	if err := json.Unmarshal(body, &completionResponse); err != nil {
-		return "", fmt.Errorf("error unmarshaling response: %w", err)
+		return ChatCompletionResponse{}, fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
 	// Check if we got any choices
 	if len(completionResponse.Choices) == 0 {
-		return "", fmt.Errorf("no completion choices returned from OpenAI")
-	}
-
-	translatedText := completionResponse.Choices[0].Message.Content
-	
-	if c.logs {
-		c.logger.Printf("Successfully translated message to Gen Alpha slang")
-		c.logger.Printf("Translation: %s", translatedText)
+		return ChatCompletionResponse{}, fmt.Errorf("no completion choices returned from OpenAI")
 	}
 
-	// Return the translated text
-	return translatedText, nil
-} 
\ No newline at end of file
+	return completionResponse, nil
+}
@@ -0,0 +1,264 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+func testLogger() *logging.Logger {
+	return logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+}
+
+// testConfig returns the settings of a client making a single attempt per
+// request
+func testConfig() *config.Config {
+	return &config.Config{
+		OpenAIAPIKey:      "sk-test",
+		OpenAIModel:       "gpt-4",
+		OpenAIMaxTokens:   256,
+		OpenAIMaxAttempts: 1,
+		OpenAITimeout:     5 * time.Second,
+	}
+}
+
+// newTestClient returns an OpenAI client sending its chat completions to
+// handler
+func newTestClient(t *testing.T, cfg *config.Config, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := New(cfg, server.Client(), testLogger())
+	c.baseURL = server.URL
+	return c
+}
+
+// writeCompletion answers with a chat completion of a single choice
+func writeCompletion(w http.ResponseWriter, content string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ChatCompletionResponse{
+		Choices: []Choice{{Message: Message{Role: "assistant", Content: content}, FinishReason: "stop"}},
+	})
+}
+
+// writeError answers with an error in OpenAI's envelope
+func writeError(w http.ResponseWriter, status int, errType, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"type": errType, "code": code, "message": message},
+	})
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+// The JSON body of chat completions sends the sampling parameters that are
+// configured, and leaves out the others
+func TestChatCompletionRequestBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		want      map[string]any
+	}{
+		{
+			name: "defaults",
+			want: map[string]any{"temperature": 0.7, "max_tokens": 256.0},
+		},
+		{
+			name: "temperature of 0 is sent",
+			configure: func(cfg *config.Config) {
+				cfg.OpenAITemperature = float(0)
+			},
+			want: map[string]any{"temperature": 0.0, "max_tokens": 256.0},
+		},
+		{
+			name: "all sampling parameters",
+			configure: func(cfg *config.Config) {
+				cfg.OpenAITemperature = float(1.2)
+				cfg.OpenAITopP = float(0.9)
+				cfg.OpenAIFrequencyPenalty = float(0.5)
+				cfg.OpenAIPresencePenalty = float(-0.5)
+				cfg.OpenAIMaxTokens = 100
+			},
+			want: map[string]any{
+				"temperature":       1.2,
+				"top_p":             0.9,
+				"frequency_penalty": 0.5,
+				"presence_penalty":  -0.5,
+				"max_tokens":        100.0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			bodies := make(chan map[string]any, 1)
+			c := newTestClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				bodies <- body
+				writeCompletion(w, "ok")
+			})
+
+			if _, err := c.Complete(context.Background(), "system", "user"); err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			body := <-bodies
+
+			// model and messages are always there, and checked elsewhere
+			delete(body, "model")
+			delete(body, "messages")
+			if !reflect.DeepEqual(body, tt.want) {
+				t.Errorf("request body = %v, want %v", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatCompletionRequestOmitsUnset(t *testing.T) {
+	body, err := json.Marshal(ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"model":"gpt-4","messages":[{"role":"user","content":"hi"}]}`
+	if string(body) != want {
+		t.Errorf("json.Marshal = %s, want %s", body, want)
+	}
+}
+
+// OpenAI-Organization and OpenAI-Project are only sent when configured
+func TestOrganizationHeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		organization string
+		project      string
+	}{
+		{name: "neither"},
+		{name: "organization", organization: "org-test"},
+		{name: "project", project: "proj_test"},
+		{name: "both", organization: "org-test", project: "proj_test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.OpenAIOrgID = tt.organization
+			cfg.OpenAIProjectID = tt.project
+			headers := make(chan http.Header, 1)
+			c := newTestClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+				headers <- r.Header.Clone()
+				writeCompletion(w, "ok")
+			})
+
+			if _, err := c.Complete(context.Background(), "system", "user"); err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			header := <-headers
+
+			for name, want := range map[string]string{"OpenAI-Organization": tt.organization, "OpenAI-Project": tt.project} {
+				values, sent := header[http.CanonicalHeaderKey(name)]
+				switch {
+				case want == "" && sent:
+					t.Errorf("%s sent as %q, want it left out", name, values)
+				case want != "" && header.Get(name) != want:
+					t.Errorf("%s = %q, want %q", name, header.Get(name), want)
+				}
+			}
+			if got := header.Get("Authorization"); got != "Bearer sk-test" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer sk-test")
+			}
+		})
+	}
+}
+
+// translateBody translates message with a pirate style and returns the
+// raw body of the request, and the user prompt in it
+func translateBody(t *testing.T, message string) ([]byte, string) {
+	t.Helper()
+	bodies := make(chan []byte, 1)
+	c := newTestClient(t, testConfig(), func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request: %v", err)
+		}
+		bodies <- raw
+		writeCompletion(w, "ok")
+	})
+
+	style, _ := translate.NewStyles(&config.Config{}).Style("pirate")
+	if _, err := c.Translate(context.Background(), translate.TranslationRequest{Style: style, Message: message, Username: "alice"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	raw := <-bodies
+	var request ChatCompletionRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		t.Fatalf("request body isn't valid JSON: %v", err)
+	}
+	return raw, request.Messages[len(request.Messages)-1].Content
+}
+
+// Control characters and CRLF pasted into a message never reach the
+// request. encoding/json would only escape them.
+func TestTranslateStripsControlCharacters(t *testing.T) {
+	message, err := os.ReadFile("testdata/invalid_utf8.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utf8.Valid(message) {
+		t.Fatal("testdata/invalid_utf8.txt is valid UTF-8")
+	}
+
+	raw, prompt := translateBody(t, string(message))
+
+	for _, escaped := range []string{`\u0000`, `\u001b`, `\r`, "\x7f"} {
+		if strings.Contains(string(raw), escaped) {
+			t.Errorf("request body contains %q", escaped)
+		}
+	}
+	if !strings.Contains(prompt, "surrogate � here,\na stray continuation byte �, a cut-off emoji � and control[31m characters.") {
+		t.Errorf("prompt = %q, want the message with CRLF turned into LF and control characters stripped", prompt)
+	}
+}
+
+// A message over 32KB is cut, on a rune boundary
+func TestTranslateCapsLongInput(t *testing.T) {
+	const maxInputBytes = 32 * 1024
+	// One byte in front puts every rune boundary off the cap
+	message := "a" + strings.Repeat("🔥", maxInputBytes)
+
+	_, prompt := translateBody(t, message)
+
+	if strings.Contains(prompt, "�") {
+		t.Error("prompt contains U+FFFD, a rune was cut in half")
+	}
+	kept := strings.Count(prompt, "🔥")
+	if want := (maxInputBytes - 1) / len("🔥"); kept != want {
+		t.Errorf("prompt keeps %d emoji, want %d", kept, want)
+	}
+}
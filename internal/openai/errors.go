@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// APIError is an error response from the OpenAI API, parsed from its
+// {"error": {...}} envelope. Responses that aren't one, like an HTML page
+// from a proxy or an empty body, only have the status code.
+//
+// It unwraps to a translate.StatusError, which decides whether the request
+// is retried.
+type APIError struct {
+	StatusCode int
+	// Type, Code and Message are empty when the body isn't an OpenAI error
+	Type    string
+	Code    string
+	Message string
+
+	status *translate.StatusError
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("OpenAI API error, status code: %d", e.StatusCode)
+	}
+
+	detail := e.Code
+	if detail == "" {
+		detail = e.Type
+	}
+	if detail == "" {
+		return fmt.Sprintf("OpenAI API error: %s, status code: %d", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("OpenAI API error (%s): %s, status code: %d", detail, e.Message, e.StatusCode)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.status
+}
+
+// newAPIError parses a non-200 response
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var envelope struct {
+		Error *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+			// Mostly a string, sometimes null or a number
+			Code any `json:"code"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		apiErr.Type = envelope.Error.Type
+		apiErr.Message = envelope.Error.Message
+		if envelope.Error.Code != nil {
+			apiErr.Code = fmt.Sprint(envelope.Error.Code)
+		}
+	}
+
+	apiErr.status = &translate.StatusError{
+		Provider:   "OpenAI",
+		StatusCode: resp.StatusCode,
+		Body:       apiErr.Message,
+		RetryAfter: translate.ParseRetryAfter(resp.Header.Get("Retry-After")),
+		// An exhausted quota is a 429 too, but no wait fixes it
+		Permanent: apiErr.Code == codeInsufficientQuota,
+	}
+	return apiErr
+}
@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		want       APIError
+		message    string
+		retryable  bool
+		permanent  bool
+	}{
+		{
+			name:   "error envelope",
+			status: http.StatusBadRequest,
+			body:   `{"error": {"type": "invalid_request_error", "code": "context_length_exceeded", "message": "This model's maximum context length is 8192 tokens."}}`,
+			want: APIError{
+				StatusCode: http.StatusBadRequest,
+				Type:       "invalid_request_error",
+				Code:       "context_length_exceeded",
+				Message:    "This model's maximum context length is 8192 tokens.",
+			},
+			message: "OpenAI API error (context_length_exceeded): This model's maximum context length is 8192 tokens., status code: 400",
+		},
+		{
+			name:   "null code falls back to the type",
+			status: http.StatusTooManyRequests,
+			body:   `{"error": {"type": "requests", "code": null, "message": "Rate limit reached."}}`,
+			want: APIError{
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "requests",
+				Message:    "Rate limit reached.",
+			},
+			message:   "OpenAI API error (requests): Rate limit reached., status code: 429",
+			retryable: true,
+		},
+		{
+			name:   "numeric code",
+			status: http.StatusInternalServerError,
+			body:   `{"error": {"code": 500, "message": "The server had an error."}}`,
+			want: APIError{
+				StatusCode: http.StatusInternalServerError,
+				Code:       "500",
+				Message:    "The server had an error.",
+			},
+			message:   "OpenAI API error (500): The server had an error., status code: 500",
+			retryable: true,
+		},
+		{
+			name:   "exhausted quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error": {"type": "insufficient_quota", "code": "insufficient_quota", "message": "You exceeded your current quota."}}`,
+			want: APIError{
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "insufficient_quota",
+				Code:       "insufficient_quota",
+				Message:    "You exceeded your current quota.",
+			},
+			message:   "OpenAI API error (insufficient_quota): You exceeded your current quota., status code: 429",
+			permanent: true,
+		},
+		{
+			// Permanent whatever the status code: not retried
+			name:   "exhausted quota on a 5xx",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error": {"type": "insufficient_quota", "code": "insufficient_quota", "message": "You exceeded your current quota."}}`,
+			want: APIError{
+				StatusCode: http.StatusServiceUnavailable,
+				Type:       "insufficient_quota",
+				Code:       "insufficient_quota",
+				Message:    "You exceeded your current quota.",
+			},
+			message:   "OpenAI API error (insufficient_quota): You exceeded your current quota., status code: 503",
+			permanent: true,
+		},
+		{
+			name:       "HTML from a proxy",
+			status:     http.StatusBadGateway,
+			retryAfter: "7",
+			body:       "<html><body><h1>502 Bad Gateway</h1></body></html>",
+			want:       APIError{StatusCode: http.StatusBadGateway},
+			message:    "OpenAI API error, status code: 502",
+			retryable:  true,
+		},
+		{
+			name:    "empty body",
+			status:  http.StatusUnauthorized,
+			want:    APIError{StatusCode: http.StatusUnauthorized},
+			message: "OpenAI API error, status code: 401",
+		},
+		{
+			name:    "JSON without an error",
+			status:  http.StatusForbidden,
+			body:    `{"detail": "forbidden"}`,
+			want:    APIError{StatusCode: http.StatusForbidden},
+			message: "OpenAI API error, status code: 403",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			got := newAPIError(resp, []byte(tt.body))
+			if got.StatusCode != tt.want.StatusCode || got.Type != tt.want.Type || got.Code != tt.want.Code || got.Message != tt.want.Message {
+				t.Errorf("newAPIError = %+v, want %+v", *got, tt.want)
+			}
+			if got.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.message)
+			}
+
+			var statusErr *translate.StatusError
+			if !errors.As(got, &statusErr) {
+				t.Fatal("newAPIError doesn't unwrap to a translate.StatusError")
+			}
+			if statusErr.StatusCode != tt.status || statusErr.Permanent != tt.permanent {
+				t.Errorf("StatusError = %+v, want status code %d, permanent %v", *statusErr, tt.status, tt.permanent)
+			}
+			if retryable := errors.Is(got, translate.ErrOverloaded); retryable != tt.retryable {
+				t.Errorf("retryable = %v, want %v", retryable, tt.retryable)
+			}
+			if tt.retryAfter != "" && statusErr.RetryAfter != 7*time.Second {
+				t.Errorf("RetryAfter = %v, want 7s", statusErr.RetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package openai
+
+import "errors"
+
+// Error codes of failures that another model may not have
+const (
+	codeInsufficientQuota = "insufficient_quota"
+	codeModelNotFound     = "model_not_found"
+)
+
+// modelUnavailable reports whether a request failed because of the model
+// itself, an exhausted quota or a model that doesn't exist, which retrying
+// won't fix but the fallback model might
+func modelUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == codeInsufficientQuota || apiErr.Code == codeModelNotFound
+}
@@ -0,0 +1,139 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fallbackServer answers requests for models with an error, and any other
+// model with a completion naming it, recording the models asked for
+type fallbackServer struct {
+	mu     sync.Mutex
+	models []string
+	fail   map[string]func(w http.ResponseWriter)
+}
+
+func (s *fallbackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.models = append(s.models, request.Model)
+	s.mu.Unlock()
+
+	if fail, ok := s.fail[request.Model]; ok {
+		fail(w)
+		return
+	}
+	writeCompletion(w, "served by "+request.Model)
+}
+
+func (s *fallbackServer) requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.models...)
+}
+
+func quotaExceeded(w http.ResponseWriter) {
+	writeError(w, http.StatusTooManyRequests, "insufficient_quota", codeInsufficientQuota, "You exceeded your current quota.")
+}
+
+func modelNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "invalid_request_error", codeModelNotFound, "The model `gpt-4` does not exist.")
+}
+
+func TestFallbackModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		fail   map[string]func(w http.ResponseWriter)
+		want   string
+		code   string
+		models []string
+	}{
+		{
+			name:   "primary model answers",
+			want:   "served by gpt-4",
+			models: []string{"gpt-4"},
+		},
+		{
+			name:   "insufficient quota moves to the fallback model",
+			fail:   map[string]func(w http.ResponseWriter){"gpt-4": quotaExceeded},
+			want:   "served by gpt-4o-mini",
+			models: []string{"gpt-4", "gpt-4o-mini"},
+		},
+		{
+			name:   "model not found moves to the fallback model",
+			fail:   map[string]func(w http.ResponseWriter){"gpt-4": modelNotFound},
+			want:   "served by gpt-4o-mini",
+			models: []string{"gpt-4", "gpt-4o-mini"},
+		},
+		{
+			name: "fallback model failing too reports the original error",
+			fail: map[string]func(w http.ResponseWriter){
+				"gpt-4":       modelNotFound,
+				"gpt-4o-mini": quotaExceeded,
+			},
+			code:   codeModelNotFound,
+			models: []string{"gpt-4", "gpt-4o-mini"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fallbackServer{fail: tt.fail}
+			cfg := testConfig()
+			// Attempts to spare show that the primary model's failure
+			// isn't retried before falling back
+			cfg.OpenAIMaxAttempts = 3
+			cfg.OpenAIFallbackModel = "gpt-4o-mini"
+			c := newTestClient(t, cfg, server.ServeHTTP)
+
+			before := fallbacks.Value()
+			got, err := c.Complete(context.Background(), "system", "user")
+
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Complete: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("Complete = %q, want %q", got, tt.want)
+				}
+			} else {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.code {
+					t.Fatalf("Complete error = %v, want an APIError with code %q", err, tt.code)
+				}
+			}
+
+			if models := server.requested(); !reflect.DeepEqual(models, tt.models) {
+				t.Errorf("requested models = %q, want %q", models, tt.models)
+			}
+			wantFallbacks := uint64(len(tt.models) - 1)
+			if got := fallbacks.Value() - before; got != wantFallbacks {
+				t.Errorf("fallbacks = %d, want %d", got, wantFallbacks)
+			}
+		})
+	}
+}
+
+// Without OPENAI_FALLBACK_MODEL a missing model is reported as it is
+func TestNoFallbackModel(t *testing.T) {
+	server := &fallbackServer{fail: map[string]func(w http.ResponseWriter){"gpt-4": modelNotFound}}
+	c := newTestClient(t, testConfig(), server.ServeHTTP)
+
+	_, err := c.Complete(context.Background(), "system", "user")
+	if !modelUnavailable(err) {
+		t.Fatalf("Complete error = %v, want model_not_found", err)
+	}
+	if models := server.requested(); !reflect.DeepEqual(models, []string{"gpt-4"}) {
+		t.Errorf("requested models = %q, want only gpt-4", models)
+	}
+}
@@ -0,0 +1,9 @@
+package openai
+
+import "github.com/user/slack-bot-api/internal/metrics"
+
+var requestDuration = metrics.NewHistogram("slackbot_openai_request_duration_seconds",
+	"Latency of single OpenAI requests, retries counted separately", metrics.LatencyBuckets)
+
+var fallbacks = metrics.NewCounter("slackbot_openai_fallbacks_total",
+	"Requests sent to OPENAI_FALLBACK_MODEL after the primary model failed")
@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// moderationModel is the model scoring translations
+const moderationModel = "omni-moderation-latest"
+
+// moderationRequest is the body of a moderations request
+type moderationRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// moderationResponse is the part of a moderations response the bot reads
+type moderationResponse struct {
+	Results []struct {
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// Moderate scores text in each of OpenAI's moderation categories. It's
+// made once, without retries: a moderation outage shouldn't hold up
+// translations for long.
+func (c *Client) Moderate(ctx context.Context, text string) (map[string]float64, error) {
+	if c.moderationURL == "" {
+		return nil, errors.New("moderation is only available from OpenAI")
+	}
+
+	jsonBody, err := encodeRequest(moderationRequest{Model: moderationModel, Input: text}, c.compressRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer putBuffer(jsonBody)
+
+	body, err := c.send(ctx, c.moderationURL, jsonBody.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error moderating translation: %w", err)
+	}
+
+	var response moderationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling moderation response: %w", err)
+	}
+	if len(response.Results) == 0 {
+		return nil, errors.New("no moderation results returned from OpenAI")
+	}
+	return response.Results[0].CategoryScores, nil
+}
@@ -0,0 +1,171 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/threadctx"
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// StreamOptions asks for the token usage in the last chunk of a stream
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatCompletionChunk is one server-sent event of a streamed chat
+// completion
+type chatCompletionChunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *completionUsage `json:"usage"`
+	// Error is sent instead of a chunk when the completion fails midway
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// errStreamEnded is a stream closed before its [DONE] event
+var errStreamEnded = errors.New("stream ended early")
+
+// streamRequest sends a prepared chat completion request with stream set,
+// retrying on load, and calls partials with the first choice's content as
+// it grows. It returns that choice's content and whether it was cut off by
+// max_tokens. A stream that breaks off after some content came keeps that
+// content, ending in an ellipsis, rather than failing.
+func (c *Client) streamRequest(ctx context.Context, requestBody ChatCompletionRequest, partials func(string)) (string, bool, error) {
+	requestBody.Stream = true
+	requestBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+
+	// Convert request to JSON, reused across retries
+	jsonBody, err := encodeRequest(requestBody, c.compressRequests)
+	if err != nil {
+		return "", false, err
+	}
+	defer putBuffer(jsonBody)
+
+	c.loggerFor(ctx).Debugf("Streaming a completion from model: %s", requestBody.Model)
+	var response ChatCompletionResponse
+	err = translate.Retry(ctx, c.loggerFor(ctx), "OpenAI", c.maxAttempts, func() error {
+		resp, err := c.post(ctx, c.baseURL, jsonBody.Bytes())
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		response, err = readStream(resp.Body, max(requestBody.N, 1), partials)
+		if err == nil {
+			return nil
+		}
+		if len(response.Choices) == 0 || strings.TrimSpace(response.Choices[0].Message.Content) == "" {
+			return err
+		}
+		c.loggerFor(ctx).Warnf("⚠️ OpenAI stream broke off, keeping what came: %v", err)
+		// The other choices broke off too, and aren't worth re-rolling to
+		response.Choices = response.Choices[:1]
+		response.Choices[0].Message.Content = strings.TrimRight(response.Choices[0].Message.Content, " \n") + "…"
+		response.Choices[0].FinishReason = ""
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	// The usage comes with the last chunk, which a broken stream or an
+	// OpenAI-compatible API may not send, so it's estimated then
+	usage := response.Usage
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		for _, message := range requestBody.Messages {
+			usage.PromptTokens += threadctx.EstimateTokens(message.Content)
+		}
+		for _, choice := range response.Choices {
+			usage.CompletionTokens += threadctx.EstimateTokens(choice.Message.Content)
+		}
+	}
+	translate.AddUsage(ctx, requestBody.Model, usage.PromptTokens, usage.CompletionTokens)
+
+	// The first choice is the one shown while streaming
+	choice := chooseChoice(ctx, response, 0)
+	return choice.Message.Content, choice.FinishReason == "length", nil
+}
+
+// readStream reads the server-sent events of a streamed chat completion
+// with n choices into a response, calling partials with the first
+// choice's content whenever it grows. On error, the response holds what
+// was read until then.
+func readStream(body io.Reader, n int, partials func(string)) (ChatCompletionResponse, error) {
+	var response ChatCompletionResponse
+	var contents []*strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseBytes)
+	done := false
+	var err error
+	for scanner.Scan() {
+		// Only data lines matter; blank lines end events, and comments
+		// keep the connection alive
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk chatCompletionChunk
+		if err = json.Unmarshal([]byte(data), &chunk); err != nil {
+			err = fmt.Errorf("error unmarshaling stream chunk: %w", err)
+			break
+		}
+		if chunk.Error != nil {
+			err = fmt.Errorf("OpenAI stream failed: %s", chunk.Error.Message)
+			break
+		}
+		if chunk.Usage != nil {
+			response.Usage = *chunk.Usage
+		}
+
+		for _, delta := range chunk.Choices {
+			if delta.Index < 0 || delta.Index >= n {
+				continue
+			}
+			for len(response.Choices) <= delta.Index {
+				response.Choices = append(response.Choices, Choice{Index: len(response.Choices)})
+				contents = append(contents, new(strings.Builder))
+			}
+			contents[delta.Index].WriteString(delta.Delta.Content)
+			if delta.FinishReason != nil {
+				response.Choices[delta.Index].FinishReason = *delta.FinishReason
+			}
+			if delta.Index == 0 && delta.Delta.Content != "" {
+				partials(contents[0].String())
+			}
+		}
+	}
+
+	for i := range response.Choices {
+		response.Choices[i].Message = Message{Role: "assistant", Content: contents[i].String()}
+	}
+	switch {
+	case err != nil:
+	case scanner.Err() != nil:
+		err = fmt.Errorf("error reading stream: %w", scanner.Err())
+	case !done:
+		err = errStreamEnded
+	case len(response.Choices) == 0:
+		err = fmt.Errorf("no completion choices returned from OpenAI")
+	}
+	return response, err
+}
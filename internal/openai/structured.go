@@ -0,0 +1,17 @@
+package openai
+
+import "encoding/json"
+
+// ResponseFormat asks the model for output matching a JSON schema
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is a named schema for structured outputs. With Strict the
+// model is constrained to the schema exactly.
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
+}
@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// ActionHandler handles a click on a Block Kit button. It runs after the
+// interaction has been acked.
+type ActionHandler func(ctx context.Context, callback slack.InteractionCallback, action *slack.BlockAction)
+
+// HandleAction registers the handler for buttons with the given action ID.
+// Handlers must be registered before Start.
+func (c *Client) HandleAction(actionID string, handler ActionHandler) {
+	c.actionHandlers[actionID] = handler
+}
+
+// handleBlockActions dispatches button clicks to their registered handlers
+func (c *Client) handleBlockActions(ctx context.Context, callback slack.InteractionCallback) {
+	for _, action := range callback.ActionCallback.BlockActions {
+		handler, ok := c.actionHandlers[action.ActionID]
+		if !ok {
+			c.loggerFor(ctx).Debugf("ℹ️ Ignoring block action with unknown action ID: %s", action.ActionID)
+			continue
+		}
+
+		c.loggerFor(ctx).Debugf("🖱️ Block action %s clicked by %s", action.ActionID, callback.User.ID)
+		handler(ctx, callback, action)
+	}
+}
+
+// PostEphemeralBlocks posts a Block Kit message to a channel that only the
+// given user can see. text is the notification and screen-reader fallback.
+func (c *Client) PostEphemeralBlocks(ctx context.Context, channelID, userID, text string, blocks ...slack.Block) error {
+	c.loggerFor(ctx).Debugf("Posting ephemeral blocks to user %s in channel: %s", userID, channelID)
+
+	_, err := c.api.PostEphemeralContext(ctx, channelID, userID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	return err
+}
+
+// ReplaceInteractiveMessage replaces the message an interaction came from,
+// including ephemeral ones, with plain text
+func (c *Client) ReplaceInteractiveMessage(ctx context.Context, responseURL, text string) error {
+	_, _, err := c.api.PostMessageContext(ctx, "",
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionReplaceOriginal(responseURL),
+	)
+	if err != nil {
+		return fmt.Errorf("error replacing interactive message: %w", err)
+	}
+	return nil
+}
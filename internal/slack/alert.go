@@ -0,0 +1,87 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// adminAlerts decides which failures are worth telling ADMIN_CHANNEL_ID
+// about: the first of each kind, then at most one per interval, counting
+// the ones in between
+type adminAlerts struct {
+	mu       sync.Mutex
+	interval time.Duration
+	seen     map[string]*alertSignature
+}
+
+// alertSignature tracks the alerts for one kind of failure
+type alertSignature struct {
+	lastAlert  time.Time
+	suppressed int
+}
+
+func newAdminAlerts(interval time.Duration) *adminAlerts {
+	return &adminAlerts{interval: interval, seen: make(map[string]*alertSignature)}
+}
+
+// numbers are left out of error signatures, so the same failure with a
+// different timestamp, request ID or duration counts as one
+var numbers = regexp.MustCompile(`[0-9]{4,}`)
+
+// admit reports whether a failure with signature should be alerted on
+// now, and if so how many times it happened since the last alert, this
+// time included
+func (a *adminAlerts) admit(signature string, now time.Time) (bool, int) {
+	signature = numbers.ReplaceAllString(signature, "#")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Signatures that went quiet are forgotten, so a long-running bot
+	// doesn't keep every error it ever saw
+	for key, seen := range a.seen {
+		if seen.suppressed == 0 && now.Sub(seen.lastAlert) >= a.interval {
+			delete(a.seen, key)
+		}
+	}
+
+	seen, ok := a.seen[signature]
+	if !ok {
+		a.seen[signature] = &alertSignature{lastAlert: now}
+		return true, 1
+	}
+	if now.Sub(seen.lastAlert) < a.interval {
+		seen.suppressed++
+		return false, 0
+	}
+	count := seen.suppressed + 1
+	seen.lastAlert, seen.suppressed = now, 0
+	return true, count
+}
+
+// alertAdmins posts a failure to process a message to ADMIN_CHANNEL_ID,
+// unless the same failure was already reported within the alert interval
+func (c *Client) alertAdmins(ctx context.Context, what, channelID, userID string, err error) {
+	if c.adminChannel == "" {
+		return
+	}
+	send, count := c.alerts.admit(what+": "+err.Error(), time.Now())
+	if !send {
+		return
+	}
+
+	text := fmt.Sprintf("🚨 Error processing %s in <#%s>", what, channelID)
+	if userID != "" {
+		text += fmt.Sprintf(" from <@%s>", userID)
+	}
+	text += fmt.Sprintf(":\n```%s```", err)
+	if count > 1 {
+		text += fmt.Sprintf("\nThis happened %d times since the last alert.", count)
+	}
+	if _, _, err := c.PostMessage(ctx, c.adminChannel, text); err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error posting alert to %s: %v", c.adminChannel, err)
+	}
+}
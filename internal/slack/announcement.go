@@ -0,0 +1,39 @@
+package slack
+
+import (
+	"context"
+	"regexp"
+)
+
+// MessageTypeAnnouncement marks message events for announcements that get a
+// TL;DR and a translation in their thread, given by ThreadTimestamp
+const MessageTypeAnnouncement = "announcement"
+
+// broadcastPattern matches Slack's encoding of @channel, @here and
+// @everyone, with or without a label, e.g. <!here> or <!channel|channel>
+var broadcastPattern = regexp.MustCompile(`<!(channel|here|everyone)(\|[^>]*)?>`)
+
+// IsBroadcast reports whether message text notifies a whole channel
+func IsBroadcast(text string) bool {
+	return broadcastPattern.MatchString(text)
+}
+
+// IsAnnouncement reports whether a message was picked up as an announcement
+func IsAnnouncement(event *IncomingMessage) bool {
+	return event.Type == MessageTypeAnnouncement
+}
+
+// handleAnnouncement passes an @channel announcement in a channel with
+// announcement TL;DRs to the processor, whoever posted it
+func (c *Client) handleAnnouncement(ctx context.Context, event *IncomingMessage, processor Processor) {
+	c.loggerFor(ctx).Infof("📣 Announcement %s in %s, posting a TL;DR", event.Timestamp, event.Channel)
+	c.decisions.Step(event.Channel, event.Timestamp, "announcement", true, "@channel or @here used, target user filter skipped")
+
+	event.Type = MessageTypeAnnouncement
+	event.ThreadTimestamp = event.Timestamp
+
+	c.decisions.SetUser(event.Channel, event.Timestamp, event.User)
+	if err := c.processWithUser(ctx, processor, event); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "announcement", event.Channel, event.User, event.Timestamp, err)
+	}
+}
@@ -0,0 +1,154 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Checkpoints remembers the latest message seen in each channel, so the
+// messages sent while the bot was disconnected can be caught up on
+type Checkpoints interface {
+	MarkSeen(channel, ts string)
+	LastSeen() map[string]string
+}
+
+// EnableCatchUp makes the client translate the messages it missed after
+// every (re)connect, reading where each channel was left off from
+// checkpoints. It must be called before Start.
+func (c *Client) EnableCatchUp(checkpoints Checkpoints) {
+	c.checkpoints = checkpoints
+}
+
+// monitors reports whether messages in a channel are translated
+func (c *Client) monitors(channelID string) bool {
+	return (c.monitorAllChannels || c.channelIDs[channelID]) && !c.excludedChannels[channelID]
+}
+
+// TargetUsers returns how many users are translated, those listed in
+// SLACK_TARGET_USERS plus the members of SLACK_TARGET_USERGROUPS, or false
+// when everyone is
+func (c *Client) TargetUsers() (int, bool) {
+	if c.allTargetUsers {
+		return 0, false
+	}
+	c.targetUsersMu.RLock()
+	users := make(map[string]bool, len(c.targetUsers))
+	for user := range c.targetUsers {
+		// A name resolved to an ID is the same user
+		if _, ok := c.targetNames[user]; !ok {
+			users[user] = true
+		}
+	}
+	c.targetUsersMu.RUnlock()
+	c.usergroups.addMembers(users)
+	return len(users), true
+}
+
+// markSeen moves the checkpoint of a monitored channel to a new message.
+// Edits don't count, they carry the timestamp of the original message.
+func (c *Client) markSeen(event *IncomingMessage) {
+	if c.checkpoints == nil || IsEdit(event) || event.ChannelType == "im" || !c.monitors(event.Channel) {
+		return
+	}
+	c.checkpoints.MarkSeen(event.Channel, event.Timestamp)
+}
+
+// catchUp runs the messages each monitored channel got since its checkpoint
+// through the usual filters, oldest first. Messages older than
+// catchUpMaxAge are left alone. A catch-up still running when the next
+// hello arrives makes that one a no-op. Messages are handled with workCtx,
+// so one already started at shutdown gets to finish like events do.
+func (c *Client) catchUp(ctx, workCtx context.Context, processor Processor) {
+	if !c.catchingUp.CompareAndSwap(false, true) {
+		return
+	}
+	defer c.catchingUp.Store(false)
+
+	// Target users are resolved during startup
+	c.waitReady(ctx)
+
+	maxAge := slackTimestamp(time.Now().Add(-c.catchUpMaxAge))
+	for channelID, lastSeen := range c.checkpoints.LastSeen() {
+		if ctx.Err() != nil {
+			return
+		}
+		if !c.monitors(channelID) {
+			continue
+		}
+		c.catchUpChannel(ctx, workCtx, channelID, max(lastSeen, maxAge), processor)
+	}
+}
+
+// catchUpChannel handles the messages posted in a channel after oldest, at
+// most catchUpMaxMessages of them, the most recent ones
+func (c *Client) catchUpChannel(ctx, workCtx context.Context, channelID, oldest string, processor Processor) {
+	var history *slack.GetConversationHistoryResponse
+	err := c.withRateLimitRetry(ctx, func() error {
+		var err error
+		history, err = c.api.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Oldest:    oldest,
+			Limit:     c.catchUpMaxMessages,
+		})
+		return err
+	})
+	if err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error catching up on channel %s: %v", channelID, err)
+		return
+	}
+	if len(history.Messages) == 0 {
+		return
+	}
+
+	if history.HasMore {
+		c.loggerFor(ctx).Warnf("⚠️ More than %d messages missed in channel %s, catching up on the latest %d only",
+			c.catchUpMaxMessages, channelID, len(history.Messages))
+	}
+	c.loggerFor(ctx).Infof("⏪ Catching up on %d messages missed in channel %s", len(history.Messages), channelID)
+
+	// The history lists the newest message first
+	for i := len(history.Messages) - 1; i >= 0; i-- {
+		if ctx.Err() != nil || !c.inFlight.begin() {
+			return
+		}
+		c.catchUpMessage(workCtx, caughtUpMessage(channelID, history.Messages[i]), processor)
+	}
+}
+
+// catchUpMessage handles one missed message. A panic is contained to the
+// message, like for events, so the rest are still caught up on.
+func (c *Client) catchUpMessage(ctx context.Context, message *IncomingMessage, processor Processor) {
+	defer c.inFlight.end()
+	defer c.recoverEvent("caught up message")
+
+	c.decisions.Step(message.Channel, message.Timestamp, "caught up", true, "sent while the bot was disconnected")
+	c.handleMessage(ctx, message, processor)
+}
+
+// caughtUpMessage converts a message from a channel's history
+func caughtUpMessage(channelID string, message slack.Message) *IncomingMessage {
+	event := &IncomingMessage{
+		Channel:         channelID,
+		User:            message.User,
+		Text:            message.Text,
+		Timestamp:       message.Timestamp,
+		ThreadTimestamp: message.ThreadTimestamp,
+		ClientMsgID:     message.ClientMsgID,
+		SubType:         message.SubType,
+		BotID:           message.BotID,
+		Attachments:     message.Attachments,
+	}
+	if message.Edited != nil {
+		event.Edited = &slackevents.Edited{User: message.Edited.User, TimeStamp: message.Edited.Timestamp}
+	}
+	return event
+}
+
+// slackTimestamp formats a time as a Slack message timestamp
+func slackTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
@@ -0,0 +1,127 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// ChannelInfo is what the bot knows about a channel
+type ChannelInfo struct {
+	ID        string
+	Name      string
+	IsPrivate bool
+}
+
+// channelCache caches conversations.info results by channel ID, for as
+// long as the TTL. Failed lookups are cached too, so a private channel the
+// bot can't see is looked up once per TTL instead of for every message.
+type channelCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]channelCacheEntry
+}
+
+type channelCacheEntry struct {
+	info      ChannelInfo
+	err       error
+	fetchedAt time.Time
+}
+
+func newChannelCache(ttl time.Duration) *channelCache {
+	return &channelCache{ttl: ttl, entries: make(map[string]channelCacheEntry)}
+}
+
+// get returns the cached lookup of a channel, if present and fresh
+func (cc *channelCache) get(channelID string) (channelCacheEntry, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	entry, ok := cc.entries[channelID]
+	if ok && time.Since(entry.fetchedAt) >= cc.ttl {
+		delete(cc.entries, channelID)
+		ok = false
+	}
+	return entry, ok
+}
+
+// put caches the outcome of looking up a channel
+func (cc *channelCache) put(info ChannelInfo, err error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.entries[info.ID] = channelCacheEntry{info: info, err: err, fetchedAt: time.Now()}
+}
+
+// forget drops a channel, so it is looked up again on next use
+func (cc *channelCache) forget(channelID string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.entries, channelID)
+}
+
+// ChannelInfo looks up a channel's name and visibility, served from the
+// cache while it is fresh. Channels that can't be looked up, like private
+// channels the bot isn't in, are only logged the first time.
+func (c *Client) ChannelInfo(ctx context.Context, channelID string) (ChannelInfo, error) {
+	if entry, ok := c.channels.get(channelID); ok {
+		return entry.info, entry.err
+	}
+
+	var channel *slack.Channel
+	err := c.withRateLimitRetry(ctx, func() error {
+		var err error
+		channel, err = c.api.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("error getting channel info: %w", err)
+		// Shutting down isn't the channel's fault, don't remember it
+		if ctx.Err() == nil {
+			c.loggerFor(ctx).Debugf("Couldn't look up channel %s, referring to it by ID: %v", channelID, err)
+			c.channels.put(ChannelInfo{ID: channelID}, err)
+		}
+		return ChannelInfo{ID: channelID}, err
+	}
+
+	info := ChannelInfo{ID: channelID, Name: channel.Name, IsPrivate: channel.IsPrivate}
+	c.channels.put(info, nil)
+	return info, nil
+}
+
+// ChannelName returns the name of a channel, without the #, or its ID when
+// the bot can't look it up
+func (c *Client) ChannelName(ctx context.Context, channelID string) string {
+	info, err := c.ChannelInfo(ctx, channelID)
+	if err != nil || info.Name == "" {
+		return channelID
+	}
+	return info.Name
+}
+
+// cacheChannels remembers listed channels, so logs and ChannelInfo don't
+// have to look them up one at a time
+func (c *Client) cacheChannels(channels []slack.Channel) {
+	for _, channel := range channels {
+		c.channels.put(ChannelInfo{ID: channel.ID, Name: channel.Name, IsPrivate: channel.IsPrivate}, nil)
+	}
+}
+
+// channelLabel names a channel in logs: "#name (ID)", or the bare ID when
+// the name can't be looked up
+func (c *Client) channelLabel(ctx context.Context, channelID string) string {
+	if name := c.ChannelName(ctx, channelID); name != channelID {
+		return "#" + name + " (" + channelID + ")"
+	}
+	return channelID
+}
+
+// handleChannelRename forgets the old name of a renamed channel
+func (c *Client) handleChannelRename(ctx context.Context, channelID, name string) {
+	c.loggerFor(ctx).Infof("✏️ Channel %s renamed to #%s", channelID, name)
+	c.channels.forget(channelID)
+}
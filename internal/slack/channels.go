@@ -0,0 +1,320 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxChannelPages bounds conversation list pagination (200 per page)
+	maxChannelPages = 50
+
+	// channelPageDelay spaces out paginated calls to stay inside the low
+	// rate-limit tiers of the conversation methods
+	channelPageDelay = 500 * time.Millisecond
+
+	// maxRateLimitRetries bounds how often a single call is retried after
+	// Slack answers with a rate limit
+	maxRateLimitRetries = 5
+)
+
+// withRateLimitRetry runs call, sleeping and retrying when Slack reports a
+// rate limit
+func (c *Client) withRateLimitRetry(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) {
+			return err
+		}
+		if attempt >= maxRateLimitRetries {
+			rateLimitFailures.Inc()
+			c.loggerFor(ctx).Errorf("❌ Slack rate limit still hit after %d retries, giving up", attempt)
+			return err
+		}
+
+		rateLimitRetries.Inc()
+		c.loggerFor(ctx).Warnf("⏳ Slack rate limit hit, retrying in %s", rateLimited.RetryAfter)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rateLimited.RetryAfter):
+		}
+	}
+}
+
+// memberChannels lists every public and private channel the bot is a member
+// of, following pagination cursors
+func (c *Client) memberChannels(ctx context.Context) ([]slack.Channel, error) {
+	return c.listChannels(ctx, func(cursor string) ([]slack.Channel, string, error) {
+		return c.api.GetConversationsForUserContext(ctx, &slack.GetConversationsForUserParameters{
+			Types:           []string{"public_channel", "private_channel"},
+			Cursor:          cursor,
+			Limit:           200,
+			ExcludeArchived: true,
+		})
+	})
+}
+
+// workspaceChannels lists every public and private channel in the
+// workspace the bot can see, following pagination cursors
+func (c *Client) workspaceChannels(ctx context.Context) ([]slack.Channel, error) {
+	return c.listChannels(ctx, func(cursor string) ([]slack.Channel, string, error) {
+		return c.api.GetConversationsContext(ctx, &slack.GetConversationsParameters{
+			Types:           []string{"public_channel", "private_channel"},
+			Cursor:          cursor,
+			Limit:           200,
+			ExcludeArchived: true,
+		})
+	})
+}
+
+// listChannels collects the pages returned by fetch, spacing out calls and
+// retrying on rate limits
+func (c *Client) listChannels(ctx context.Context, fetch func(cursor string) ([]slack.Channel, string, error)) ([]slack.Channel, error) {
+	var all []slack.Channel
+	cursor := ""
+
+	for page := 0; page < maxChannelPages; page++ {
+		if page > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(channelPageDelay):
+			}
+		}
+
+		var channels []slack.Channel
+		var nextCursor string
+		err := c.withRateLimitRetry(ctx, func() error {
+			var err error
+			channels, nextCursor, err = fetch(cursor)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing channels: %w", err)
+		}
+
+		all = append(all, channels...)
+		if nextCursor == "" {
+			return all, nil
+		}
+		cursor = nextCursor
+	}
+
+	c.logger.Warnf("⚠️ Stopped listing channels after %d pages", maxChannelPages)
+	return all, nil
+}
+
+// channelIDPattern matches Slack channel IDs; other SLACK_CHANNEL_IDS and
+// SLACK_EXCLUDE_CHANNEL_IDS entries are treated as channel names
+var channelIDPattern = regexp.MustCompile(`^[CG][A-Z0-9]{6,}$`)
+
+// resolveChannels turns the entries of the channel list variable into
+// channel IDs. IDs are kept as they are; names (with or without a leading
+// #) are looked up in the workspace channel list, which is only fetched
+// when a name is present.
+func (c *Client) resolveChannels(ctx context.Context, variable string, entries []string) ([]string, error) {
+	var ids, names []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case !strings.HasPrefix(entry, "#") && channelIDPattern.MatchString(entry):
+			ids = append(ids, entry)
+		default:
+			names = append(names, strings.TrimPrefix(entry, "#"))
+		}
+	}
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	channels, err := c.workspaceChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving channel names: %w", err)
+	}
+	byName := make(map[string]string, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name] = channel.ID
+	}
+
+	var unresolved []string
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			unresolved = append(unresolved, "#"+name)
+			continue
+		}
+		c.logger.Debugf("Resolved channel #%s to %s", name, id)
+		ids = append(ids, id)
+	}
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("%s: channels not found (check the name, and that the bot can see private channels it was invited to): %s",
+			variable, strings.Join(unresolved, ", "))
+	}
+	return ids, nil
+}
+
+// checkAllChannelsThreshold refuses to start in all-channels mode when the
+// bot is in more channels than the configured threshold, unless the
+// operator explicitly confirmed it
+func (c *Client) checkAllChannelsThreshold(ctx context.Context) error {
+	if !c.monitorAllChannels || c.allChannelsConfirm || c.allChannelsWarnThreshold <= 0 {
+		return nil
+	}
+
+	channels, err := c.memberChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(channels) > c.allChannelsWarnThreshold {
+		return fmt.Errorf("bot is a member of %d channels, more than ALL_CHANNELS_WARN_THRESHOLD (%d); "+
+			"set ALL_CHANNELS_CONFIRM=true to monitor all of them, or run `channels suggest` to pick the most active ones for SLACK_CHANNEL_IDS",
+			len(channels), c.allChannelsWarnThreshold)
+	}
+	return nil
+}
+
+// ChannelActivity is the number of recent messages in a channel
+type ChannelActivity struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Messages int    `json:"messages"`
+}
+
+// SuggestOptions controls the channel activity scan
+type SuggestOptions struct {
+	// Lookback is how far back messages are counted
+	Lookback time.Duration
+	// MaxMessages caps the number of messages counted per channel
+	MaxMessages int
+	// StatePath, when set, stores scan progress so an interrupted scan can
+	// be resumed
+	StatePath string
+}
+
+// SuggestChannels counts recent messages in every channel the bot is a
+// member of and returns them sorted from most to least active
+func (c *Client) SuggestChannels(ctx context.Context, opts SuggestOptions) ([]ChannelActivity, error) {
+	scanned, err := loadScanState(opts.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := c.memberChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest := strconv.FormatInt(time.Now().Add(-opts.Lookback).Unix(), 10)
+
+	for i, channel := range channels {
+		if _, ok := scanned[channel.ID]; ok {
+			continue
+		}
+
+		c.logger.Infof("Scanning channel %d/%d: #%s", i+1, len(channels), channel.Name)
+
+		var history *slack.GetConversationHistoryResponse
+		err := c.withRateLimitRetry(ctx, func() error {
+			var err error
+			history, err = c.api.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: channel.ID,
+				Oldest:    oldest,
+				Limit:     opts.MaxMessages,
+			})
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			c.logger.Warnf("⚠️ Cannot read history of #%s (%s): %v", channel.Name, channel.ID, err)
+			continue
+		}
+
+		scanned[channel.ID] = ChannelActivity{ID: channel.ID, Name: channel.Name, Messages: len(history.Messages)}
+		if err := saveScanState(opts.StatePath, scanned); err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(channelPageDelay):
+		}
+	}
+
+	activity := make([]ChannelActivity, 0, len(scanned))
+	for _, a := range scanned {
+		activity = append(activity, a)
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		if activity[i].Messages != activity[j].Messages {
+			return activity[i].Messages > activity[j].Messages
+		}
+		return activity[i].Name < activity[j].Name
+	})
+	return activity, nil
+}
+
+// loadScanState reads the progress of a previous scan, if any
+func loadScanState(path string) (map[string]ChannelActivity, error) {
+	scanned := make(map[string]ChannelActivity)
+	if path == "" {
+		return scanned, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return scanned, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading scan state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &scanned); err != nil {
+		return nil, fmt.Errorf("error parsing scan state %s: %w", path, err)
+	}
+	return scanned, nil
+}
+
+// saveScanState persists scan progress after each channel
+func saveScanState(path string, scanned map[string]ChannelActivity) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(scanned, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding scan state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing scan state: %w", err)
+	}
+	return nil
+}
+
+// MonitoredChannels returns the IDs of the configured channels, empty when
+// monitoring all channels
+func (c *Client) MonitoredChannels() []string {
+	ids := make([]string, 0, len(c.channelIDs))
+	for id := range c.channelIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
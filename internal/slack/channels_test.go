@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/slacktest"
+)
+
+func TestCheckAllChannelsThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		channels  []string // SLACK_CHANNEL_IDS, empty monitors all
+		member    int      // channels the bot is a member of
+		threshold int
+		confirm   bool
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "under threshold", member: 3, threshold: 5, wantCalls: 1},
+		{name: "at threshold", member: 5, threshold: 5, wantCalls: 1},
+		{name: "over threshold", member: 6, threshold: 5, wantErr: true, wantCalls: 1},
+		{name: "over threshold across pages", member: 250, threshold: 200, wantErr: true, wantCalls: 2},
+		{name: "confirmed", member: 6, threshold: 5, confirm: true},
+		{name: "threshold off", member: 600, threshold: 0},
+		{name: "explicit channels", channels: []string{"C0000001"}, member: 600, threshold: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := slacktest.NewServer(t)
+			for i := 0; i < tt.member; i++ {
+				server.AddChannel(slack.Channel{GroupConversation: slack.GroupConversation{
+					Conversation: slack.Conversation{ID: fmt.Sprintf("C%07d", i)},
+					Name:         fmt.Sprintf("channel-%d", i),
+				}})
+			}
+			cfg := testConfig()
+			cfg.SlackChannelIDs = tt.channels
+			cfg.AllChannelsWarnThreshold = tt.threshold
+			cfg.AllChannelsConfirm = tt.confirm
+			c := newTestClient(t, server, cfg)
+
+			err := c.checkAllChannelsThreshold(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAllChannelsThreshold() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "ALL_CHANNELS_CONFIRM") {
+				t.Errorf("error %q doesn't tell how to confirm", err)
+			}
+			if got := server.Calls("users.conversations"); got != tt.wantCalls {
+				t.Errorf("users.conversations called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCheckAllChannelsThresholdListError(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.Fail("users.conversations", "missing_scope")
+	cfg := testConfig()
+	cfg.AllChannelsWarnThreshold = 5
+	c := newTestClient(t, server, cfg)
+
+	err := c.checkAllChannelsThreshold(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "missing_scope") {
+		t.Errorf("checkAllChannelsThreshold() = %v, want the missing_scope error", err)
+	}
+}
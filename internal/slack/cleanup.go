@@ -0,0 +1,64 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// DeleteMessage deletes one of the bot's messages, retrying on rate limits
+func (c *Client) DeleteMessage(ctx context.Context, channelID, ts string) error {
+	c.logger.Debugf("Deleting message %s in channel: %s", ts, channelID)
+
+	return c.withRateLimitRetry(ctx, func() error {
+		_, _, err := c.api.DeleteMessageContext(ctx, channelID, ts)
+		return err
+	})
+}
+
+// KeepReason reports why one of the bot's messages should not be deleted
+// automatically: it was pinned, or humans replied to it in a thread. An
+// empty reason means it can go.
+func (c *Client) KeepReason(ctx context.Context, channelID, ts string) (string, error) {
+	botUserID, err := c.BotUserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var thread []slack.Message
+	err = c.withRateLimitRetry(ctx, func() error {
+		var err error
+		thread, _, _, err = c.api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: ts,
+			Limit:     100,
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error getting thread replies: %w", err)
+	}
+
+	for _, msg := range thread {
+		if msg.Timestamp == ts {
+			if len(msg.PinnedTo) > 0 {
+				return "pinned", nil
+			}
+			continue
+		}
+		if msg.BotID == "" && msg.User != "" && msg.User != botUserID {
+			return "has replies from humans", nil
+		}
+	}
+	return "", nil
+}
+
+// IsPermanentError reports whether Slack rejected a call outright (e.g.
+// message_not_found, cant_delete_message), as opposed to a network problem
+// worth retrying later
+func IsPermanentError(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	return errors.As(err, &slackErr)
+}
@@ -2,9 +2,13 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -12,178 +16,390 @@ import (
 	"github.com/slack-go/slack/socketmode"
 
 	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/command"
+	"github.com/user/slack-bot-api/internal/explain"
+	"github.com/user/slack-bot-api/internal/logging"
 	"github.com/user/slack-bot-api/maps"
 )
 
+// decisionLogSize is the number of recent messages whose filter decisions
+// are retained for explain mode
+const decisionLogSize = 500
+
+// eventQueueSize is the number of acked events that can wait for
+// processing before new ones are dropped
+const eventQueueSize = 1000
+
+// Processor handles a message that passed the filters. The author's user
+// info has already been looked up and is passed along.
+type Processor func(ctx context.Context, event *IncomingMessage, user *slack.User) error
+
 // Client handles communication with the Slack API
 type Client struct {
 	api          *slack.Client
 	socketClient *socketmode.Client
 	channelIDs   map[string]bool // Will be nil if we're monitoring all channels
 	targetUsers  map[string]bool
-	logger       *log.Logger
-	debug        bool
-	logs         bool
-	monitorAllChannels bool
+	// targetUsersMu guards targetUsers, which verification adds the IDs
+	// of usernames and display names to, and targetNames, those names
+	// mapped to the IDs
+	targetUsersMu            sync.RWMutex
+	targetNames              map[string]string
+	logger                   *logging.Logger
+	debug                    bool
+	logs                     bool
+	monitorAllChannels       bool
+	decisions                *explain.Log
+	botUserID                string
+	botUserIDMu              sync.Mutex
+	allChannelsWarnThreshold int
+	allChannelsConfirm       bool
+	triggerReaction          string
+
+	// Slash subcommands and the users allowed to run admin-only ones
+	commands   *command.Registry
+	adminUsers map[string]bool
+
+	// Channels and users that are never translated, winning over
+	// channelIDs and targetUsers
+	excludedChannels map[string]bool
+	excludedUsers    map[string]bool
+
+	// Messages that are always translated
+	watchRules []config.WatchRule
+
+	// Channels where @channel announcements get a TL;DR thread
+	announcementChannels map[string]bool
+
+	// SLACK_TARGET_USERS is "*": everyone is a target user
+	allTargetUsers bool
+	// Members of target user groups are target users too
+	usergroups               *usergroupMembers
+	usergroupRefreshInterval time.Duration
+	// Channels whose translations name nobody, or all of them
+	anonymousAll      bool
+	anonymousChannels map[string]bool
+
+	// Message subtypes dropped before any API call
+	skippedSubtypes map[string]bool
+
+	// Button clicks, by action ID
+	actionHandlers map[string]ActionHandler
+
+	// Reactions added to messages, by emoji name
+	reactionHandlers map[string]ReactionHandler
+
+	// Startup ordering: events are acked and queued immediately, and held
+	// until verification marks the client ready
+	phaseMu      sync.Mutex
+	phase        string
+	ready        chan struct{}
+	readyOnce    sync.Once
+	readyTimeout time.Duration
+
+	// Event processing concurrency
+	workerPoolSize       int
+	preserveChannelOrder bool
+	// processingTimeout bounds the processing of each message
+	processingTimeout time.Duration
+
+	// Messages being processed, which get shutdownGrace to finish when
+	// shutting down
+	inFlight      *inFlight
+	shutdownGrace time.Duration
+
+	// users.info results are cached
+	users *userCache
+
+	// conversations.info results are cached
+	channels *channelCache
+
+	// Target and excluded user emails that couldn't be resolved to a user
+	// ID, with why
+	unresolvedEmails map[string]error
+
+	// Web API calls by method
+	usage *apiUsage
+
+	// Posts are queued per channel to stay inside Slack's rate limits
+	posts *channelPosts
+
+	// Socket mode connection state, for health checks
+	conn              *connection
+	healthGracePeriod time.Duration
+
+	// Events whose handling panicked, for /status
+	panics atomic.Uint64
+
+	// Channel told when the connection recovers after enough failures
+	connectionAlertChannel  string
+	connectionAlertFailures int
+
+	// Channel told about failed messages, deduplicated by error
+	adminChannel string
+	alerts       *adminAlerts
+
+	// Messages missed while disconnected are caught up on when
+	// checkpoints is set
+	checkpoints        Checkpoints
+	catchUpMaxAge      time.Duration
+	catchUpMaxMessages int
+	catchingUp         atomic.Bool
+
+	// Redelivered events are dropped
+	recentEvents    *recentSet
+	duplicateEvents uint64
 }
 
-// New creates a new Slack client
-func New(cfg *config.Config, logger *log.Logger) (*Client, error) {
+// New creates a new Slack client making its requests with httpClient
+func New(cfg *config.Config, httpClient *http.Client, logger *logging.Logger) (*Client, error) {
 	// Initialize Slack API client
+	// Every Web API call goes through usage, which records it by method
+	usage := newAPIUsage(httpClient)
 	api := slack.New(
 		cfg.SlackBotToken,
 		slack.OptionAppLevelToken(cfg.SlackAppToken),
 		slack.OptionDebug(cfg.Debug),
+		slack.OptionHTTPClient(usage),
 	)
 
 	// Create socket mode client
 	socketClient := socketmode.New(
 		api,
 		socketmode.OptionDebug(cfg.Debug),
-		socketmode.OptionLog(log.New(logger.Writer(), "socketmode: ", log.Lshortfile|log.LstdFlags)),
+		socketmode.OptionLog(logger.StdLogger("socketmode")),
+		socketmode.OptionDialer(websocketDialer(httpClient)),
 	)
 
 	// Check if we should monitor all channels
 	monitorAllChannels := len(cfg.SlackChannelIDs) == 0 || (len(cfg.SlackChannelIDs) == 1 && cfg.SlackChannelIDs[0] == "")
-	
-	var channelIDs map[string]bool
-	
-	if !monitorAllChannels {
-		// Convert channel IDs to a map for faster lookup
-		channelIDs = make(map[string]bool)
-		for _, id := range cfg.SlackChannelIDs {
-			// Strip any whitespace
-			id = strings.TrimSpace(id)
-			if id != "" {
-				channelIDs[id] = true
-			}
-		}
-	}
 
 	if cfg.Logs {
 		if monitorAllChannels {
-			logger.Println("=== Slack Channel Configuration ===")
-			logger.Println("🔍 Bot will monitor ALL channels it has been added to")
+			logger.Infof("=== Slack Channel Configuration ===")
+			logger.Infof("🔍 Bot will monitor ALL channels it has been added to")
 		} else {
-			logger.Println("=== Slack Channel Configuration ===")
-			logger.Printf("Number of monitored channels: %d", len(cfg.SlackChannelIDs))
+			logger.Infof("=== Slack Channel Configuration ===")
+			logger.Infof("Number of monitored channels: %d", len(cfg.SlackChannelIDs))
 			for i, id := range cfg.SlackChannelIDs {
-				logger.Printf("  Channel #%d: %s", i+1, id)
+				logger.Infof("  Channel #%d: %s", i+1, id)
 				// Try to get channel info if possible
 				if channel, err := api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: id}); err == nil {
-					logger.Printf("    Name: %s", channel.Name)
-					logger.Printf("    Is Channel: %v, Is Private: %v", channel.IsChannel, channel.IsPrivate)
+					logger.Infof("    Name: %s", channel.Name)
+					logger.Infof("    Is Channel: %v, Is Private: %v", channel.IsChannel, channel.IsPrivate)
 				}
 			}
 		}
 	}
 
-	// Convert target users to a map for faster lookup
-	targetUsers := make(map[string]bool)
-	for _, user := range cfg.SlackTargetUsers {
-		// Strip any whitespace
-		user = strings.TrimSpace(user)
-		if user != "" {
-			targetUsers[user] = true
-		}
+	// Convert target users to a map for faster lookup. Emails are
+	// resolved to user IDs once the client exists.
+	// With "*" there's nobody to list.
+	targetUsers, targetEmails := make(map[string]bool), []string(nil)
+	if !cfg.SlackAllTargetUsers {
+		targetUsers, targetEmails = parseUsers(cfg.SlackTargetUsers)
 	}
+	excludedUsers, excludedEmails := parseUsers(cfg.SlackExcludeUsers)
 
 	if cfg.Logs {
-		logger.Println("=== Slack User Configuration ===")
-		logger.Printf("Number of target users: %d", len(cfg.SlackTargetUsers))
+		logger.Infof("=== Slack User Configuration ===")
+		logger.Infof("Number of target users: %d", len(cfg.SlackTargetUsers))
 		for i, user := range cfg.SlackTargetUsers {
-			logger.Printf("  User #%d: %s", i+1, user)
+			logger.Infof("  User #%d: %s", i+1, user)
 			// Try to get user info if the user ID format is detected
 			if strings.HasPrefix(user, "U") && len(user) > 8 {
 				if userInfo, err := api.GetUserInfo(user); err == nil {
-					logger.Printf("    Name: %s", userInfo.Name)
-					logger.Printf("    Real Name: %s", userInfo.RealName)
-					logger.Printf("    Email: %s", userInfo.Profile.Email)
+					logger.Infof("    Name: %s", userInfo.Name)
+					logger.Infof("    Real Name: %s", userInfo.RealName)
+					logger.Infof("    Email: %s", userInfo.Profile.Email)
 				}
 			}
 		}
 	}
 
-	return &Client{
-		api:          api,
-		socketClient: socketClient,
-		channelIDs:   channelIDs,
-		targetUsers:  targetUsers,
-		logger:       logger,
-		debug:        cfg.Debug,
-		logs:         cfg.Logs,
-		monitorAllChannels: monitorAllChannels,
-	}, nil
-}
+	adminUsers := make(map[string]bool)
+	for _, user := range cfg.AdminUsers {
+		if user = strings.TrimSpace(user); user != "" {
+			adminUsers[user] = true
+		}
+	}
 
-// Start listens for Slack events
-func (c *Client) Start(ctx context.Context) error {
-	if c.logs {
-		c.logger.Println("Starting Slack client with Socket Mode...")
-		
-		// Only run setup verification when logs are enabled
-		if err := c.VerifySetup(ctx); err != nil {
-			c.logger.Printf("WARNING: Setup verification found issues: %v", err)
+	c := &Client{
+		api:                      api,
+		socketClient:             socketClient,
+		targetUsers:              targetUsers,
+		excludedUsers:            excludedUsers,
+		logger:                   logger,
+		debug:                    cfg.Debug,
+		logs:                     cfg.Logs,
+		monitorAllChannels:       monitorAllChannels,
+		decisions:                explain.NewLog(decisionLogSize),
+		allChannelsWarnThreshold: cfg.AllChannelsWarnThreshold,
+		allChannelsConfirm:       cfg.AllChannelsConfirm,
+		triggerReaction:          cfg.TriggerReaction,
+		watchRules:               cfg.WatchRules,
+		usage:                    usage,
+		posts:                    newChannelPosts(),
+		conn:                     newConnection(),
+		healthGracePeriod:        cfg.HealthGracePeriod,
+		connectionAlertChannel:   cfg.ConnectionAlertChannel,
+		connectionAlertFailures:  cfg.ConnectionAlertFailures,
+		adminChannel:             cfg.AdminChannelID,
+		alerts:                   newAdminAlerts(cfg.AdminAlertInterval),
+		catchUpMaxAge:            cfg.CatchUpMaxAge,
+		catchUpMaxMessages:       cfg.CatchUpMaxMessages,
+		announcementChannels:     make(map[string]bool),
+		allTargetUsers:           cfg.SlackAllTargetUsers,
+		usergroups:               newUsergroupMembers(cfg.SlackTargetUsergroups),
+		usergroupRefreshInterval: cfg.UsergroupRefreshInterval,
+		anonymousAll:             cfg.Anonymous,
+		anonymousChannels:        make(map[string]bool),
+		skippedSubtypes:          skippedSubtypeSet(cfg.SkippedSubtypes),
+		phase:                    PhaseStarting,
+		ready:                    make(chan struct{}),
+		readyTimeout:             cfg.StartupReadyTimeout,
+		processingTimeout:        cfg.MessageProcessingTimeout,
+		inFlight:                 newInFlight(),
+		shutdownGrace:            cfg.ShutdownGrace,
+		recentEvents:             newRecentSet(dedupCapacity, dedupTTL),
+		users:                    newUserCache(userCacheCapacity, cfg.UserCacheTTL),
+		channels:                 newChannelCache(cfg.ChannelCacheTTL),
+		workerPoolSize:           cfg.WorkerPoolSize,
+		preserveChannelOrder:     cfg.PreserveChannelOrder,
+		commands:                 command.NewRegistry(),
+		adminUsers:               adminUsers,
+		actionHandlers:           make(map[string]ActionHandler),
+		reactionHandlers:         make(map[string]ReactionHandler),
+	}
+	c.registerCommands()
+	for _, id := range cfg.AnnouncementTLDRChannels {
+		c.announcementChannels[strings.TrimSpace(id)] = true
+	}
+	for _, id := range cfg.AnonymousChannels {
+		if id = strings.TrimSpace(id); id != "" {
+			c.anonymousChannels[id] = true
 		}
-	} else {
-		// Simple startup message when logs are disabled
-		c.logger.Println("Starting Slack client...")
 	}
-	
-	// Run the socket mode client in a goroutine
+
+	c.unresolvedEmails = make(map[string]error)
+	c.resolveUserEmails(context.Background(), targetEmails, c.targetUsers)
+	c.resolveUserEmails(context.Background(), excludedEmails, c.excludedUsers)
+	c.refreshUsergroups(context.Background())
+
+	excludedChannels, err := c.resolveChannels(context.Background(), "SLACK_EXCLUDE_CHANNEL_IDS", cfg.SlackExcludeChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+	c.excludedChannels = make(map[string]bool, len(excludedChannels))
+	for _, id := range excludedChannels {
+		c.excludedChannels[id] = true
+	}
+
+	if !monitorAllChannels {
+		// Entries may be channel names, which are resolved to IDs so the
+		// event filters only ever compare IDs
+		ids, err := c.resolveChannels(context.Background(), "SLACK_CHANNEL_IDS", cfg.SlackChannelIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// Convert channel IDs to a map for faster lookup
+		c.channelIDs = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			c.channelIDs[id] = true
+		}
+	}
+
+	return c, nil
+}
+
+// Start connects to Slack and listens for events. The socket connection
+// starts first so events are acked right away; verification runs
+// concurrently and marks the client ready when done.
+func (c *Client) Start(ctx context.Context) error {
+	c.logger.Infof("Starting Slack client with Socket Mode...")
+
+	// Run the socket mode client in a goroutine until ctx is done. It only
+	// returns early on errors it can't recover from, like a revoked token.
+	c.setPhase(PhaseConnecting)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- c.socketClient.RunContext(ctx)
+	}()
+
+	startupErr := make(chan error, 1)
 	go func() {
-		if err := c.socketClient.Run(); err != nil {
-			c.logger.Printf("Error running socket mode client: %v", err)
+		c.setPhase(PhaseVerifying)
+
+		// Guard against surprise volume in huge workspaces
+		if err := c.checkAllChannelsThreshold(ctx); err != nil {
+			startupErr <- err
+			return
 		}
+
+		// Only run setup verification when logs are enabled
+		if c.logs {
+			if err := c.VerifySetup(ctx); err != nil {
+				c.logger.Warnf("⚠️ Setup verification found issues: %v", err)
+			}
+		}
+
+		c.markReady()
 	}()
 
-	// Run until context is canceled
-	<-ctx.Done()
-	c.logger.Println("Shutting down Slack client...")
+	// Run until context is canceled, or startup or the socket mode client
+	// fails
+	select {
+	case <-ctx.Done():
+	case err := <-startupErr:
+		return err
+	case err := <-runErr:
+		if ctx.Err() == nil {
+			if err == nil {
+				err = errors.New("exited unexpectedly")
+			}
+			return fmt.Errorf("socket mode client stopped: %w", err)
+		}
+	}
+	c.logger.Infof("Shutting down Slack client...")
 	return nil
 }
 
 // VerifySetup checks that everything is correctly configured
 func (c *Client) VerifySetup(ctx context.Context) error {
-	c.logger.Println("Verifying Slack bot setup...")
-	
+	c.logger.Infof("Verifying Slack bot setup...")
+
 	// Check authentication
 	authTest, err := c.api.AuthTestContext(ctx)
 	if err != nil {
 		return fmt.Errorf("authentication test failed: %w", err)
 	}
-	
-	c.logger.Printf("✅ Connected as: %s (UserID: %s, TeamName: %s)", 
+
+	c.logger.Infof("✅ Connected as: %s (UserID: %s, TeamName: %s)",
 		authTest.User, authTest.UserID, authTest.Team)
-	
+
 	// Check each channel
-	c.logger.Println("Verifying channel access...")
+	c.logger.Infof("Verifying channel access...")
 	channelErrors := false
 
 	if c.monitorAllChannels {
-		c.logger.Println("🔍 Bot is configured to monitor ALL channels it has been added to")
-		
-		// Get all conversations the bot is a member of
-		channels, nextCursor, err := c.api.GetConversationsForUserContext(ctx, &slack.GetConversationsForUserParameters{
-			Types: []string{"public_channel", "private_channel"},
-			Limit: 100,
-		})
-		
+		c.logger.Infof("🔍 Bot is configured to monitor ALL channels it has been added to")
+
+		// Get all conversations the bot is a member of, every page of them
+		channels, err := c.memberChannels(ctx)
+
 		if err != nil {
-			c.logger.Printf("❌ Error fetching channels: %v", err)
+			c.logger.Errorf("❌ Error fetching channels: %v", err)
 			channelErrors = true
 		} else {
+			c.cacheChannels(channels)
 			if len(channels) == 0 {
-				c.logger.Println("⚠️ Bot is not a member of any channels. Please add the bot to channels using /invite @BotName")
+				c.logger.Warnf("⚠️ Bot is not a member of any channels. Please add the bot to channels using /invite @BotName")
 				channelErrors = true
 			} else {
-				c.logger.Printf("✅ Bot is a member of %d channels:", len(channels))
+				c.logger.Infof("✅ Bot is a member of %d channels:", len(channels))
 				for _, channel := range channels {
-					c.logger.Printf("   - %s (%s)", channel.Name, channel.ID)
-				}
-				
-				if nextCursor != "" {
-					c.logger.Println("⚠️ Bot is in more than 100 channels. Only showing the first 100.")
+					c.logger.Infof("   - %s (%s)", channel.Name, channel.ID)
 				}
 			}
 		}
@@ -192,25 +408,25 @@ func (c *Client) VerifySetup(ctx context.Context) error {
 			channelInfo, err := c.api.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
 				ChannelID: channelID,
 			})
-			
+
 			if err != nil {
-				c.logger.Printf("❌ Channel access error for %s: %v", channelID, err)
+				c.logger.Errorf("❌ Channel access error for %s: %v", channelID, err)
 				channelErrors = true
 				continue
 			}
-			
+
 			// Check if bot is a member of the channel
 			members, _, err := c.api.GetUsersInConversationContext(ctx, &slack.GetUsersInConversationParameters{
 				ChannelID: channelID,
 			})
-			
+
 			if err != nil {
-				c.logger.Printf("❌ Cannot verify membership for channel %s (%s): %v", 
+				c.logger.Errorf("❌ Cannot verify membership for channel %s (%s): %v",
 					channelInfo.Name, channelID, err)
 				channelErrors = true
 				continue
 			}
-			
+
 			botInChannel := false
 			for _, memberID := range members {
 				if memberID == authTest.UserID {
@@ -218,71 +434,100 @@ func (c *Client) VerifySetup(ctx context.Context) error {
 					break
 				}
 			}
-			
+
 			if !botInChannel {
-				c.logger.Printf("❌ Bot is NOT a member of channel %s (%s). Please add the bot using /invite @%s", 
+				c.logger.Errorf("❌ Bot is NOT a member of channel %s (%s). Please add the bot using /invite @%s",
 					channelInfo.Name, channelID, authTest.User)
 				channelErrors = true
 				continue
 			}
-			
-			c.logger.Printf("✅ Channel verified: %s (%s)", channelInfo.Name, channelID)
+
+			c.logger.Infof("✅ Channel verified: %s (%s)", channelInfo.Name, channelID)
 		}
 	}
-	
+
 	// Check user access
-	c.logger.Println("Verifying user access...")
+	c.logger.Infof("Verifying user access...")
 	userErrors := false
-	
+
+	for email, err := range c.unresolvedEmails {
+		c.logger.Errorf("❌ User email %s could not be resolved: %v", email, err)
+		userErrors = true
+	}
+
+	if c.allTargetUsers {
+		c.logger.Infof("✅ Translating every user who isn't a bot (SLACK_TARGET_USERS=*)")
+	}
+
+	// A group that couldn't be fetched at startup has nobody in it until
+	// a refresh succeeds
+	sizes := c.usergroups.sizes()
+	for _, group := range c.usergroups.groups {
+		if size, ok := sizes[group]; ok {
+			c.logger.Infof("✅ User group %s verified: %d members", group, size)
+		} else {
+			c.logger.Errorf("❌ Cannot get members of user group %s. Check the ID and the usergroups:read scope.", group)
+			userErrors = true
+		}
+	}
+
+	// The workspace user list is only needed for usernames, and is fetched
+	// at most once no matter how many are configured. Names are resolved
+	// to IDs here, since messages are matched by ID: display names can be
+	// changed by anyone to any name.
+	var usersByName map[string]slack.User
+	resolved := make(map[string]string)
 	for targetUser := range c.targetUsers {
 		// Skip IDs that look like user IDs as they don't need username verification
 		if strings.HasPrefix(targetUser, "U") && len(targetUser) > 8 {
 			user, err := c.api.GetUserInfoContext(ctx, targetUser)
 			if err != nil {
-				c.logger.Printf("❌ Cannot get info for user ID %s: %v", targetUser, err)
+				c.logger.Errorf("❌ Cannot get info for user ID %s: %v", targetUser, err)
 				userErrors = true
 			} else {
-				c.logger.Printf("✅ User ID verified: %s (%s)", user.Name, targetUser)
+				c.logger.Infof("✅ User ID verified: %s (%s)", user.Name, targetUser)
 			}
 			continue
 		}
-		
-		// Try to find user by username
-		users, err := c.api.GetUsersContext(ctx)
-		if err != nil {
-			c.logger.Printf("❌ Cannot retrieve users list: %v", err)
-			userErrors = true
-			continue
-		}
-		
-		foundUser := false
-		for _, user := range users {
-			if user.Name == targetUser {
-				foundUser = true
-				c.logger.Printf("✅ Username verified: %s (%s)", user.Name, user.ID)
+
+		// Try to find user by username or display name
+		if usersByName == nil {
+			users, err := c.api.GetUsersContext(ctx)
+			if err != nil {
+				c.logger.Errorf("❌ Cannot retrieve users list: %v", err)
+				userErrors = true
 				break
 			}
+			usersByName = indexUsersByName(users)
 		}
-		
-		if !foundUser {
-			c.logger.Printf("❌ Username '%s' not found in workspace. Check for typos or use the user ID instead.", 
+
+		if user, ok := usersByName[targetUser]; ok {
+			c.logger.Infof("✅ Username verified: %s (%s)", targetUser, user.ID)
+			resolved[targetUser] = user.ID
+		} else {
+			c.logger.Errorf("❌ Username '%s' not found in workspace. Check for typos or use the user ID instead.",
 				targetUser)
 			userErrors = true
 		}
 	}
-	
+
+	c.addTargetNames(resolved)
+
+	// An entry on both lists is most likely a mistake; the exclusion wins
+	c.warnIncludedAndExcluded()
+
 	// Test if we can listen for events
-	c.logger.Println("Checking event subscriptions...")
-	c.logger.Println("⚠️ To verify event reception, please send a test message in one of the monitored channels.")
-	
+	c.logger.Infof("Checking event subscriptions...")
+	c.logger.Warnf("⚠️ To verify event reception, please send a test message in one of the monitored channels.")
+
 	// Send a test message to verify if Slack events are set up properly
 	c.testEventSubscription(ctx)
 
 	if channelErrors || userErrors {
 		return fmt.Errorf("setup verification found issues with channels and/or users")
 	}
-	
-	c.logger.Println("✅ Slack setup verification completed successfully!")
+
+	c.logger.Infof("✅ Slack setup verification completed successfully!")
 	return nil
 }
 
@@ -290,305 +535,785 @@ func (c *Client) VerifySetup(ctx context.Context) error {
 func (c *Client) testEventSubscription(ctx context.Context) {
 	// For all-channels mode, we need to find a channel to test
 	if c.monitorAllChannels {
-		c.logger.Println("🔍 Finding a channel to send test message...")
-		
+		c.logger.Infof("🔍 Finding a channel to send test message...")
+
 		// Get channels the bot is a member of
 		channels, _, err := c.api.GetConversationsForUserContext(ctx, &slack.GetConversationsForUserParameters{
 			Types: []string{"public_channel", "private_channel"},
 			Limit: 1,
 		})
-		
+
 		if err != nil {
-			c.logger.Printf("❌ Error fetching channels for test: %v", err)
-			c.logger.Println("⚠️ Skipping event subscription test")
+			c.logger.Errorf("❌ Error fetching channels for test: %v", err)
+			c.logger.Warnf("⚠️ Skipping event subscription test")
 			return
 		}
-		
+
 		if len(channels) == 0 {
-			c.logger.Println("⚠️ Bot is not a member of any channels. Please add the bot to channels using /invite @BotName")
-			c.logger.Println("⚠️ Skipping event subscription test")
+			c.logger.Warnf("⚠️ Bot is not a member of any channels. Please add the bot to channels using /invite @BotName")
+			c.logger.Warnf("⚠️ Skipping event subscription test")
 			return
 		}
-		
+
 		// Skip sending test message if DEBUG mode is not enabled
 		if !c.debug {
-			c.logger.Println("ℹ️ Skipping self-test message (enable DEBUG=true to send test messages)")
-			c.logger.Println("⚠️ If you're not receiving events, check your Event Subscriptions in Slack API settings")
+			c.logger.Debugf("ℹ️ Skipping self-test message (enable DEBUG=true to send test messages)")
+			c.logger.Warnf("⚠️ If you're not receiving events, check your Event Subscriptions in Slack API settings")
 			return
 		}
-		
+
 		// Use the first channel we find
 		channelID := channels[0].ID
-		c.logger.Printf("🧪 Sending a self-test message to channel %s (%s) to verify event subscriptions...", 
+		c.logger.Infof("🧪 Sending a self-test message to channel %s (%s) to verify event subscriptions...",
 			channels[0].Name, channelID)
-		
+
 		// Create a unique message so we can identify it
-		testMsg := fmt.Sprintf("🔍 Bot self-test message (timestamp: %s) - If you see this message but no events are logged, check your Event Subscriptions in Slack API", 
+		testMsg := fmt.Sprintf("🔍 Bot self-test message (timestamp: %s) - If you see this message but no events are logged, check your Event Subscriptions in Slack API",
 			time.Now().Format(time.RFC3339))
-		
+
 		// Send the message
 		_, _, err = c.api.PostMessageContext(
 			ctx,
 			channelID,
 			slack.MsgOptionText(testMsg, false),
 		)
-		
+
 		if err != nil {
-			c.logger.Printf("❌ Failed to send test message: %v", err)
-			c.logger.Println("⚠️ This may indicate the bot lacks permissions to post in this channel")
+			c.logger.Errorf("❌ Failed to send test message: %v", err)
+			c.logger.Warnf("⚠️ This may indicate the bot lacks permissions to post in this channel")
 			return
 		}
-		
-		c.logger.Println("✅ Test message sent successfully")
-		c.logger.Println("⚠️ If you don't see any event logs after this, your Slack app's Event Subscriptions may not be set up correctly")
-		c.logger.Println("⚠️ Check that Socket Mode is enabled AND you've subscribed to message events in your Slack app settings")
+
+		c.logger.Infof("✅ Test message sent successfully")
+		c.logger.Warnf("⚠️ If you don't see any event logs after this, your Slack app's Event Subscriptions may not be set up correctly")
+		c.logger.Warnf("⚠️ Check that Socket Mode is enabled AND you've subscribed to message events in your Slack app settings")
 		return
 	}
-	
+
 	// Only try to send a test message if we have at least one channel
 	if len(c.channelIDs) == 0 {
-		c.logger.Println("⚠️ No channels configured, skipping event subscription test")
+		c.logger.Warnf("⚠️ No channels configured, skipping event subscription test")
 		return
 	}
-	
+
 	// Skip sending test message if DEBUG mode is not enabled
 	if !c.debug {
-		c.logger.Println("ℹ️ Skipping self-test message (enable DEBUG=true to send test messages)")
-		c.logger.Println("⚠️ If you're not receiving events, check your Event Subscriptions in Slack API settings")
+		c.logger.Debugf("ℹ️ Skipping self-test message (enable DEBUG=true to send test messages)")
+		c.logger.Warnf("⚠️ If you're not receiving events, check your Event Subscriptions in Slack API settings")
 		return
 	}
-	
+
 	// Get the first channel ID
 	var channelID string
 	for id := range c.channelIDs {
 		channelID = id
 		break
 	}
-	
-	c.logger.Printf("🧪 Sending a self-test message to channel %s to verify event subscriptions...", channelID)
-	
+
+	c.logger.Infof("🧪 Sending a self-test message to channel %s to verify event subscriptions...", channelID)
+
 	// Create a unique message so we can identify it
-	testMsg := fmt.Sprintf("🔍 Bot self-test message (timestamp: %s) - If you see this message but no events are logged, check your Event Subscriptions in Slack API", 
+	testMsg := fmt.Sprintf("🔍 Bot self-test message (timestamp: %s) - If you see this message but no events are logged, check your Event Subscriptions in Slack API",
 		time.Now().Format(time.RFC3339))
-	
+
 	// Send the message
 	_, _, err := c.api.PostMessageContext(
 		ctx,
 		channelID,
 		slack.MsgOptionText(testMsg, false),
 	)
-	
+
 	if err != nil {
-		c.logger.Printf("❌ Failed to send test message: %v", err)
-		c.logger.Println("⚠️ This may indicate the bot lacks permissions to post in this channel")
+		c.logger.Errorf("❌ Failed to send test message: %v", err)
+		c.logger.Warnf("⚠️ This may indicate the bot lacks permissions to post in this channel")
 		return
 	}
-	
-	c.logger.Println("✅ Test message sent successfully")
-	c.logger.Println("⚠️ If you don't see any event logs after this, your Slack app's Event Subscriptions may not be set up correctly")
-	c.logger.Println("⚠️ Check that Socket Mode is enabled AND you've subscribed to message events in your Slack app settings")
+
+	c.logger.Infof("✅ Test message sent successfully")
+	c.logger.Warnf("⚠️ If you don't see any event logs after this, your Slack app's Event Subscriptions may not be set up correctly")
+	c.logger.Warnf("⚠️ Check that Socket Mode is enabled AND you've subscribed to message events in your Slack app settings")
 }
 
 // ProcessEvents processes Slack events
-func (c *Client) ProcessEvents(ctx context.Context, processor func(ctx context.Context, event *slack.MessageEvent) error) {
+func (c *Client) ProcessEvents(ctx context.Context, processor Processor) {
 	if c.logs {
-		c.logger.Println("\n===============================================")
-		c.logger.Println("🤖 GEN ALPHA BOT READY TO PROCESS MESSAGES 🤖")
-		c.logger.Println("===============================================")
-		c.logger.Printf("Bot is monitoring %d channels for messages from %d target users", 
-			len(c.channelIDs), len(c.targetUsers))
-		c.logger.Println("Channels monitored:", strings.Join(maps.Keys(c.channelIDs), ", "))
-		c.logger.Println("Target users:", strings.Join(maps.Keys(c.targetUsers), ", "))
-		c.logger.Println("===============================================\n")
-		c.logger.Println("⚠️ WAITING FOR EVENTS - If no events appear below when you send messages, check your Slack app configuration")
-	}
-	
+		c.logger.Infof("\n===============================================")
+		c.logger.Infof("🤖 GEN ALPHA BOT READY TO PROCESS MESSAGES 🤖")
+		c.logger.Infof("===============================================")
+		if c.allTargetUsers {
+			c.logger.Infof("Bot is monitoring %d channels for messages from everyone", len(c.channelIDs))
+		} else {
+			c.logger.Infof("Bot is monitoring %d channels for messages from %d target users",
+				len(c.channelIDs), len(c.targetUsers))
+		}
+		c.logger.Infof("Channels monitored: %s", strings.Join(maps.Keys(c.channelIDs), ", "))
+		if !c.allTargetUsers {
+			c.logger.Infof("Target users: %s", strings.Join(maps.Keys(c.targetUsers), ", "))
+		}
+		c.logger.Infof("===============================================")
+		c.logger.Infof("⚠️ WAITING FOR EVENTS - If no events appear below when you send messages, check your Slack app configuration")
+	}
+
 	// Create a ticker to log periodic heartbeats
 	ticker := time.NewTicker(60 * time.Second)
 	defer ticker.Stop()
-	
+
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				c.logger.Println("❤️ Bot is still alive and listening for events...")
+				c.logger.Infof("❤️ Bot is still alive and listening for events...")
+				hits, misses := c.users.stats()
+				c.logger.Debugf("👤 User info cache: %d hits, %d misses", hits, misses)
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
-	
-	for evt := range c.socketClient.Events {
-		// Debug log for ALL events received from Slack
-		c.logger.Printf("🔍 DEBUG - Received event from Slack: Type=%s", evt.Type)
-		
-		// Handle events by type
-		switch evt.Type {
-		case socketmode.EventTypeConnecting:
-			c.logger.Println("Connecting to Slack with Socket Mode...")
-		case socketmode.EventTypeConnectionError:
-			c.logger.Println("Connection failed. Retrying later...")
-		case socketmode.EventTypeConnected:
-			c.logger.Println("Connected to Slack with Socket Mode.")
-		case socketmode.EventTypeHello:
-			c.logger.Println("🎉 Received Hello from Slack - connection fully established")
-		case socketmode.EventTypeDisconnect:
-			c.logger.Println("⚠️ Disconnected from Slack")
-		case socketmode.EventTypeEventsAPI:
-			// Acknowledge the event immediately
-			c.socketClient.Ack(*evt.Request)
-
-			// Log raw event for troubleshooting
-			c.logger.Printf("📨 Received event from Slack Events API: %+v", evt)
-
-			// Parse the event
-			eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+
+	if len(c.usergroups.groups) > 0 {
+		go c.refreshUsergroupsEvery(ctx, c.usergroupRefreshInterval)
+	}
+
+	// Exclusions and the processing deadline apply to every way a
+	// message can reach the processor
+	processor = c.excluding(c.WithDeadline(processor))
+
+	// Events API events are queued to a worker pool and processed once
+	// startup is ready, so the loop below only ever acks and enqueues
+	pool := newWorkerPool(c.workerPoolSize, eventQueueSize, c.preserveChannelOrder, func() { c.waitReady(ctx) })
+
+	// Work that already started is allowed to finish after cancellation,
+	// for up to shutdownGrace, so a translation isn't abandoned halfway
+	// through posting
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer c.drain(pool, cancelWork)
+
+	connected := false
+	for {
+		var evt socketmode.Event
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok = <-c.socketClient.Events:
 			if !ok {
-				c.logger.Printf("❌ Error: Events API event expected but got %T", evt.Data)
-				continue
+				return
 			}
+		}
 
-			// Log the complete event structure
-			c.logger.Printf("📨 Event details - Type: %s, InnerEvent Type: %s", 
-				eventsAPIEvent.Type, eventsAPIEvent.InnerEvent.Type)
-
-			// Handle message events
-			if eventsAPIEvent.Type == slackevents.CallbackEvent {
-				innerEvent := eventsAPIEvent.InnerEvent
-				
-				// Log inner event type for troubleshooting
-				c.logger.Printf("🔍 Inner event type: %s", innerEvent.Type)
-				
-				// Check for message type
-				if innerEvent.Type == string(slackevents.Message) {
-					// First, get the event as a slackevents.MessageEvent
-					slackEventsMessageEvent, ok := innerEvent.Data.(*slackevents.MessageEvent)
-					if !ok {
-						c.logger.Printf("❌ Error: slackevents.MessageEvent expected but got %T", innerEvent.Data)
-						continue
-					}
-					
-					// Create a compatible MessageEvent structure
-					// Using only the fields we need for our application to avoid field name mismatches
-					messageEvent := &slack.MessageEvent{
-						Msg: slack.Msg{
-							Channel:   slackEventsMessageEvent.Channel,
-							User:      slackEventsMessageEvent.User,
-							Text:      slackEventsMessageEvent.Text,
-							Timestamp: slackEventsMessageEvent.TimeStamp,
-							ThreadTimestamp: slackEventsMessageEvent.ThreadTimeStamp,
-							BotID:     slackEventsMessageEvent.BotID,
-							SubType:   slackEventsMessageEvent.SubType,
-						},
-					}
-
-					c.logger.Printf("📝 Message received - Channel: %s, User: %s, Text: %s", 
-						messageEvent.Channel, messageEvent.User, messageEvent.Text)
-
-					// Skip bot messages, including our own replies to avoid loops
-					if messageEvent.BotID != "" || messageEvent.SubType == "bot_message" {
-						c.logger.Printf("⏩ Ignoring bot message from: %s", messageEvent.BotID)
-						continue
-					}
-
-					// Debug all channel IDs
-					c.logger.Printf("🔍 Checking channel access - Message channel: %s, Monitored channels: %v", 
-						messageEvent.Channel, c.channelIDs)
-						
-					// Process only messages from monitored channels if we're not monitoring all channels
-					if !c.monitorAllChannels && !c.channelIDs[messageEvent.Channel] {
-						c.logger.Printf("⏩ Ignoring message from non-monitored channel: %s", messageEvent.Channel)
-						continue
-					}
-
-					if c.monitorAllChannels {
-						c.logger.Printf("✅ Processing message from channel: %s (monitoring all channels)", messageEvent.Channel)
-					} else {
-						c.logger.Printf("✅ Channel match found: %s", messageEvent.Channel)
-					}
-
-					// Process only messages from target users
-					user, err := c.GetUserInfo(ctx, messageEvent.User)
-					if err != nil {
-						c.logger.Printf("❌ Error getting user info: %v", err)
-						continue
-					}
-
-					c.logger.Printf("👤 User info retrieved: %s (%s)", user.Name, user.ID)
-
-					// Debug all target users
-					c.logger.Printf("🔍 Checking user match - Message user: %s (%s), Target users: %v", 
-						user.Name, messageEvent.User, c.targetUsers)
-						
-					if !c.targetUsers[user.Name] && !c.targetUsers[messageEvent.User] {
-						c.logger.Printf("⏩ Ignoring message from non-target user: %s (%s)", user.Name, messageEvent.User)
-						continue
-					}
-
-					c.logger.Printf("✅ User match found: %s", user.Name)
-					c.logger.Printf("🎯 Processing message: '%s'", messageEvent.Text)
-
-					// Process the message
-					if err := processor(ctx, messageEvent); err != nil {
-						c.logger.Printf("❌ Error processing message: %v", err)
-					} else {
-						c.logger.Printf("✅ Successfully processed message from user: %s", user.Name)
-					}
-				} else {
-					c.logger.Printf("ℹ️ Received non-message event type: %s", innerEvent.Type)
-				}
-			} else {
-				c.logger.Printf("ℹ️ Received non-callback event type: %s", eventsAPIEvent.Type)
+		c.dispatchEvent(ctx, workCtx, evt, pool, processor, &connected)
+	}
+}
+
+// dispatchEvent acks an event from the socket mode client and hands it to
+// its handler. A panic while handling the event, here or in the handler it
+// was passed to, is logged and the event dropped, so one malformed event
+// can't take down the event loop.
+func (c *Client) dispatchEvent(ctx, workCtx context.Context, evt socketmode.Event, pool *workerPool, processor Processor, connected *bool) {
+	defer c.recoverEvent(string(evt.Type))
+
+	// Debug log for ALL events received from Slack
+	c.logger.Debugf("🔍 DEBUG - Received event from Slack: Type=%s", evt.Type)
+
+	// Handle events by type
+	switch evt.Type {
+	case socketmode.EventTypeConnecting:
+		c.logger.Infof("Connecting to Slack with Socket Mode...")
+	case socketmode.EventTypeConnectionError:
+		failures := c.conn.setFailed(fmt.Sprintf("connection error: %v", evt.Data))
+		socketConnectionErrors.Inc()
+		if connErr, ok := evt.Data.(*slack.ConnectionErrorEvent); ok {
+			c.logger.Warnf("⚠️ Connection failed (%d in a row): %v. Retrying in %s", failures, connErr.ErrorObj, connErr.Backoff.Round(time.Millisecond))
+		} else {
+			c.logger.Warnf("⚠️ Connection failed (%d in a row). Retrying later...", failures)
+		}
+	case socketmode.EventTypeConnected:
+		c.logger.Infof("Connected to Slack with Socket Mode.")
+		if *connected {
+			socketReconnects.Inc()
+		}
+		*connected = true
+		ended := c.conn.setConnected()
+		if ended.failures > 0 {
+			c.logger.Infof("🔌 Reconnected after %s and %d failed attempts", ended.duration.Round(time.Second), ended.failures)
+		}
+		// Posting can wait on rate limits, don't hold up the event loop
+		go c.alertRecovery(ctx, ended)
+	case socketmode.EventTypeHello:
+		c.logger.Infof("🎉 Received Hello from Slack - connection fully established")
+		c.conn.setHello()
+		if c.checkpoints != nil {
+			go c.catchUp(ctx, workCtx, processor)
+		}
+	case socketmode.EventTypeDisconnect:
+		reason := disconnectReason(evt)
+		c.logger.Warnf("⚠️ Disconnected from Slack: %s", reason)
+		c.conn.setDown("disconnected: " + reason)
+	case socketmode.EventTypeEventsAPI:
+		// Acknowledge the event immediately
+		c.ack(evt)
+
+		// Queue the event for processing so the loop gets back to acking
+		// promptly, even while startup verification is still running
+		queued := pool.submit(eventChannel(evt), func() {
+			defer c.recoverEvent(string(evt.Type))
+			// Shutting down, don't start new work
+			if ctx.Err() != nil || !c.inFlight.begin() {
+				c.inFlight.skip()
+				return
 			}
-		default:
-			c.logger.Printf("ℹ️ Received unhandled event type: %s", evt.Type)
+			defer c.inFlight.end()
+			c.handleEventsAPI(workCtx, evt, processor)
+		})
+		if !queued {
+			c.logger.Warnf("⚠️ Event queue full (%d events), dropping event", eventQueueSize)
+		}
+	case socketmode.EventTypeInteractive:
+		// Acknowledge within Slack's 3 second deadline, work happens async
+		c.ack(evt)
+
+		callback, ok := evt.Data.(slack.InteractionCallback)
+		if !ok {
+			c.logger.Errorf("❌ Error: interaction callback expected but got %T", evt.Data)
+			return
+		}
+
+		if !c.inFlight.begin() {
+			return
 		}
+		go func() {
+			defer c.inFlight.end()
+			defer c.recoverEvent(string(evt.Type))
+			c.handleInteraction(workCtx, callback, processor)
+		}()
+	case socketmode.EventTypeSlashCommand:
+		// Acknowledge the command immediately; the reply is sent separately
+		c.ack(evt)
+
+		cmd, ok := evt.Data.(slack.SlashCommand)
+		if !ok {
+			c.logger.Errorf("❌ Error: slash command expected but got %T", evt.Data)
+			return
+		}
+
+		c.logger.Infof("⌨️ Slash command received - Command: %s %s, User: %s", cmd.Command, cmd.Text, cmd.UserID)
+		if !c.inFlight.begin() {
+			return
+		}
+		go func() {
+			defer c.inFlight.end()
+			defer c.recoverEvent(string(evt.Type))
+			c.handleSlashCommand(workCtx, cmd)
+		}()
+	default:
+		c.logger.Debugf("ℹ️ Received unhandled event type: %s", evt.Type)
+	}
+}
+
+// ack acknowledges an event. Some events, such as those replayed while
+// reconnecting, arrive without a request and can't be acked.
+func (c *Client) ack(evt socketmode.Event) {
+	if evt.Request == nil {
+		c.logger.Warnf("⚠️ %s event arrived without a request, not acking it", evt.Type)
+		return
 	}
+	c.socketClient.Ack(*evt.Request)
 }
 
-// GetUserInfo gets information about a Slack user
+// recoverEvent is deferred around the handling of a single event and logs a
+// panic instead of letting it crash the bot, or stop the loop or worker
+// handling the events after it
+func (c *Client) recoverEvent(what string) {
+	if r := recover(); r != nil {
+		c.panics.Add(1)
+		eventPanics.Inc()
+		c.logger.Errorf("❌ Recovered from panic while handling %s event: %v\n%s", what, r, debug.Stack())
+	}
+}
+
+// Panics returns how many events panicked while being handled since
+// startup
+func (c *Client) Panics() uint64 {
+	return c.panics.Load()
+}
+
+// loggerFor returns the logger carrying the fields of the event ctx belongs
+// to
+func (c *Client) loggerFor(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, c.logger)
+}
+
+// GetUserInfo gets information about a Slack user, served from the cache
+// while it is fresh
 func (c *Client) GetUserInfo(ctx context.Context, userID string) (*slack.User, error) {
-	if c.logs {
-		c.logger.Printf("Getting user info for userID: %s", userID)
+	if user, ok := c.users.get(userID); ok {
+		return user, nil
 	}
-	
+
+	c.logger.Debugf("Getting user info for userID: %s", userID)
+
 	user, err := c.api.GetUserInfoContext(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting user info: %w", err)
 	}
-	
-	if c.logs {
-		c.logger.Printf("User info retrieved: %s (%s)", user.Name, user.ID)
-	}
-	
+	c.users.put(user)
+
+	c.logger.Debugf("User info retrieved: %s (%s)", user.Name, user.ID)
+
 	return user, nil
 }
 
-// PostMessage posts a message to a Slack channel
+// PostMessage posts a message to a Slack channel. Posts to the same
+// channel are queued and spaced out, and retried when Slack rate limits
+// them anyway.
 func (c *Client) PostMessage(ctx context.Context, channelID, text string, options ...slack.MsgOption) (string, string, error) {
-	if c.logs {
-		c.logger.Printf("Posting message to channel: %s", channelID)
+	c.logger.Debugf("Posting message to channel: %s", channelID)
+
+	options = append([]slack.MsgOption{slack.MsgOptionText(text, false)}, options...)
+	var respChannel, ts string
+	err := c.posts.do(ctx, channelID, func() error {
+		defer func(start time.Time) { postDuration.ObserveDuration(time.Since(start)) }(time.Now())
+		return c.withRateLimitRetry(ctx, func() error {
+			var err error
+			respChannel, ts, err = c.api.PostMessageContext(ctx, channelID, options...)
+			return err
+		})
+	})
+	return respChannel, ts, err
+}
+
+// UpdateMessage replaces the content of one of the bot's messages. When
+// blocks are given, text is the fallback used in notifications. Names in
+// text aren't linked, so an edit never notifies anyone.
+func (c *Client) UpdateMessage(ctx context.Context, channelID, ts, text string, blocks ...slack.Block) error {
+	c.logger.Debugf("Updating message %s in channel: %s", ts, channelID)
+
+	_, _, _, err := c.api.UpdateMessageContext(ctx, channelID, ts, slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks...), slack.MsgOptionLinkNames(false))
+	return err
+}
+
+// PostBlocks posts a Block Kit message to a Slack channel. text is the
+// fallback shown in notifications and by clients that can't render blocks.
+func (c *Client) PostBlocks(ctx context.Context, channelID, text string, blocks []slack.Block, options ...slack.MsgOption) (string, string, error) {
+	return c.PostMessage(ctx, channelID, text, append([]slack.MsgOption{slack.MsgOptionBlocks(blocks...)}, options...)...)
+}
+
+// ThreadMessages returns the messages of a thread posted before ts, parent
+// first, up to limit
+func (c *Client) ThreadMessages(ctx context.Context, channelID, threadTS, ts string, limit int) ([]slack.Message, error) {
+	var messages []slack.Message
+	err := c.withRateLimitRetry(ctx, func() error {
+		var err error
+		messages, _, _, err = c.api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: threadTS,
+			Latest:    ts,
+			Limit:     limit,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting thread replies: %w", err)
 	}
-	
-	return c.api.PostMessageContext(ctx, channelID, append([]slack.MsgOption{slack.MsgOptionText(text, false)}, options...)...)
+	return messages, nil
+}
+
+// AddReaction adds an emoji reaction to a message
+func (c *Client) AddReaction(ctx context.Context, channelID, ts, name string) error {
+	return c.api.AddReactionContext(ctx, name, slack.NewRefToMessage(channelID, ts))
+}
+
+// RemoveReaction removes one of the bot's emoji reactions from a message
+func (c *Client) RemoveReaction(ctx context.Context, channelID, ts, name string) error {
+	return c.api.RemoveReactionContext(ctx, name, slack.NewRefToMessage(channelID, ts))
+}
+
+// PostEphemeral posts a message to a channel that only the given user can see
+func (c *Client) PostEphemeral(ctx context.Context, channelID, userID, text string) error {
+	c.logger.Debugf("Posting ephemeral message to user %s in channel: %s", userID, channelID)
+
+	_, err := c.api.PostEphemeralContext(ctx, channelID, userID, slack.MsgOptionText(text, false))
+	return err
+}
+
+// Decisions returns the log of recent filter decisions
+func (c *Client) Decisions() *explain.Log {
+	return c.decisions
 }
 
 // CreateThread posts a message to a thread
 func (c *Client) CreateThread(ctx context.Context, channelID, threadTS, text string) (string, string, error) {
-	if c.logs {
-		c.logger.Printf("Creating thread reply in channel: %s, thread: %s", channelID, threadTS)
-	}
-	
-	channelID, threadTS, err := c.api.PostMessageContext(
-		ctx,
-		channelID,
-		slack.MsgOptionText(text, false),
-		slack.MsgOptionTS(threadTS),
-	)
-	
-	if err == nil && c.logs {
-		c.logger.Printf("Thread reply created successfully in channel: %s, thread: %s", channelID, threadTS)
+	c.logger.Debugf("Creating thread reply in channel: %s, thread: %s", channelID, threadTS)
+
+	channelID, threadTS, err := c.PostMessage(ctx, channelID, text, slack.MsgOptionTS(threadTS))
+
+	if err == nil {
+		c.logger.Debugf("Thread reply created successfully in channel: %s, thread: %s", channelID, threadTS)
 	}
-	
+
 	return channelID, threadTS, err
-} 
\ No newline at end of file
+}
+
+// handleEventsAPI handles an Events API event that has already been acked
+func (c *Client) handleEventsAPI(ctx context.Context, evt socketmode.Event, processor Processor) {
+	// Log raw event for troubleshooting
+	c.logger.Debugf("📨 Received event from Slack Events API: %+v", evt)
+
+	// Parse the event
+	eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+	if !ok {
+		c.logger.Errorf("❌ Error: Events API event expected but got %T", evt.Data)
+		return
+	}
+
+	// Log the complete event structure
+	c.logger.Debugf("📨 Event details - Type: %s, InnerEvent Type: %s",
+		eventsAPIEvent.Type, eventsAPIEvent.InnerEvent.Type)
+
+	// Drop redeliveries of events we've already handled
+	if callback, ok := eventsAPIEvent.Data.(*slackevents.EventsAPICallbackEvent); ok && callback.EventID != "" {
+		if c.recentEvents.seen(callback.EventID) {
+			duplicates := atomic.AddUint64(&c.duplicateEvents, 1)
+			c.logger.Debugf("⏩ Dropped duplicate event %s (%d duplicates dropped so far)", callback.EventID, duplicates)
+			return
+		}
+	}
+
+	// Handle message events
+	if eventsAPIEvent.Type == slackevents.CallbackEvent {
+		innerEvent := eventsAPIEvent.InnerEvent
+		logger := c.logger.With(logging.EventType(innerEvent.Type))
+		ctx = logging.NewContext(ctx, logger)
+
+		// Log inner event type for troubleshooting
+		logger.Debugf("🔍 Inner event type: %s", innerEvent.Type)
+
+		// Check for message type
+		if innerEvent.Type == string(slackevents.Message) {
+			messagesReceived.Inc()
+			// First, get the event as a slackevents.MessageEvent
+			slackEventsMessageEvent, ok := innerEvent.Data.(*slackevents.MessageEvent)
+			if !ok {
+				logger.Errorf("❌ Error: slackevents.MessageEvent expected but got %T", innerEvent.Data)
+				return
+			}
+
+			messageEvent := newIncomingMessage(slackEventsMessageEvent)
+
+			// Edits carry the edited message in a nested object
+			if messageEvent.SubType == subTypeMessageChanged {
+				edited, ok := editedMessage(slackEventsMessageEvent)
+				if !ok {
+					logger.Debugf("⏩ Ignoring edit that didn't change the text in channel: %s", messageEvent.Channel)
+					return
+				}
+				messageEvent = edited
+			}
+			c.handleMessage(ctx, messageEvent, processor)
+		} else if innerEvent.Type == string(slackevents.ReactionAdded) {
+			reaction, ok := innerEvent.Data.(*slackevents.ReactionAddedEvent)
+			if !ok {
+				logger.Errorf("❌ Error: slackevents.ReactionAddedEvent expected but got %T", innerEvent.Data)
+				return
+			}
+			c.handleReactionAdded(ctx, reaction, processor)
+		} else if innerEvent.Type == string(slackevents.ChannelRename) {
+			if rename, ok := innerEvent.Data.(*slackevents.ChannelRenameEvent); ok {
+				c.handleChannelRename(ctx, rename.Channel.ID, rename.Channel.Name)
+			}
+		} else if innerEvent.Type == string(slackevents.GroupRename) {
+			if rename, ok := innerEvent.Data.(*slackevents.GroupRenameEvent); ok {
+				c.handleChannelRename(ctx, rename.Channel.ID, rename.Channel.Name)
+			}
+		} else if innerEvent.Type == string(slackevents.AppMention) {
+			mention, ok := innerEvent.Data.(*slackevents.AppMentionEvent)
+			if !ok {
+				logger.Errorf("❌ Error: slackevents.AppMentionEvent expected but got %T", innerEvent.Data)
+				return
+			}
+			c.handleAppMention(ctx, mention, processor)
+		} else {
+			logger.Debugf("ℹ️ Received non-message event type: %s", innerEvent.Type)
+		}
+	} else {
+		c.logger.Debugf("ℹ️ Received non-callback event type: %s", eventsAPIEvent.Type)
+	}
+}
+
+// eventChannel returns the channel an Events API event belongs to, used to
+// keep events of one channel in order
+func eventChannel(evt socketmode.Event) string {
+	eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+	if !ok {
+		return ""
+	}
+
+	switch data := eventsAPIEvent.InnerEvent.Data.(type) {
+	case *slackevents.MessageEvent:
+		return data.Channel
+	case *slackevents.AppMentionEvent:
+		return data.Channel
+	case *slackevents.ReactionAddedEvent:
+		return data.Item.Channel
+	}
+	return ""
+}
+
+// handleMessage runs a message through the filters and hands it to the
+// processor when it passes. Messages arrive here from message events and
+// from catching up after a reconnect.
+func (c *Client) handleMessage(ctx context.Context, messageEvent *IncomingMessage, processor Processor) {
+	logger := c.loggerFor(ctx).With(logging.Channel(messageEvent.Channel), logging.User(messageEvent.User))
+	if name := c.ChannelName(ctx, messageEvent.Channel); name != messageEvent.Channel {
+		logger = logger.With(logging.ChannelName(name))
+	}
+	ctx = logging.NewContext(ctx, logger)
+
+	logger.Debugf("📝 Message received - Channel: %s, User: %s, Text: %s",
+		c.channelLabel(ctx, messageEvent.Channel), messageEvent.User, messageEvent.Text)
+
+	// Retries of the same message can also arrive under a new event ID
+	if messageEvent.SubType == "" && c.recentEvents.seen(messageEvent.Channel+"/"+messageEvent.Timestamp) {
+		duplicates := atomic.AddUint64(&c.duplicateEvents, 1)
+		logger.Debugf("⏩ Dropped duplicate message %s in %s (%d duplicates dropped so far)", messageEvent.Timestamp, messageEvent.Channel, duplicates)
+		return
+	}
+
+	// Remember how far the channel was read, for catching up after an
+	// outage
+	c.markSeen(messageEvent)
+
+	// Housekeeping messages like joins and topic changes
+	if c.skippedSubtypes[messageEvent.SubType] {
+		logger.Debugf("⏩ Ignoring %s message in channel: %s", messageEvent.SubType, messageEvent.Channel)
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "translatable subtype", false, "subtype "+messageEvent.SubType)
+		return
+	}
+
+	// Watch rules guarantee translation, whoever posted the message,
+	// except for our own replies
+	if messageEvent.SubType == "" || messageEvent.SubType == "bot_message" {
+		if rule, ok := c.matchWatchRule(messageEvent, time.Now()); ok {
+			if botUserID, err := c.BotUserID(ctx); err == nil && messageEvent.User != botUserID {
+				c.handleWatchedMessage(ctx, messageEvent, rule, processor)
+				return
+			}
+		}
+	}
+
+	// Skip bot messages, including our own replies to avoid loops
+	if messageEvent.BotID != "" || messageEvent.SubType == "bot_message" {
+		logger.Debugf("⏩ Ignoring bot message from: %s", messageEvent.BotID)
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "not a bot message", false,
+			fmt.Sprintf("bot_id=%s subtype=%s", messageEvent.BotID, messageEvent.SubType))
+		return
+	}
+	c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "not a bot message", true, "")
+
+	// Without an author there's no user to look up or filter on;
+	// thread broadcast echoes arrive like this
+	if messageEvent.User == "" {
+		logger.Debugf("⏩ Ignoring message without a user in channel: %s (subtype %q)", messageEvent.Channel, messageEvent.SubType)
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "has author", false, "message has no user")
+		return
+	}
+
+	// Direct messages to the bot can be commands, like "optout"
+	if messageEvent.ChannelType == "im" && !IsEdit(messageEvent) &&
+		c.textCommand(ctx, messageEvent.Channel, messageEvent.Timestamp, messageEvent.User, messageEvent.Text) {
+		return
+	}
+
+	// Anything else sent to the bot directly is translated right
+	// there, whoever sent it
+	if messageEvent.ChannelType == "im" {
+		c.handleDirectMessage(ctx, messageEvent, processor)
+		return
+	}
+
+	// Mentions of the bot are handled as explicit requests by the
+	// app_mention event, so don't translate them twice
+	if c.mentionsBot(ctx, messageEvent.Text) {
+		logger.Debugf("⏩ Ignoring message that mentions the bot (handled as app_mention)")
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "not a bot mention", false, "handled as app_mention")
+		return
+	}
+
+	// Debug all channel IDs
+	logger.Debugf("🔍 Checking channel access - Message channel: %s, Monitored channels: %v",
+		messageEvent.Channel, c.channelIDs)
+
+	// Process only messages from monitored channels if we're not monitoring all channels
+	if !c.monitorAllChannels && !c.channelIDs[messageEvent.Channel] {
+		logger.Debugf("⏩ Ignoring message from non-monitored channel: %s", messageEvent.Channel)
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "monitored channel", false, "channel is not in SLACK_CHANNEL_IDS")
+		return
+	}
+
+	if c.monitorAllChannels {
+		logger.Debugf("✅ Processing message from channel: %s (monitoring all channels)", c.channelLabel(ctx, messageEvent.Channel))
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "monitored channel", true, "monitoring all channels")
+	} else {
+		logger.Debugf("✅ Channel match found: %s", c.channelLabel(ctx, messageEvent.Channel))
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "monitored channel", true, "")
+	}
+
+	// Announcements are summarized for everyone, whoever posts them,
+	// once; edits don't summarize again
+	if c.announcementChannels[messageEvent.Channel] && messageEvent.ThreadTimestamp == "" && !IsEdit(messageEvent) && IsBroadcast(messageEvent.Text) {
+		c.handleAnnouncement(ctx, messageEvent, processor)
+		return
+	}
+
+	// Process only messages from target users
+	c.decisions.SetUser(messageEvent.Channel, messageEvent.Timestamp, messageEvent.User)
+	var user *slack.User
+	if c.needsAuthor(messageEvent.Channel) {
+		var err error
+		user, err = c.GetUserInfo(ctx, messageEvent.User)
+		if err != nil {
+			logger.Errorf("❌ Error getting user info: %v", err)
+			c.decisions.Failed(messageEvent.Channel, messageEvent.Timestamp, err)
+			return
+		}
+		logger.Debugf("👤 User info retrieved: %s (%s)", user.Name, user.ID)
+	} else {
+		// Everyone is a target and nobody is named, so the ID is all
+		// that's needed
+		user = &slack.User{ID: messageEvent.User}
+		logger.Debugf("👤 Skipped user info lookup for %s (everyone is a target user, anonymous channel)", user.ID)
+	}
+
+	// Debug all target users
+	c.targetUsersMu.RLock()
+	logger.Debugf("🔍 Checking user match - Message user: %s (%s), Target users: %v",
+		user.Name, messageEvent.User, c.targetUsers)
+	c.targetUsersMu.RUnlock()
+
+	if !c.isTargetUser(user) {
+		logger.Debugf("⏩ Ignoring message from non-target user: %s (%s)", user.Name, messageEvent.User)
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "target user", false,
+			fmt.Sprintf("%s is not in SLACK_TARGET_USERS", user.Name))
+		return
+	}
+	if c.allTargetUsers {
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "target user", true, "SLACK_TARGET_USERS is *")
+	} else {
+		c.decisions.Step(messageEvent.Channel, messageEvent.Timestamp, "target user", true, user.Name)
+	}
+
+	logger.Debugf("✅ User match found: %s", user.Name)
+	logger.Debugf("🎯 Processing message: '%s'", messageEvent.Text)
+
+	// Process the message
+	start := time.Now()
+	err := processor(ctx, messageEvent, user)
+	logger = logger.With(logging.Duration(time.Since(start)))
+	if err != nil {
+		c.processingFailed(ctx, logger, "message", messageEvent.Channel, messageEvent.User, messageEvent.Timestamp, err)
+	} else {
+		logger.Infof("✅ Successfully processed message from user: %s", user.Name)
+	}
+}
+
+// disconnectReason describes why Slack closed the connection, from the
+// disconnect request it sent
+func disconnectReason(evt socketmode.Event) string {
+	if evt.Request == nil || evt.Request.Reason == "" {
+		return "no reason given"
+	}
+	if evt.Request.DebugInfo.Host != "" {
+		return fmt.Sprintf("%s (host %s)", evt.Request.Reason, evt.Request.DebugInfo.Host)
+	}
+	return evt.Request.Reason
+}
+
+// processWithUser looks up the author of an on-demand message and passes
+// both to the processor
+func (c *Client) processWithUser(ctx context.Context, processor Processor, event *IncomingMessage) error {
+	user, err := c.GetUserInfo(ctx, event.User)
+	if err != nil {
+		return err
+	}
+	return processor(ctx, event, user)
+}
+
+// resolveUserEmails looks up users given by email and adds their IDs to
+// users. Failures are logged right away and reported again by VerifySetup,
+// since such a user would otherwise silently never match.
+func (c *Client) resolveUserEmails(ctx context.Context, emails []string, users map[string]bool) {
+	for _, email := range emails {
+		var user *slack.User
+		err := c.withRateLimitRetry(ctx, func() error {
+			var err error
+			user, err = c.api.GetUserByEmailContext(ctx, email)
+			return err
+		})
+		if err != nil {
+			c.logger.Errorf("❌ User email %s could not be resolved: %v", email, err)
+			c.unresolvedEmails[email] = err
+			continue
+		}
+
+		c.logger.Debugf("Resolved user %s to %s (%s)", email, user.Name, user.ID)
+		users[user.ID] = true
+	}
+}
+
+// needsAuthor reports whether messages in a channel need their author
+// looked up before processing. Only when everyone is a target user, the
+// translation names nobody and exclusions are all by user ID can the
+// users.info call be skipped.
+func (c *Client) needsAuthor(channelID string) bool {
+	if !c.allTargetUsers || !(c.anonymousAll || c.anonymousChannels[channelID]) {
+		return true
+	}
+	for user := range c.excludedUsers {
+		if !userIDPattern.MatchString(user) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTargetUser reports whether a user is in SLACK_TARGET_USERS, by ID or
+// legacy username, or in one of SLACK_TARGET_USERGROUPS. Display names are
+// only matched through the IDs verification resolved them to, since anyone
+// can take any display name. With "*" every user who isn't a bot is.
+func (c *Client) isTargetUser(user *slack.User) bool {
+	if c.allTargetUsers {
+		return !user.IsBot
+	}
+	c.targetUsersMu.RLock()
+	target := c.targetUsers[user.ID] || c.targetUsers[user.Name]
+	c.targetUsersMu.RUnlock()
+	return target || c.usergroups.contains(user.ID)
+}
+
+// addTargetNames adds the IDs of usernames and display names in
+// SLACK_TARGET_USERS, as resolved by verification, to the target users
+func (c *Client) addTargetNames(resolved map[string]string) {
+	c.targetUsersMu.Lock()
+	defer c.targetUsersMu.Unlock()
+
+	c.targetNames = resolved
+	for _, id := range resolved {
+		c.targetUsers[id] = true
+	}
+}
+
+// indexUsersByName maps usernames and display names to users. Usernames
+// win over display names, which aren't unique.
+func indexUsersByName(users []slack.User) map[string]slack.User {
+	byName := make(map[string]slack.User, len(users))
+	for _, user := range users {
+		if user.Profile.DisplayName != "" {
+			byName[user.Profile.DisplayName] = user
+		}
+	}
+	for _, user := range users {
+		byName[user.Name] = user
+	}
+	return byName
+}
@@ -0,0 +1,221 @@
+package slack
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/slacktest"
+)
+
+// testConfig is the configuration of a client monitoring every channel
+// for every user, with the defaults config.Load would fill in
+func testConfig() *config.Config {
+	return &config.Config{
+		SlackBotToken:            "xoxb-test",
+		SlackAppToken:            "xapp-test",
+		SlackAllTargetUsers:      true,
+		UserCacheTTL:             time.Minute,
+		ChannelCacheTTL:          time.Minute,
+		StartupReadyTimeout:      5 * time.Second,
+		WorkerPoolSize:           4,
+		ShutdownGrace:            time.Second,
+		HealthGracePeriod:        time.Minute,
+		AdminAlertInterval:       time.Hour,
+		UsergroupRefreshInterval: time.Hour,
+	}
+}
+
+// newTestClient creates a client talking to server
+func newTestClient(t *testing.T, server *slacktest.Server, cfg *config.Config) *Client {
+	t.Helper()
+	c, err := New(cfg, server.HTTPClient(), testLogger())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func testLogger() *logging.Logger {
+	return logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+}
+
+const (
+	testChannel = "C0000001"
+	testUser    = "U0000001"
+)
+
+// recorder is a processor remembering the text of the messages it was
+// given. Messages containing panicOn make it panic.
+type recorder struct {
+	panicOn string
+
+	mu    sync.Mutex
+	texts []string
+}
+
+func (r *recorder) process(ctx context.Context, event *IncomingMessage, user *slack.User) error {
+	if r.panicOn != "" && strings.Contains(event.Text, r.panicOn) {
+		panic("processor blew up on " + event.Text)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts = append(r.texts, event.Text)
+	return nil
+}
+
+func (r *recorder) processed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.texts...)
+}
+
+// runClient connects c to server and processes events with processor
+// until the test ends
+func runClient(t *testing.T, server *slacktest.Server, c *Client, processor Processor) {
+	t.Helper()
+	server.AddUser(slack.User{ID: testUser, Name: "alice"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := c.Start(ctx); err != nil {
+			t.Errorf("Start: %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		c.ProcessEvents(ctx, processor)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+}
+
+// A panic while handling one event is contained to that event
+func TestPanickingProcessorIsRecovered(t *testing.T) {
+	server := slacktest.NewServer(t)
+	c := newTestClient(t, server, testConfig())
+	r := &recorder{panicOn: "boom"}
+	runClient(t, server, c, r.process)
+
+	server.SendEvent("", slacktest.Message(testChannel, testUser, "boom goes the processor", server.NextTS()))
+	server.WaitFor(5*time.Second, "the panic to be recovered", func() bool { return c.Panics() == 1 })
+
+	server.SendEvent("", slacktest.Message(testChannel, testUser, "still here fr", server.NextTS()))
+	server.WaitFor(5*time.Second, "the next event to be processed", func() bool { return len(r.processed()) == 1 })
+	if got := r.processed(); got[0] != "still here fr" {
+		t.Errorf("processed %q, want the event after the panic", got)
+	}
+	if got := c.Panics(); got != 1 {
+		t.Errorf("Panics() = %d, want 1", got)
+	}
+}
+
+// Events replayed while reconnecting can arrive without a request to ack
+func TestDispatchEventWithoutRequest(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.AddUser(slack.User{ID: testUser, Name: "alice"})
+	c := newTestClient(t, server, testConfig())
+	r := &recorder{}
+
+	message := socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type: slackevents.CallbackEvent,
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Type: string(slackevents.Message),
+				Data: &slackevents.MessageEvent{Type: "message", Channel: testChannel, ChannelType: "channel",
+					User: testUser, Text: "replayed without a request", TimeStamp: server.NextTS()},
+			},
+		},
+	}
+	events := []socketmode.Event{
+		message,
+		{Type: socketmode.EventTypeInteractive, Data: slack.InteractionCallback{Type: slack.InteractionTypeBlockActions}},
+		{Type: socketmode.EventTypeSlashCommand, Data: slack.SlashCommand{Command: "/genalpha", Text: "help", ChannelID: testChannel, UserID: testUser}},
+	}
+
+	ctx := context.Background()
+	pool := newWorkerPool(1, 10, false, func() {})
+	connected := false
+	for _, evt := range events {
+		if evt.Request != nil {
+			t.Fatalf("%s event has a request", evt.Type)
+		}
+		c.dispatchEvent(ctx, ctx, evt, pool, r.process, &connected)
+	}
+	pool.close()
+	_, idle := c.inFlight.drain()
+	<-idle
+
+	if got := c.Panics(); got != 0 {
+		t.Errorf("%d events panicked, want none", got)
+	}
+	if got := r.processed(); len(got) != 1 || got[0] != "replayed without a request" {
+		t.Errorf("processed %q, want the message handled despite not being acked", got)
+	}
+}
+
+// Events are acked as soon as the connection is up, even while startup
+// verification is still running, and processed once it's done
+func TestEventsAckedDuringVerification(t *testing.T) {
+	server := slacktest.NewServer(t)
+	verifying := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	server.Handle("auth.test", func(url.Values) any {
+		once.Do(func() {
+			close(verifying)
+			<-release
+		})
+		return map[string]any{"ok": true, "user": "genalpha", "user_id": slacktest.BotUserID, "bot_id": slacktest.BotID,
+			"team": "Test Workspace", "team_id": "T0000001"}
+	})
+	cfg := testConfig()
+	cfg.Logs = true
+	cfg.StartupReadyTimeout = time.Minute
+	c := newTestClient(t, server, cfg)
+	r := &recorder{}
+	runClient(t, server, c, r.process)
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	server.WaitFor(5*time.Second, "the socket mode connection", server.Connected)
+	<-verifying
+	time.Sleep(time.Second)
+
+	envelope := server.SendEvent("", slacktest.Message(testChannel, testUser, "sent right after connecting", server.NextTS()))
+	server.WaitFor(5*time.Second, "the event to be acked", func() bool { return server.Acked(envelope) })
+	if phase := c.Phase(); phase != PhaseVerifying {
+		t.Fatalf("phase %q when the event was acked, want %q", phase, PhaseVerifying)
+	}
+	if got := r.processed(); len(got) != 0 {
+		t.Fatalf("processed %q before verification finished", got)
+	}
+
+	close(release)
+	server.WaitFor(5*time.Second, "the event to be processed", func() bool { return len(r.processed()) == 1 })
+	if phase := c.Phase(); phase != PhaseReady {
+		t.Errorf("phase %q after processing, want %q", phase, PhaseReady)
+	}
+}
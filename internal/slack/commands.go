@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/user/slack-bot-api/internal/command"
+	"github.com/user/slack-bot-api/internal/explain"
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// registerCommands adds the slash subcommands to the client's registry
+func (c *Client) registerCommands() {
+	c.commands.Register(command.Command{
+		Name:        "explain",
+		Usage:       "[message link]",
+		Description: "show why the bot did or didn't translate a message",
+		Handler:     c.explainCommand,
+	})
+	c.commands.Register(command.Command{
+		Name:        "rules",
+		Description: "list the watch rules whose messages are always translated",
+		Enabled:     func() bool { return len(c.watchRules) > 0 },
+		Handler:     c.rulesCommand,
+	})
+}
+
+// handleSlashCommand dispatches a /genalpha command and replies ephemerally
+func (c *Client) handleSlashCommand(ctx context.Context, cmd slack.SlashCommand) {
+	ctx = logging.NewContext(ctx, c.logger.With(
+		logging.EventType(string(socketmode.EventTypeSlashCommand)),
+		logging.Channel(cmd.ChannelID),
+		logging.User(cmd.UserID),
+	))
+
+	req := command.Parse(cmd.Command, cmd.Text)
+	req.UserID = cmd.UserID
+	req.ChannelID = cmd.ChannelID
+	req.IsAdmin = c.adminUsers[cmd.UserID]
+
+	reply := c.commands.Dispatch(ctx, req)
+
+	if err := c.PostEphemeral(ctx, cmd.ChannelID, cmd.UserID, reply); err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error replying to slash command: %v", err)
+	}
+}
+
+// explainCommand renders the decision trail for the message given by a
+// permalink, or for the most recent message in the channel when omitted
+func (c *Client) explainCommand(ctx context.Context, req command.Request) string {
+	var record explain.Record
+	var found bool
+
+	if len(req.Args) > 0 {
+		channel, ts, err := explain.ParsePermalink(req.Args[0])
+		if err != nil {
+			return "⚠️ " + err.Error()
+		}
+		record, found = c.decisions.Get(channel, ts)
+	} else {
+		record, found = c.decisions.Latest(req.ChannelID)
+	}
+
+	if !found {
+		return "🤷 No decision record for that message. Only recent messages the bot received are kept."
+	}
+	return explain.Format(record)
+}
+
+// Commands returns the registry of /genalpha subcommands, so other parts of
+// the bot can add their own
+func (c *Client) Commands() *command.Registry {
+	return c.commands
+}
+
+// IsAdmin reports whether a user is listed in ADMIN_USERS
+func (c *Client) IsAdmin(userID string) bool {
+	return c.adminUsers[userID]
+}
@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// errProcessingTimeout marks a message given up on after
+// MESSAGE_PROCESSING_TIMEOUT
+var errProcessingTimeout = errors.New("MESSAGE_PROCESSING_TIMEOUT exceeded")
+
+// WithDeadline wraps a processor so that each message gets at most
+// MESSAGE_PROCESSING_TIMEOUT, LLM requests and their retries included.
+// Retries stop as soon as the deadline passes, so a slow provider costs
+// one warning per message rather than a storm of retries. Messages held
+// back and processed later get a deadline of their own this way.
+func (c *Client) WithDeadline(processor Processor) Processor {
+	if c.processingTimeout <= 0 {
+		return processor
+	}
+	return func(ctx context.Context, event *IncomingMessage, user *slack.User) error {
+		ctx, cancel := context.WithTimeout(ctx, c.processingTimeout)
+		defer cancel()
+
+		err := processor(ctx, event, user)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", errProcessingTimeout, c.processingTimeout)
+		}
+		return err
+	}
+}
+
+// processingFailed logs a message the processor failed on, records it in
+// the decision log and alerts ADMIN_CHANNEL_ID. Timeouts are expected
+// under load and get a short warning instead of an error.
+func (c *Client) processingFailed(ctx context.Context, logger *logging.Logger, what, channelID, userID, ts string, err error) {
+	if errors.Is(err, errProcessingTimeout) {
+		logger.Warnf("⏱️ Gave up on %s %s: %v", what, ts, err)
+	} else {
+		logger.Errorf("❌ Error processing %s: %v", what, err)
+	}
+	c.decisions.Failed(channelID, ts, err)
+	c.alertAdmins(ctx, what, channelID, userID, err)
+}
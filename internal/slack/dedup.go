@@ -0,0 +1,77 @@
+package slack
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// dedupCapacity is the number of recent event keys remembered
+	dedupCapacity = 1000
+
+	// dedupTTL is how long an event key is remembered
+	dedupTTL = 10 * time.Minute
+)
+
+// recentSet remembers recently seen keys, bounded both by count and age.
+// Slack redelivers events it didn't see acked in time, so this is used to
+// drop the retries.
+type recentSet struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type recentEntry struct {
+	key    string
+	seenAt time.Time
+}
+
+func newRecentSet(capacity int, ttl time.Duration) *recentSet {
+	return &recentSet{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// seen reports whether key was already added within the TTL, adding it if
+// it wasn't
+func (s *recentSet) seen(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// Expire old entries from the front (oldest first)
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		entry := front.Value.(*recentEntry)
+		if now.Sub(entry.seenAt) < s.ttl {
+			break
+		}
+		s.order.Remove(front)
+		delete(s.entries, entry.key)
+	}
+
+	if _, ok := s.entries[key]; ok {
+		return true
+	}
+
+	s.entries[key] = s.order.PushBack(&recentEntry{key: key, seenAt: now})
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*recentEntry).key)
+	}
+	return false
+}
+
+// DuplicateEvents returns how many redelivered events have been dropped
+func (c *Client) DuplicateEvents() uint64 {
+	return atomic.LoadUint64(&c.duplicateEvents)
+}
@@ -0,0 +1,24 @@
+package slack
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// websocketDialer returns the dialer of the Socket Mode connection, using
+// the same proxy and TLS settings as httpClient's requests. Socket Mode
+// otherwise dials with the websocket package's defaults, which know nothing
+// of a private CA.
+func websocketDialer(httpClient *http.Client) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+	}
+	if transport, ok := httpClient.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+	return dialer
+}
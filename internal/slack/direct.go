@@ -0,0 +1,29 @@
+package slack
+
+import (
+	"context"
+)
+
+// MessageTypeDirect marks messages sent to the bot in a direct message.
+// They are translated on demand and answered in the same conversation.
+const MessageTypeDirect = "direct_message"
+
+// IsDirect reports whether a message was sent to the bot in a direct
+// message
+func IsDirect(event *IncomingMessage) bool {
+	return event.Type == MessageTypeDirect
+}
+
+// handleDirectMessage translates a message someone sent the bot directly.
+// The DM is a playground anyone can use, so the channel and target user
+// filters don't apply.
+func (c *Client) handleDirectMessage(ctx context.Context, event *IncomingMessage, processor Processor) {
+	c.loggerFor(ctx).Infof("💬 Direct message received from %s", event.User)
+	c.decisions.Step(event.Channel, event.Timestamp, "direct message", true, "sent to the bot, channel and user filters skipped")
+
+	event.Type = MessageTypeDirect
+	c.decisions.SetUser(event.Channel, event.Timestamp, event.User)
+	if err := c.processWithUser(ctx, processor, event); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "direct message", event.Channel, event.User, event.Timestamp, err)
+	}
+}
@@ -0,0 +1,106 @@
+package slack
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// inFlight counts the messages being processed, so shutdown can wait for
+// them. Once draining, no new ones are started.
+type inFlight struct {
+	mu         sync.Mutex
+	running    int
+	notStarted int
+	draining   bool
+	idle       chan struct{}
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{}
+}
+
+// begin marks a message as being processed, reporting false when shutting
+// down, in which case it must not be processed. Every successful begin is
+// followed by an end.
+func (f *inFlight) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.draining {
+		return false
+	}
+	f.running++
+	return true
+}
+
+// end marks a message begun as done
+func (f *inFlight) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.running--
+	if f.running == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+}
+
+// skip counts a queued message that wasn't started because of shutdown
+func (f *inFlight) skip() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.notStarted++
+}
+
+// drain stops new messages from beginning, and returns how many are still
+// running and a channel closed once none are
+func (f *inFlight) drain() (int, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.draining = true
+	idle := make(chan struct{})
+	if f.running == 0 {
+		close(idle)
+	} else {
+		f.idle = idle
+	}
+	return f.running, idle
+}
+
+// counts returns how many messages are running, and how many queued ones
+// weren't started
+func (f *inFlight) counts() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.running, f.notStarted
+}
+
+// drain waits up to SHUTDOWN_GRACE for the messages being processed to
+// finish, then cancels the ones still running, and stops the worker pool.
+// Events still queued are dropped.
+func (c *Client) drain(pool *workerPool, cancelWork context.CancelFunc) {
+	running, idle := c.inFlight.drain()
+	c.logger.Infof("🛑 Draining %d in-flight messages, waiting up to %s...", running, c.shutdownGrace)
+
+	grace := time.NewTimer(c.shutdownGrace)
+	defer grace.Stop()
+	abandoned := 0
+	select {
+	case <-idle:
+	case <-grace.C:
+		abandoned, _ = c.inFlight.counts()
+		c.logger.Warnf("⚠️ SHUTDOWN_GRACE of %s is over, abandoning %d messages still in flight", c.shutdownGrace, abandoned)
+	}
+
+	// Cancelling makes the abandoned ones return promptly, their LLM and
+	// Slack requests with them
+	cancelWork()
+	pool.close()
+
+	_, notStarted := c.inFlight.counts()
+	c.logger.Infof("🛑 Drained: %d messages completed, %d abandoned, %d queued events not started", running-abandoned, abandoned, notStarted)
+}
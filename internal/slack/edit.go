@@ -0,0 +1,32 @@
+package slack
+
+import "github.com/slack-go/slack/slackevents"
+
+// subTypeMessageChanged is the subtype of events for edited messages
+const subTypeMessageChanged = "message_changed"
+
+// IsEdit reports whether a message event is an edit of an earlier message
+func IsEdit(event *IncomingMessage) bool {
+	return event.SubType == subTypeMessageChanged
+}
+
+// editedMessage converts a message_changed event into an incoming message
+// for the edited message, keeping the message_changed subtype so it can be told
+// apart from new messages. It reports false for edits that don't change the
+// text, like link previews being added.
+func editedMessage(event *slackevents.MessageEvent) (*IncomingMessage, bool) {
+	edited := event.Message
+	if edited == nil {
+		return nil, false
+	}
+	if event.PreviousMessage != nil && event.PreviousMessage.Text == edited.Text {
+		return nil, false
+	}
+
+	message := newIncomingMessage(edited)
+	message.Channel = event.Channel
+	message.ChannelType = event.ChannelType
+	message.EventTimestamp = event.EventTimeStamp
+	message.SubType = subTypeMessageChanged
+	return message, true
+}
@@ -0,0 +1,86 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// userIDPattern matches Slack user IDs; other user list entries are
+// usernames or display names
+var userIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+
+// parseUsers splits the entries of a user list into usernames, display
+// names and IDs, and emails, which still need to be resolved to IDs
+func parseUsers(entries []string) (map[string]bool, []string) {
+	users := make(map[string]bool)
+	var emails []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "@"):
+			emails = append(emails, entry)
+		default:
+			users[entry] = true
+		}
+	}
+	return users, emails
+}
+
+// isExcludedUser reports whether a user is in SLACK_EXCLUDE_USERS, by ID,
+// username or display name
+func (c *Client) isExcludedUser(user *slack.User) bool {
+	return c.excludedUsers[user.ID] || c.excludedUsers[user.Name] ||
+		(user.Profile.DisplayName != "" && c.excludedUsers[user.Profile.DisplayName])
+}
+
+// exclusion returns why a message in a channel by a user must not be
+// translated, or "" when nothing excludes it
+func (c *Client) exclusion(channelID string, user *slack.User) string {
+	if c.excludedChannels[channelID] {
+		return "channel is in SLACK_EXCLUDE_CHANNEL_IDS"
+	}
+	if user != nil && c.isExcludedUser(user) {
+		// Authors that weren't looked up only have an ID
+		name := user.Name
+		if name == "" {
+			name = user.ID
+		}
+		return fmt.Sprintf("%s is in SLACK_EXCLUDE_USERS", name)
+	}
+	return ""
+}
+
+// excluding wraps a processor so that messages in excluded channels and
+// from excluded users are dropped, however they arrived: exclusions win
+// over the channel and target user lists, and over mentions, reactions,
+// shortcuts and watch rules
+func (c *Client) excluding(processor Processor) Processor {
+	return func(ctx context.Context, event *IncomingMessage, user *slack.User) error {
+		if reason := c.exclusion(event.Channel, user); reason != "" {
+			c.loggerFor(ctx).Debugf("⏩ Skipping message %s: %s", event.Timestamp, reason)
+			c.decisions.Step(event.Channel, event.Timestamp, "not excluded", false, reason)
+			return nil
+		}
+		return processor(ctx, event, user)
+	}
+}
+
+// warnIncludedAndExcluded warns about channels and users that are both
+// included and excluded
+func (c *Client) warnIncludedAndExcluded() {
+	for channelID := range c.excludedChannels {
+		if c.channelIDs[channelID] {
+			c.logger.Warnf("⚠️ Channel %s is in both SLACK_CHANNEL_IDS and SLACK_EXCLUDE_CHANNEL_IDS; it won't be translated", channelID)
+		}
+	}
+	for user := range c.excludedUsers {
+		if c.targetUsers[user] {
+			c.logger.Warnf("⚠️ User %s is in both SLACK_TARGET_USERS and SLACK_EXCLUDE_USERS; they won't be translated", user)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// connection tracks the socket mode connection as reported by its events
+type connection struct {
+	mu              sync.Mutex
+	connected       bool
+	helloReceived   bool
+	lastConnectedAt time.Time
+	downSince       time.Time
+	lastError       string
+	// failures counts connection attempts that failed since the last
+	// successful one
+	failures int
+}
+
+// outage describes a connection that was just re-established
+type outage struct {
+	failures int
+	duration time.Duration
+}
+
+func newConnection() *connection {
+	// Not being connected yet counts as being down since startup
+	return &connection{downSince: time.Now()}
+}
+
+// setConnected marks the connection up and returns the outage it ended
+func (c *connection) setConnected() outage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ended := outage{failures: c.failures, duration: time.Since(c.downSince)}
+	c.connected = true
+	c.lastConnectedAt = time.Now()
+	c.lastError = ""
+	c.failures = 0
+	return ended
+}
+
+// setFailed records a failed connection attempt, returning how many failed
+// in a row
+func (c *connection) setFailed(reason string) int {
+	c.setDown(reason)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures++
+	return c.failures
+}
+
+func (c *connection) setHello() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.helloReceived = true
+}
+
+func (c *connection) setDown(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connected {
+		c.downSince = time.Now()
+	}
+	c.connected = false
+	c.lastError = reason
+}
+
+// Healthy reports whether the socket mode connection is up, or has been
+// down for no longer than the grace period, which covers Slack's routine
+// reconnects
+func (c *Client) Healthy() bool {
+	return c.HealthProblem() == ""
+}
+
+// HealthProblem describes why the client isn't healthy, empty when it is
+func (c *Client) HealthProblem() string {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+
+	if c.conn.connected {
+		return ""
+	}
+	down := time.Since(c.conn.downSince)
+	if down <= c.healthGracePeriod {
+		return ""
+	}
+
+	problem := fmt.Sprintf("socket mode not connected for %s", down.Round(time.Second))
+	if c.conn.lastConnectedAt.IsZero() {
+		problem = fmt.Sprintf("socket mode hasn't connected in the %s since startup", down.Round(time.Second))
+	}
+	if c.conn.lastError != "" {
+		problem += " (" + c.conn.lastError + ")"
+	}
+	return problem
+}
+
+// LastConnectedAt returns when the socket mode connection was last
+// established, zero if it never was
+func (c *Client) LastConnectedAt() time.Time {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+
+	return c.conn.lastConnectedAt
+}
+
+// Connected reports whether the socket mode connection is currently up
+func (c *Client) Connected() bool {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+
+	return c.conn.connected
+}
+
+// ConnectionFailures returns how many socket mode connection attempts in a
+// row have failed, 0 while connected
+func (c *Client) ConnectionFailures() int {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+
+	return c.conn.failures
+}
+
+// Ready reports whether Slack has said hello on a socket mode connection at
+// least once, i.e. the bot has been able to receive events
+func (c *Client) Ready() bool {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+
+	return c.conn.helloReceived
+}
+
+// alertRecovery tells the connection alert channel that the connection is
+// back after an outage with enough failed attempts. It's posted once per
+// outage, not for every failure.
+func (c *Client) alertRecovery(ctx context.Context, ended outage) {
+	if c.connectionAlertChannel == "" || ended.failures < c.connectionAlertFailures {
+		return
+	}
+
+	text := fmt.Sprintf("⚠️ The bot lost its Slack connection for %s and failed to reconnect %d times in a row. It's back now; messages sent in the meantime weren't translated.",
+		ended.duration.Round(time.Second), ended.failures)
+	if _, _, err := c.PostMessage(ctx, c.connectionAlertChannel, text); err != nil {
+		c.logger.Errorf("❌ Error posting connection recovery alert to %s: %v", c.connectionAlertChannel, err)
+	}
+}
@@ -0,0 +1,92 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+const (
+	// MessageTypeShortcut marks message events produced from the "Translate
+	// to Gen Alpha" message shortcut. Like mentions, the bot replies to
+	// these in the thread given by ThreadTimestamp.
+	MessageTypeShortcut = "message_action"
+
+	// TranslateShortcutCallbackID is the callback ID of the message
+	// shortcut configured in the Slack app
+	TranslateShortcutCallbackID = "translate_gen_alpha"
+)
+
+// IsOnDemand reports whether a message was explicitly requested to be
+// translated (mention, shortcut, reaction or direct message) rather than
+// picked up by the filters
+func IsOnDemand(event *IncomingMessage) bool {
+	return event.Type == MessageTypeMention || event.Type == MessageTypeShortcut || event.Type == MessageTypeReaction ||
+		event.Type == MessageTypeDirect
+}
+
+// handleInteraction handles interactive payloads. It runs after the request
+// has been acked, so slow work doesn't hold up Slack's 3 second deadline.
+func (c *Client) handleInteraction(ctx context.Context, callback slack.InteractionCallback, processor Processor) {
+	ctx = logging.NewContext(ctx, c.logger.With(
+		logging.EventType(string(callback.Type)),
+		logging.Channel(callback.Channel.ID),
+		logging.User(callback.User.ID),
+	))
+
+	switch callback.Type {
+	case slack.InteractionTypeMessageAction:
+		if callback.CallbackID != TranslateShortcutCallbackID {
+			c.loggerFor(ctx).Debugf("ℹ️ Ignoring message shortcut with unknown callback ID: %s", callback.CallbackID)
+			return
+		}
+		c.handleTranslateShortcut(ctx, callback, processor)
+	case slack.InteractionTypeBlockActions:
+		c.handleBlockActions(ctx, callback)
+	default:
+		c.loggerFor(ctx).Debugf("ℹ️ Received unhandled interaction type: %s", callback.Type)
+	}
+}
+
+// handleTranslateShortcut translates the message the shortcut was used on
+// and replies in its thread. Shortcuts are explicit requests, so the
+// channel and target user filters don't apply.
+func (c *Client) handleTranslateShortcut(ctx context.Context, callback slack.InteractionCallback, processor Processor) {
+	message := callback.Message
+	channelID := callback.Channel.ID
+
+	c.loggerFor(ctx).Infof("⚡ Translate shortcut used by %s on message %s in channel %s", callback.User.ID, message.Timestamp, channelID)
+	c.decisions.Step(channelID, message.Timestamp, "message shortcut", true,
+		"requested by "+callback.User.ID+", channel and user filters skipped")
+
+	if message.Text == "" || message.User == "" {
+		c.loggerFor(ctx).Debugf("⏩ Nothing to translate in shortcut message")
+		c.decisions.Step(channelID, message.Timestamp, "has text", false, "message has no text or author")
+		if err := c.PostEphemeral(ctx, channelID, callback.User.ID, "🤷 That message has no text I can translate."); err != nil {
+			c.loggerFor(ctx).Errorf("❌ Error replying to shortcut: %v", err)
+		}
+		return
+	}
+
+	threadTS := message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = message.Timestamp
+	}
+
+	messageEvent := &IncomingMessage{
+		Type:            MessageTypeShortcut,
+		Channel:         channelID,
+		User:            message.User,
+		Text:            message.Text,
+		Timestamp:       message.Timestamp,
+		ThreadTimestamp: threadTS,
+		Attachments:     message.Attachments,
+	}
+
+	c.decisions.SetUser(channelID, message.Timestamp, message.User)
+	if err := c.processWithUser(ctx, processor, messageEvent); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "shortcut", channelID, message.User, message.Timestamp, err)
+	}
+}
@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// MessageTypeMention marks message events that were produced from an
+// app_mention, i.e. an explicit request to translate. The bot replies to
+// these in the thread given by ThreadTimestamp.
+const MessageTypeMention = "app_mention"
+
+// BotUserID returns the bot's own user ID, looked up once via auth.test
+func (c *Client) BotUserID(ctx context.Context) (string, error) {
+	c.botUserIDMu.Lock()
+	defer c.botUserIDMu.Unlock()
+
+	if c.botUserID != "" {
+		return c.botUserID, nil
+	}
+
+	authTest, err := c.api.AuthTestContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error looking up bot user ID: %w", err)
+	}
+
+	c.botUserID = authTest.UserID
+	return c.botUserID, nil
+}
+
+// mentionsBot reports whether text contains a mention of the bot
+func (c *Client) mentionsBot(ctx context.Context, text string) bool {
+	botUserID, err := c.BotUserID(ctx)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(text, "<@"+botUserID+">")
+}
+
+// stripMention removes every mention of the given user from text
+func stripMention(text, userID string) string {
+	text = strings.ReplaceAll(text, "<@"+userID+">", "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// handleAppMention turns an explicit mention of the bot into a message for
+// the processor. Outside a thread the text after the mention is translated;
+// inside a thread the thread's parent message is. Mentions are explicit
+// requests, so the channel and target user filters don't apply.
+func (c *Client) handleAppMention(ctx context.Context, mention *slackevents.AppMentionEvent, processor Processor) {
+	ctx = logging.NewContext(ctx, c.loggerFor(ctx).With(logging.Channel(mention.Channel), logging.User(mention.User)))
+	c.loggerFor(ctx).Infof("📣 Mention received - Channel: %s, User: %s", mention.Channel, mention.User)
+
+	if mention.BotID != "" {
+		c.loggerFor(ctx).Debugf("⏩ Ignoring mention from bot: %s", mention.BotID)
+		return
+	}
+	c.decisions.Step(mention.Channel, mention.TimeStamp, "app mention", true, "explicit request, channel and user filters skipped")
+
+	botUserID, err := c.BotUserID(ctx)
+	if err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error handling mention: %v", err)
+		c.decisions.Failed(mention.Channel, mention.TimeStamp, err)
+		return
+	}
+
+	// "@genalpha optout" and the like are commands, not requests
+	if c.textCommand(ctx, mention.Channel, mention.TimeStamp, mention.User, mention.Text) {
+		return
+	}
+
+	messageEvent := &IncomingMessage{
+		Type:            MessageTypeMention,
+		Channel:         mention.Channel,
+		User:            mention.User,
+		Text:            stripMention(mention.Text, botUserID),
+		Timestamp:       mention.TimeStamp,
+		ThreadTimestamp: mention.ThreadTimeStamp,
+	}
+
+	if mention.ThreadTimeStamp != "" && mention.ThreadTimeStamp != mention.TimeStamp {
+		parent, err := c.threadParent(ctx, mention.Channel, mention.ThreadTimeStamp)
+		if err != nil {
+			c.loggerFor(ctx).Errorf("❌ Error fetching thread parent: %v", err)
+			c.decisions.Failed(mention.Channel, mention.TimeStamp, err)
+			return
+		}
+
+		messageEvent.User = parent.User
+		messageEvent.Text = stripMention(parent.Text, botUserID)
+		messageEvent.Attachments = parent.Attachments
+	} else {
+		// Reply in a new thread under the mention itself
+		messageEvent.ThreadTimestamp = mention.TimeStamp
+	}
+
+	if messageEvent.Text == "" || messageEvent.User == "" {
+		c.loggerFor(ctx).Debugf("⏩ Nothing to translate in mention")
+		c.decisions.Step(mention.Channel, mention.TimeStamp, "has text", false, "mention had no text to translate")
+		return
+	}
+
+	c.decisions.SetUser(mention.Channel, mention.TimeStamp, messageEvent.User)
+	if err := c.processWithUser(ctx, processor, messageEvent); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "mention", mention.Channel, messageEvent.User, mention.TimeStamp, err)
+	}
+}
+
+// threadParent fetches the first message of a thread
+func (c *Client) threadParent(ctx context.Context, channelID, threadTS string) (*slack.Message, error) {
+	msgs, _, _, err := c.api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting thread replies: %w", err)
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("thread %s in channel %s has no messages", threadTS, channelID)
+	}
+	return &msgs[0], nil
+}
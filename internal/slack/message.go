@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// IncomingMessage is a message to translate, as handed to the Processor.
+// Channel messages are converted from their Events API event; mentions,
+// reactions and shortcuts build one from the message they point at, so
+// fields only events carry, like ChannelType and ClientMsgID, may be empty.
+type IncomingMessage struct {
+	// Type is empty for channel messages, or one of the MessageType
+	// constants for messages translated on demand
+	Type            string
+	Channel         string
+	ChannelType     string
+	User            string
+	Text            string
+	Timestamp       string
+	ThreadTimestamp string
+	EventTimestamp  string
+	ClientMsgID     string
+	SubType         string
+	BotID           string
+	Attachments     []slack.Attachment
+	Files           []slackevents.File
+	// Edited is set on edited messages
+	Edited *slackevents.Edited
+}
+
+// newIncomingMessage converts a message event from the Events API
+func newIncomingMessage(event *slackevents.MessageEvent) *IncomingMessage {
+	return &IncomingMessage{
+		Channel:         event.Channel,
+		ChannelType:     event.ChannelType,
+		User:            event.User,
+		Text:            event.Text,
+		Timestamp:       event.TimeStamp,
+		ThreadTimestamp: event.ThreadTimeStamp,
+		EventTimestamp:  event.EventTimeStamp,
+		ClientMsgID:     event.ClientMsgID,
+		SubType:         event.SubType,
+		BotID:           event.BotID,
+		Attachments:     event.Attachments,
+		Files:           event.Files,
+		Edited:          event.Edited,
+	}
+}
@@ -0,0 +1,22 @@
+package slack
+
+import "github.com/user/slack-bot-api/internal/metrics"
+
+var (
+	messagesReceived = metrics.NewCounter("slackbot_messages_received_total",
+		"Message events received from Slack, before any filtering")
+	socketReconnects = metrics.NewCounter("slackbot_socket_reconnects_total",
+		"Times the socket mode connection was re-established after the first connect")
+	socketConnectionErrors = metrics.NewCounter("slackbot_socket_connection_errors_total",
+		"Failed socket mode connection attempts")
+	postDuration = metrics.NewHistogram("slackbot_slack_post_duration_seconds",
+		"Latency of posting messages to Slack", metrics.LatencyBuckets)
+	postQueueWait = metrics.NewHistogram("slackbot_slack_post_queue_wait_seconds",
+		"Time posts waited for earlier posts to the same channel", metrics.LatencyBuckets)
+	rateLimitRetries = metrics.NewCounter("slackbot_slack_rate_limit_retries_total",
+		"Web API calls retried after Slack answered with a rate limit")
+	eventPanics = metrics.NewCounter("slackbot_event_panics_total",
+		"Events whose handling panicked and was recovered from")
+	rateLimitFailures = metrics.NewCounter("slackbot_slack_rate_limit_failures_total",
+		"Web API calls given up on after still being rate limited on the last retry")
+)
@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// workerPool runs queued jobs on a fixed number of workers. When ordered,
+// jobs with the same key always go to the same worker, so jobs for one
+// channel run in the order they were submitted.
+type workerPool struct {
+	queues  []chan func()
+	ordered bool
+	mu      sync.Mutex
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// newWorkerPool starts size workers with queueSize slots of buffer in total.
+// Every worker calls before() once before taking its first job.
+func newWorkerPool(size, queueSize int, ordered bool, before func()) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	perWorker := queueSize / size
+	if perWorker < 1 {
+		perWorker = 1
+	}
+
+	p := &workerPool{ordered: ordered}
+	if ordered {
+		p.queues = make([]chan func(), size)
+		for i := range p.queues {
+			p.queues[i] = make(chan func(), perWorker)
+		}
+	} else {
+		// Unordered jobs share one queue so any idle worker can take them
+		p.queues = []chan func(){make(chan func(), queueSize)}
+	}
+
+	for i := 0; i < size; i++ {
+		queue := p.queues[i%len(p.queues)]
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			before()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// submit queues a job without blocking, reporting false if the job's queue
+// is full or the pool is closed
+func (p *workerPool) submit(key string, job func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+
+	queue := p.queues[0]
+	if p.ordered {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		queue = p.queues[h.Sum32()%uint32(len(p.queues))]
+	}
+
+	select {
+	case queue <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops accepting jobs and waits for the workers to finish the jobs
+// they already have
+func (p *workerPool) close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
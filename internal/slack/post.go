@@ -0,0 +1,74 @@
+package slack
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// postInterval is the least time between two posts to the same channel.
+// Slack allows about one message per second per channel, bursts aside.
+const postInterval = time.Second
+
+// channelPosts serializes the bot's posts to each channel and spaces them
+// postInterval apart, so a busy channel stays inside Slack's rate limits
+// instead of running into them. It is safe for concurrent use.
+type channelPosts struct {
+	mu       sync.Mutex
+	channels map[string]*channelQueue
+}
+
+// channelQueue holds a token in turn while a post to its channel is in
+// flight
+type channelQueue struct {
+	turn chan struct{}
+	// last is when the previous post finished; only read and written
+	// while holding the turn
+	last time.Time
+}
+
+func newChannelPosts() *channelPosts {
+	return &channelPosts{channels: make(map[string]*channelQueue)}
+}
+
+func (p *channelPosts) queue(channelID string) *channelQueue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queue, ok := p.channels[channelID]
+	if !ok {
+		queue = &channelQueue{turn: make(chan struct{}, 1)}
+		p.channels[channelID] = queue
+	}
+	return queue
+}
+
+// do runs post once the posts to channelID queued before it are done and
+// postInterval has passed since the last one. It gives up when ctx is done
+// while waiting.
+func (p *channelPosts) do(ctx context.Context, channelID string, post func() error) error {
+	queue := p.queue(channelID)
+	start := time.Now()
+
+	select {
+	case queue.turn <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-queue.turn }()
+
+	if wait := time.Until(queue.last.Add(postInterval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	postQueueWait.ObserveDuration(time.Since(start))
+
+	err := post()
+	queue.last = time.Now()
+	return err
+}
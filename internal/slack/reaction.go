@@ -0,0 +1,142 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// MessageTypeReaction marks message events produced by someone adding the
+// trigger reaction. Like mentions, the bot replies to these in the thread
+// given by ThreadTimestamp.
+const MessageTypeReaction = "reaction_added"
+
+// ReactionHandler handles a reaction added to a message
+type ReactionHandler func(ctx context.Context, reaction *slackevents.ReactionAddedEvent)
+
+// HandleReaction registers the handler for reactions with the given emoji
+// name, without colons. Handlers must be registered before Start.
+func (c *Client) HandleReaction(name string, handler ReactionHandler) {
+	c.reactionHandlers[name] = handler
+}
+
+// handleReactionAdded passes a reaction to its registered handler, and
+// translates a message when the configured trigger reaction is added to
+// it. Reactions from anyone count, so the channel and target user filters
+// don't apply.
+func (c *Client) handleReactionAdded(ctx context.Context, reaction *slackevents.ReactionAddedEvent, processor Processor) {
+	if reaction.Item.Type != "message" {
+		return
+	}
+	if handler, ok := c.reactionHandlers[reaction.Reaction]; ok {
+		handler(logging.NewContext(ctx, c.loggerFor(ctx).With(logging.Channel(reaction.Item.Channel), logging.User(reaction.User))), reaction)
+	}
+	if c.triggerReaction == "" || reaction.Reaction != c.triggerReaction {
+		return
+	}
+
+	channelID := reaction.Item.Channel
+	ts := reaction.Item.Timestamp
+	ctx = logging.NewContext(ctx, c.loggerFor(ctx).With(logging.Channel(channelID), logging.User(reaction.User)))
+	c.loggerFor(ctx).Infof("💀 Trigger reaction :%s: added by %s to message %s in channel %s", reaction.Reaction, reaction.User, ts, channelID)
+
+	botUserID, err := c.BotUserID(ctx)
+	if err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error handling reaction: %v", err)
+		return
+	}
+
+	// Never translate our own messages, that way lies an infinite loop
+	if reaction.ItemUser == botUserID {
+		c.loggerFor(ctx).Debugf("⏩ Ignoring trigger reaction on the bot's own message")
+		return
+	}
+
+	message, err := c.fetchMessage(ctx, channelID, ts)
+	if err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error fetching reacted message: %v", err)
+		c.decisions.Failed(channelID, ts, err)
+		return
+	}
+
+	if message.BotID != "" || message.User == botUserID {
+		c.loggerFor(ctx).Debugf("⏩ Ignoring trigger reaction on a bot message")
+		return
+	}
+
+	// Only the first trigger reaction translates, later ones pile on
+	for _, r := range message.Reactions {
+		if r.Name == c.triggerReaction && r.Count > 1 {
+			c.loggerFor(ctx).Debugf("⏩ Message already has %d :%s: reactions, not translating again", r.Count, r.Name)
+			return
+		}
+	}
+
+	c.decisions.Step(channelID, ts, "trigger reaction", true,
+		fmt.Sprintf(":%s: added by %s, channel and user filters skipped", reaction.Reaction, reaction.User))
+
+	if message.Text == "" || message.User == "" {
+		c.decisions.Step(channelID, ts, "has text", false, "message has no text or author")
+		return
+	}
+
+	threadTS := message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = message.Timestamp
+	}
+
+	messageEvent := &IncomingMessage{
+		Type:            MessageTypeReaction,
+		Channel:         channelID,
+		User:            message.User,
+		Text:            message.Text,
+		Timestamp:       message.Timestamp,
+		ThreadTimestamp: threadTS,
+		Attachments:     message.Attachments,
+	}
+
+	c.decisions.SetUser(channelID, ts, message.User)
+	if err := c.processWithUser(ctx, processor, messageEvent); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "reaction trigger", channelID, message.User, ts, err)
+	}
+}
+
+// fetchMessage fetches a single message by channel and timestamp. Top-level
+// messages come from the channel history; thread replies aren't in the
+// history, so those are looked up in their thread instead.
+func (c *Client) fetchMessage(ctx context.Context, channelID, ts string) (*slack.Message, error) {
+	history, err := c.api.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    ts,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting channel history: %w", err)
+	}
+	if len(history.Messages) > 0 && history.Messages[0].Timestamp == ts {
+		return &history.Messages[0], nil
+	}
+
+	replies, _, _, err := c.api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: ts,
+		Latest:    ts,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting thread replies: %w", err)
+	}
+	for i := range replies {
+		if replies[i].Timestamp == ts {
+			return &replies[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("message %s not found in channel %s", ts, channelID)
+}
@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"context"
+	"time"
+)
+
+// Startup phases, in order
+const (
+	PhaseStarting   = "starting"
+	PhaseConnecting = "connecting"
+	PhaseVerifying  = "verifying"
+	PhaseReady      = "ready"
+)
+
+// setPhase records and logs a startup phase transition
+func (c *Client) setPhase(phase string) {
+	c.phaseMu.Lock()
+	previous := c.phase
+	c.phase = phase
+	c.phaseMu.Unlock()
+
+	if previous != phase {
+		c.logger.Infof("🚦 Startup phase: %s -> %s", previous, phase)
+	}
+}
+
+// Phase returns the current startup phase
+func (c *Client) Phase() string {
+	c.phaseMu.Lock()
+	defer c.phaseMu.Unlock()
+	return c.phase
+}
+
+// markReady marks the filter configuration as ready, releasing queued
+// events for processing
+func (c *Client) markReady() {
+	c.readyOnce.Do(func() {
+		c.setPhase(PhaseReady)
+		close(c.ready)
+	})
+}
+
+// waitReady blocks until startup is ready, the context is done, or the ready
+// timeout passes. On timeout processing starts with whatever is ready
+// rather than holding events forever.
+func (c *Client) waitReady(ctx context.Context) {
+	timer := time.NewTimer(c.readyTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+	case <-timer.C:
+		c.logger.Warnf("⚠️ Startup still in phase %q after %s, processing queued events anyway", c.Phase(), c.readyTimeout)
+	}
+}
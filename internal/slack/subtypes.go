@@ -0,0 +1,46 @@
+package slack
+
+import "strings"
+
+// defaultSkippedSubtypes are message subtypes Slack posts for channel
+// housekeeping. They have no author or nothing worth translating, so they
+// are dropped before any API call.
+var defaultSkippedSubtypes = []string{
+	"channel_join",
+	"channel_leave",
+	"channel_topic",
+	"channel_purpose",
+	"channel_name",
+	"channel_archive",
+	"channel_unarchive",
+	"group_join",
+	"group_leave",
+	"group_topic",
+	"group_purpose",
+	"group_name",
+	"group_archive",
+	"group_unarchive",
+	"pinned_item",
+	"unpinned_item",
+	"message_deleted",
+	"message_replied",
+	"reminder_add",
+	"bot_add",
+	"bot_remove",
+	"ekm_access_denied",
+}
+
+// skippedSubtypeSet combines the default skipped subtypes with extra ones
+// from the configuration
+func skippedSubtypeSet(extra []string) map[string]bool {
+	skipped := make(map[string]bool, len(defaultSkippedSubtypes)+len(extra))
+	for _, subtype := range defaultSkippedSubtypes {
+		skipped[subtype] = true
+	}
+	for _, subtype := range extra {
+		if subtype = strings.TrimSpace(subtype); subtype != "" {
+			skipped[subtype] = true
+		}
+	}
+	return skipped
+}
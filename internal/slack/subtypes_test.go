@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/slacktest"
+)
+
+func TestHousekeepingSubtypesSkipped(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.AddUser(slack.User{ID: testUser, Name: "alice"})
+	cfg := testConfig()
+	cfg.SkippedSubtypes = []string{"pinned_item"}
+	c := newTestClient(t, server, cfg)
+
+	tests := []struct {
+		subtype string
+		skipped bool
+	}{
+		{subtype: "channel_join", skipped: true},
+		{subtype: "channel_topic", skipped: true},
+		{subtype: "channel_purpose", skipped: true},
+		{subtype: "pinned_item", skipped: true},
+		{subtype: "", skipped: false},
+		{subtype: "thread_broadcast", skipped: false},
+	}
+	for _, tt := range tests {
+		name := tt.subtype
+		if name == "" {
+			name = "no subtype"
+		}
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			c.handleMessage(context.Background(), &IncomingMessage{
+				Channel:     testChannel,
+				ChannelType: "channel",
+				User:        testUser,
+				SubType:     tt.subtype,
+				Text:        "alice has joined the channel",
+				Timestamp:   server.NextTS(),
+			}, r.process)
+
+			if processed := len(r.processed()) == 1; processed == tt.skipped {
+				t.Errorf("subtype %q processed: %v, want %v", tt.subtype, processed, !tt.skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/internal/slacktest"
+)
+
+// Display names in SLACK_TARGET_USERS are resolved to IDs by verification.
+// Someone else taking the same display name doesn't become a target user.
+func TestTargetDisplayNamesMatchByID(t *testing.T) {
+	server := slacktest.NewServer(t)
+	alice := slack.User{ID: "U0000001", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice A"}}
+	bob := slack.User{ID: "U0000002", Name: "bob", Profile: slack.UserProfile{DisplayName: "Bobby"}}
+	server.AddUser(alice)
+	server.AddUser(bob)
+
+	cfg := testConfig()
+	cfg.SlackAllTargetUsers = false
+	cfg.SlackTargetUsers = []string{"Alice A", "bob"}
+	c := newTestClient(t, server, cfg)
+
+	impostor := slack.User{ID: "U0000003", Name: "mallory", Profile: slack.UserProfile{DisplayName: "Alice A"}}
+	if c.isTargetUser(&alice) {
+		t.Error("a display name matched before verification resolved it")
+	}
+
+	c.VerifySetup(context.Background())
+
+	tests := []struct {
+		name string
+		user slack.User
+		want bool
+	}{
+		{name: "display name resolved to the ID", user: alice, want: true},
+		{name: "legacy username", user: bob, want: true},
+		{name: "someone else with the display name", user: impostor, want: false},
+	}
+	for _, tt := range tests {
+		if got := c.isTargetUser(&tt.user); got != tt.want {
+			t.Errorf("%s: isTargetUser(%s) = %v, want %v", tt.name, tt.user.ID, got, tt.want)
+		}
+	}
+
+	if users, ok := c.TargetUsers(); !ok || users != 2 {
+		t.Errorf("TargetUsers = %d, %v, want 2, true", users, ok)
+	}
+}
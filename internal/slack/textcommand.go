@@ -0,0 +1,46 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/user/slack-bot-api/internal/command"
+)
+
+// textCommand runs a command sent as a mention of the bot or a direct
+// message, like "@genalpha optout", and replies ephemerally. Mentions of
+// the bot in text are ignored. A lone word resembling a command, like
+// "@genalpha hlep", gets a hint instead of a translation. It reports
+// false, doing nothing, when text doesn't start with a command name, so
+// the text is handled as a message instead.
+func (c *Client) textCommand(ctx context.Context, channelID, ts, userID, text string) bool {
+	botUserID, err := c.BotUserID(ctx)
+	if err != nil {
+		return false
+	}
+
+	req := command.Parse("<@"+botUserID+">", stripMention(text, botUserID))
+	req.UserID = userID
+	req.ChannelID = channelID
+	req.IsAdmin = c.adminUsers[userID]
+	if req.Name == "" {
+		return false
+	}
+	if !c.commands.Has(req.Name, req.IsAdmin) {
+		// A sentence starting with a word close to a command name is
+		// still a sentence
+		if len(req.Args) > 0 || !c.commands.Resembles(req.Name, req.IsAdmin) {
+			return false
+		}
+		c.loggerFor(ctx).Infof("🤔 Unknown command %q from %s in %s", req.Name, userID, channelID)
+		c.decisions.Step(channelID, ts, "command", false, "unknown command "+req.Name)
+	} else {
+		c.loggerFor(ctx).Infof("💬 Command %q from %s in %s", req.Name, userID, channelID)
+		c.decisions.Step(channelID, ts, "command", true, req.Name)
+	}
+	reply := c.commands.Dispatch(ctx, req)
+
+	if err := c.PostEphemeral(ctx, channelID, userID, reply); err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error replying to command: %v", err)
+	}
+	return true
+}
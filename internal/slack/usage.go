@@ -0,0 +1,141 @@
+package slack
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usageWindow is how far back API usage is summarized, in one-minute
+// buckets
+const usageWindow = 60
+
+// LatencyBounds are the upper bounds of the API latency histogram buckets;
+// a final bucket counts everything slower
+var LatencyBounds = []time.Duration{
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// MethodUsage summarizes the calls of one Web API method over the last hour
+type MethodUsage struct {
+	Method      string
+	Calls       uint64
+	RateLimited uint64
+	AvgLatency  time.Duration
+	// Histogram counts calls per latency bucket, see LatencyBounds
+	Histogram []uint64
+}
+
+// usageBucket holds one minute of calls to a method
+type usageBucket struct {
+	minute      int64
+	calls       uint64
+	rateLimited uint64
+	latency     time.Duration
+	histogram   []uint64
+}
+
+// apiUsage records every Web API call by method. It wraps the HTTP client
+// the Slack library uses, so every method is covered without touching the
+// call sites.
+type apiUsage struct {
+	mu      sync.Mutex
+	next    *http.Client
+	methods map[string]*[usageWindow]usageBucket
+}
+
+func newAPIUsage(next *http.Client) *apiUsage {
+	return &apiUsage{
+		next:    next,
+		methods: make(map[string]*[usageWindow]usageBucket),
+	}
+}
+
+// Do makes the request and records its method, latency and whether it was
+// rate limited
+func (u *apiUsage) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := u.next.Do(req)
+	rateLimited := err == nil && resp.StatusCode == http.StatusTooManyRequests
+
+	u.record(strings.TrimPrefix(req.URL.Path, "/api/"), start, time.Since(start), rateLimited)
+	return resp, err
+}
+
+// record adds a call to its method's bucket for the current minute
+func (u *apiUsage) record(method string, at time.Time, latency time.Duration, rateLimited bool) {
+	minute := at.Unix() / 60
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	buckets, ok := u.methods[method]
+	if !ok {
+		buckets = new([usageWindow]usageBucket)
+		u.methods[method] = buckets
+	}
+
+	bucket := &buckets[minute%usageWindow]
+	if bucket.minute != minute {
+		*bucket = usageBucket{minute: minute, histogram: make([]uint64, len(LatencyBounds)+1)}
+	}
+	bucket.calls++
+	bucket.latency += latency
+	if rateLimited {
+		bucket.rateLimited++
+	}
+
+	i := sort.Search(len(LatencyBounds), func(i int) bool { return latency <= LatencyBounds[i] })
+	bucket.histogram[i]++
+}
+
+// summary returns the usage of every method called in the last hour, most
+// called first
+func (u *apiUsage) summary(now time.Time) []MethodUsage {
+	oldest := now.Unix()/60 - usageWindow + 1
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var usage []MethodUsage
+	for method, buckets := range u.methods {
+		m := MethodUsage{Method: method, Histogram: make([]uint64, len(LatencyBounds)+1)}
+		var latency time.Duration
+		for _, bucket := range buckets {
+			if bucket.minute < oldest || bucket.calls == 0 {
+				continue
+			}
+			m.Calls += bucket.calls
+			m.RateLimited += bucket.rateLimited
+			latency += bucket.latency
+			for i, n := range bucket.histogram {
+				m.Histogram[i] += n
+			}
+		}
+		if m.Calls == 0 {
+			continue
+		}
+		m.AvgLatency = latency / time.Duration(m.Calls)
+		usage = append(usage, m)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Calls != usage[j].Calls {
+			return usage[i].Calls > usage[j].Calls
+		}
+		return usage[i].Method < usage[j].Method
+	})
+	return usage
+}
+
+// APIUsage summarizes the Web API calls made in the last hour, by method
+func (c *Client) APIUsage() []MethodUsage {
+	return c.usage.summary(time.Now())
+}
@@ -0,0 +1,86 @@
+package slack
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// userCacheCapacity is the number of users whose info is cached
+const userCacheCapacity = 1000
+
+// userCache caches users.info results by user ID, bounded by count and age.
+// The least recently used user is evicted when full.
+type userCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	entries  map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type userCacheEntry struct {
+	user      *slack.User
+	fetchedAt time.Time
+}
+
+func newUserCache(capacity int, ttl time.Duration) *userCache {
+	return &userCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached user, if present and fresh
+func (uc *userCache) get(userID string) (*slack.User, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	elem, ok := uc.entries[userID]
+	if ok && time.Since(elem.Value.(*userCacheEntry).fetchedAt) >= uc.ttl {
+		uc.order.Remove(elem)
+		delete(uc.entries, userID)
+		ok = false
+	}
+	if !ok {
+		atomic.AddUint64(&uc.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&uc.hits, 1)
+	uc.order.MoveToBack(elem)
+	return elem.Value.(*userCacheEntry).user, true
+}
+
+// put caches a user
+func (uc *userCache) put(user *slack.User) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	entry := &userCacheEntry{user: user, fetchedAt: time.Now()}
+	if elem, ok := uc.entries[user.ID]; ok {
+		elem.Value = entry
+		uc.order.MoveToBack(elem)
+		return
+	}
+
+	uc.entries[user.ID] = uc.order.PushBack(entry)
+	if uc.order.Len() > uc.capacity {
+		oldest := uc.order.Front()
+		uc.order.Remove(oldest)
+		delete(uc.entries, oldest.Value.(*userCacheEntry).user.ID)
+	}
+}
+
+// stats returns the number of cache hits and misses so far
+func (uc *userCache) stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&uc.hits), atomic.LoadUint64(&uc.misses)
+}
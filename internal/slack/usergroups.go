@@ -0,0 +1,115 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usergroupMembers tracks who is in the SLACK_TARGET_USERGROUPS user
+// groups. A group whose membership can't be fetched keeps the members it
+// had. It is safe for concurrent use.
+type usergroupMembers struct {
+	groups []string
+
+	mu      sync.RWMutex
+	members map[string]map[string]bool
+}
+
+func newUsergroupMembers(groups []string) *usergroupMembers {
+	m := &usergroupMembers{members: make(map[string]map[string]bool)}
+	for _, group := range groups {
+		if group = strings.TrimSpace(group); group != "" {
+			m.groups = append(m.groups, group)
+		}
+	}
+	return m
+}
+
+// contains reports whether a user is in any of the groups
+func (m *usergroupMembers) contains(userID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, members := range m.members {
+		if members[userID] {
+			return true
+		}
+	}
+	return false
+}
+
+// set replaces the members of a group
+func (m *usergroupMembers) set(group string, users []string) {
+	members := make(map[string]bool, len(users))
+	for _, user := range users {
+		members[user] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members[group] = members
+}
+
+// sizes returns how many members each group has. Groups whose membership
+// was never fetched are missing.
+func (m *usergroupMembers) sizes() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sizes := make(map[string]int, len(m.members))
+	for group, members := range m.members {
+		sizes[group] = len(members)
+	}
+	return sizes
+}
+
+// addMembers adds the members of every group to users
+func (m *usergroupMembers) addMembers(users map[string]bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, members := range m.members {
+		for user := range members {
+			users[user] = true
+		}
+	}
+}
+
+// refreshUsergroups fetches the members of every target user group. A
+// failure is logged as a warning and the group keeps its previous members,
+// rather than nobody in it being translated.
+func (c *Client) refreshUsergroups(ctx context.Context) {
+	for _, group := range c.usergroups.groups {
+		var users []string
+		err := c.withRateLimitRetry(ctx, func() error {
+			var err error
+			users, err = c.api.GetUserGroupMembersContext(ctx, group)
+			return err
+		})
+		if err != nil {
+			c.loggerFor(ctx).Warnf("⚠️ Couldn't refresh user group %s, keeping its previous members: %v", group, err)
+			continue
+		}
+
+		c.usergroups.set(group, users)
+		c.loggerFor(ctx).Debugf("👥 User group %s has %d members", group, len(users))
+	}
+}
+
+// refreshUsergroupsEvery keeps the target user groups' membership up to
+// date until ctx is done
+func (c *Client) refreshUsergroupsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refreshUsergroups(ctx)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/command"
+)
+
+// MessageTypeWatchRule marks message events that matched a watch rule. They
+// are translated like regular messages, but ahead of everything else.
+const MessageTypeWatchRule = "watch_rule"
+
+// IsWatched reports whether a message matched a watch rule
+func IsWatched(event *IncomingMessage) bool {
+	return event.Type == MessageTypeWatchRule
+}
+
+// matchWatchRule returns the first watch rule matching a message posted at
+// the given time
+func (c *Client) matchWatchRule(event *IncomingMessage, at time.Time) (config.WatchRule, bool) {
+	for _, rule := range c.watchRules {
+		if rule.Channel != event.Channel {
+			continue
+		}
+		if rule.Author != event.User && rule.Author != event.BotID {
+			continue
+		}
+		if rule.Window != "" && !inWindow(at, rule.WindowStart, rule.WindowEnd) {
+			continue
+		}
+		if rule.PatternRegexp != nil && !rule.PatternRegexp.MatchString(event.Text) {
+			continue
+		}
+		return rule, true
+	}
+	return config.WatchRule{}, false
+}
+
+// inWindow reports whether the local time of day of t is within the window.
+// A window ending before it starts spans midnight.
+func inWindow(t time.Time, start, end time.Duration) bool {
+	year, month, day := t.Date()
+	offset := t.Sub(time.Date(year, month, day, 0, 0, 0, 0, t.Location()))
+	if start <= end {
+		return offset >= start && offset <= end
+	}
+	return offset >= start || offset <= end
+}
+
+// handleWatchedMessage translates a message that matched a watch rule. The
+// channel, target user and bot message filters don't apply, so scheduled
+// posts from other bots can be translated too.
+func (c *Client) handleWatchedMessage(ctx context.Context, event *IncomingMessage, rule config.WatchRule, processor Processor) {
+	c.loggerFor(ctx).Infof("👀 Message %s in %s matched the watch rule for %s", event.Timestamp, event.Channel, rule.Author)
+	c.decisions.Step(event.Channel, event.Timestamp, "watch rule", true,
+		fmt.Sprintf("author %s, channel and user filters skipped", rule.Author))
+
+	if event.Text == "" {
+		c.decisions.Step(event.Channel, event.Timestamp, "has text", false, "message has no text")
+		return
+	}
+
+	event.Type = MessageTypeWatchRule
+
+	var user *slack.User
+	var err error
+	if event.User != "" {
+		c.decisions.SetUser(event.Channel, event.Timestamp, event.User)
+		user, err = c.GetUserInfo(ctx, event.User)
+	} else {
+		user, err = c.botAuthor(ctx, event.BotID)
+	}
+	if err != nil {
+		c.loggerFor(ctx).Errorf("❌ Error getting author of watched message: %v", err)
+		c.decisions.Failed(event.Channel, event.Timestamp, err)
+		return
+	}
+
+	if err := processor(ctx, event, user); err != nil {
+		c.processingFailed(ctx, c.loggerFor(ctx), "watched message", event.Channel, event.User, event.Timestamp, err)
+	}
+}
+
+// botAuthor describes a bot as a user, so posts without a user ID can go
+// through the same processor as everything else
+func (c *Client) botAuthor(ctx context.Context, botID string) (*slack.User, error) {
+	bot, err := c.api.GetBotInfoContext(ctx, slack.GetBotInfoParameters{Bot: botID})
+	if err != nil {
+		return nil, fmt.Errorf("error getting bot info for %s: %w", botID, err)
+	}
+	return &slack.User{ID: bot.ID, Name: bot.Name, RealName: bot.Name, IsBot: true}, nil
+}
+
+// rulesCommand lists the configured watch rules
+func (c *Client) rulesCommand(ctx context.Context, req command.Request) string {
+	var b strings.Builder
+	b.WriteString("*Watch rules* (always translated, first match wins)\n")
+	for i, rule := range c.watchRules {
+		fmt.Fprintf(&b, "%d. <#%s> by `%s`", i+1, rule.Channel, rule.Author)
+		if rule.Window != "" {
+			fmt.Fprintf(&b, ", between %s", rule.Window)
+		}
+		if rule.Pattern != "" {
+			fmt.Fprintf(&b, ", matching `%s`", rule.Pattern)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,112 @@
+// Package slackfmt turns Slack's message markup into the plain text a person
+// reading the message sees.
+package slackfmt
+
+import (
+	"strings"
+)
+
+// Resolver looks up the names that mentions are shown with. Either lookup
+// may be nil; an empty result falls back to the label Slack sent along, or
+// the raw ID.
+type Resolver struct {
+	// User returns the display name of a user ID
+	User func(id string) string
+	// Channel returns the name of a channel ID, without the #
+	Channel func(id string) string
+}
+
+// Resolve replaces the markup in text: user mentions become @name, channel
+// mentions #name, special mentions like <!here> @here, links their label
+// or URL, and the &amp;, &lt; and &gt; escapes the characters they stand
+// for. Anything that isn't markup is left as is.
+func Resolve(text string, r Resolver) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '>')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(unescape(text[:start]))
+		b.WriteString(r.resolveEntity(text[start+1 : end]))
+		text = text[end+1:]
+	}
+	b.WriteString(unescape(text))
+
+	return b.String()
+}
+
+// resolveEntity returns the text shown for the inside of a <...> entity
+func (r Resolver) resolveEntity(entity string) string {
+	target, label, _ := strings.Cut(entity, "|")
+	label = unescape(label)
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		id := target[1:]
+		if name := lookup(r.User, id); name != "" {
+			return "@" + name
+		}
+		if label != "" {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return "@" + id
+
+	case strings.HasPrefix(target, "#"):
+		id := target[1:]
+		if label != "" {
+			return "#" + label
+		}
+		if name := lookup(r.Channel, id); name != "" {
+			return "#" + name
+		}
+		return "#" + id
+
+	case strings.HasPrefix(target, "!"):
+		return special(target[1:], label)
+	}
+
+	if label != "" {
+		return label
+	}
+	return strings.TrimPrefix(unescape(target), "mailto:")
+}
+
+// special returns the text shown for a <!...> entity: @here, @channel and
+// @everyone, user groups and dates, which carry their fallback text
+func special(command, label string) string {
+	name, _, _ := strings.Cut(command, "^")
+	switch name {
+	case "here", "channel", "everyone":
+		return "@" + name
+	}
+	if label != "" {
+		return label
+	}
+	return name
+}
+
+func lookup(fn func(string) string, id string) string {
+	if fn == nil || id == "" {
+		return ""
+	}
+	return fn(id)
+}
+
+var unescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// unescape replaces the only three HTML entities Slack escapes in text
+func unescape(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,98 @@
+package slackfmt
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	users := map[string]string{"U0000001": "alice", "U0000002": "bob"}
+	channels := map[string]string{"C0000001": "deploys"}
+	r := Resolver{
+		User:    func(id string) string { return users[id] },
+		Channel: func(id string) string { return channels[id] },
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "plain text",
+			text: "the deploy is cooked",
+			want: "the deploy is cooked",
+		},
+		{
+			name: "user mentions",
+			text: "<@U0000001> can you ask <@U0000002|bobby> about it",
+			want: "@alice can you ask @bob about it",
+		},
+		{
+			name: "unknown user falls back to the label, then the ID",
+			text: "ping <@U9999999|carol> and <@U8888888>",
+			want: "ping @carol and @U8888888",
+		},
+		{
+			name: "channel mentions",
+			text: "moved to <#C0000002|incidents> from <#C0000001> and <#C0000003>",
+			want: "moved to #incidents from #deploys and #C0000003",
+		},
+		{
+			name: "links",
+			text: "see <https://example.com/runbook|the runbook> or <https://example.com>",
+			want: "see the runbook or https://example.com",
+		},
+		{
+			name: "mailto link",
+			text: "mail <mailto:ops@example.com>",
+			want: "mail ops@example.com",
+		},
+		{
+			name: "special mentions",
+			text: "<!here> <!channel> <!everyone> <!subteam^S0000001|@oncall>",
+			want: "@here @channel @everyone @oncall",
+		},
+		{
+			name: "date fallback text",
+			text: "due <!date^1700000000^{date_short}|Nov 14, 2023>",
+			want: "due Nov 14, 2023",
+		},
+		{
+			name: "escapes",
+			text: "if a &lt; b &amp;&amp; b &gt; c",
+			want: "if a < b && b > c",
+		},
+		{
+			name: "escaped label and URL",
+			text: "<https://example.com/?a=1&amp;b=2|Q&amp;A> <https://example.com/?x=1&amp;y=2>",
+			want: "Q&A https://example.com/?x=1&y=2",
+		},
+		{
+			name: "escaped markup isn't resolved",
+			text: "type &lt;@U0000001&gt; to mention",
+			want: "type <@U0000001> to mention",
+		},
+		{
+			name: "unclosed bracket",
+			text: "a <@U0000001 b &amp; c",
+			want: "a <@U0000001 b & c",
+		},
+		{
+			name: "everything at once",
+			text: "<@U0000001> posted <https://ci.example.com/1|build #1> in <#C0000001|deploys> &amp; it's red &gt;:(",
+			want: "@alice posted build #1 in #deploys & it's red >:(",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.text, r); got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveWithoutLookups(t *testing.T) {
+	got := Resolve("<@U0000001|alice> in <#C0000001>", Resolver{})
+	if want := "@alice in #C0000001"; got != want {
+		t.Errorf("Resolve = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,573 @@
+// Package slacktest is a fake Slack for tests. It answers the Web API
+// methods the bot calls and serves a socket mode connection that events
+// are pushed through, recording posts and acks along the way.
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+)
+
+// BotUserID is the user ID the fake reports for the bot in auth.test
+const BotUserID = "UBOT000001"
+
+// BotID is the bot ID the fake puts on messages the bot posts
+const BotID = "BBOT000001"
+
+// pingInterval is how often the socket mode connection is pinged; the
+// socket mode client reconnects when it misses pings for 30 seconds
+const pingInterval = time.Second
+
+// Handler answers a Web API call with the value encoded as its JSON
+// response
+type Handler func(form url.Values) any
+
+// Post is a message the bot posted, updated or deleted
+type Post struct {
+	Method   string
+	Channel  string
+	TS       string
+	ThreadTS string
+	Text     string
+	User     string
+	Blocks   string
+}
+
+// envelope is a socket mode message waiting to be sent
+type envelope struct {
+	id   string
+	data []byte
+}
+
+// Server is a fake Slack Web API and socket mode endpoint
+type Server struct {
+	t   testing.TB
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	failures map[string][]string
+	failWhen map[string]func(form url.Values) string
+	echo     bool
+	calls    map[string]int
+	users    map[string]slack.User
+	channels []slack.Channel
+	posts    []Post
+	acks     map[string]bool
+	ts       int64
+	events   int
+	conns    map[*websocket.Conn]bool
+	changed  chan struct{}
+
+	// outgoing holds socket mode messages until a connection takes them
+	outgoing chan envelope
+}
+
+// NewServer starts a fake Slack that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		t:        t,
+		handlers: make(map[string]Handler),
+		failures: make(map[string][]string),
+		failWhen: make(map[string]func(url.Values) string),
+		calls:    make(map[string]int),
+		users:    make(map[string]slack.User),
+		acks:     make(map[string]bool),
+		ts:       time.Now().Unix() * 1e6,
+		conns:    make(map[*websocket.Conn]bool),
+		changed:  make(chan struct{}),
+		outgoing: make(chan envelope, 1000),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.serveAPI)
+	mux.HandleFunc("/link", s.serveSocket)
+	s.srv = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close disconnects socket mode clients and stops the server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.srv.Close()
+}
+
+// APIURL is the Web API base URL, for slack.OptionAPIURL
+func (s *Server) APIURL() string {
+	return s.srv.URL + "/api/"
+}
+
+// HTTPClient returns a client that sends every request, whatever its host,
+// to the fake. It lets code that always talks to slack.com run against it.
+func (s *Server) HTTPClient() *http.Client {
+	target, _ := url.Parse(s.srv.URL)
+	return &http.Client{Transport: roundTripper(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// AddUser makes a user known to users.info, users.list and
+// users.lookupByEmail
+func (s *Server) AddUser(user slack.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+}
+
+// AddChannel makes a channel known to conversations.info and the
+// conversation lists
+func (s *Server) AddChannel(channel slack.Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels = append(s.channels, channel)
+}
+
+// Handle answers method with h instead of the built-in behavior
+func (s *Server) Handle(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+// Fail makes the next calls of method fail with the given Slack error
+// codes, one call per code
+func (s *Server) Fail(method string, errs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], errs...)
+}
+
+// FailWhen makes calls of method fail with the Slack error code cond
+// returns for their arguments, unless it returns ""
+func (s *Server) FailWhen(method string, cond func(form url.Values) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failWhen[method] = cond
+}
+
+// EchoPosts sends every message the bot posts back to it as a message
+// event, like Slack does
+func (s *Server) EchoPosts() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.echo = true
+}
+
+// Calls returns how often method was called
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// Posts returns the messages posted, updated and deleted so far, in order
+func (s *Server) Posts() []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Post(nil), s.posts...)
+}
+
+// Acked reports whether the socket mode envelope was acknowledged
+func (s *Server) Acked(envelopeID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acks[envelopeID]
+}
+
+// Connected reports whether a socket mode client is connected
+func (s *Server) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns) > 0
+}
+
+// WaitFor blocks until cond holds, checking it whenever the fake records a
+// call, post or ack, and fails the test after timeout
+func (s *Server) WaitFor(timeout time.Duration, what string, cond func() bool) {
+	s.t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		if cond() {
+			return
+		}
+		select {
+		case <-changed:
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline.C:
+			s.t.Fatalf("timed out after %s waiting for %s", timeout, what)
+		}
+	}
+}
+
+// NextTS returns a new message timestamp, later than every earlier one
+func (s *Server) NextTS() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextTSLocked()
+}
+
+func (s *Server) nextTSLocked() string {
+	s.ts++
+	return fmt.Sprintf("%d.%06d", s.ts/1e6, s.ts%1e6)
+}
+
+// notifyLocked wakes WaitFor callers; callers must hold s.mu
+func (s *Server) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Message is the inner event of a message posted in a channel
+func Message(channel, user, text, ts string) map[string]any {
+	return map[string]any{
+		"type":         "message",
+		"channel":      channel,
+		"channel_type": "channel",
+		"user":         user,
+		"text":         text,
+		"ts":           ts,
+		"event_ts":     ts,
+	}
+}
+
+// SendEvent pushes an Events API event over socket mode, wrapped in an
+// event callback with the given event ID (a new one when empty). It returns
+// the envelope ID the bot should ack.
+func (s *Server) SendEvent(eventID string, inner map[string]any) string {
+	s.mu.Lock()
+	s.events++
+	envelopeID := fmt.Sprintf("envelope-%d", s.events)
+	if eventID == "" {
+		eventID = fmt.Sprintf("Ev%06d", s.events)
+	}
+	s.mu.Unlock()
+
+	payload, err := json.Marshal(map[string]any{
+		"token":      "verification-token",
+		"team_id":    "T0000001",
+		"api_app_id": "A0000001",
+		"type":       "event_callback",
+		"event_id":   eventID,
+		"event_time": time.Now().Unix(),
+		"event":      inner,
+	})
+	if err != nil {
+		s.t.Fatalf("encoding event: %v", err)
+	}
+	s.send(map[string]any{
+		"type":                     "events_api",
+		"envelope_id":              envelopeID,
+		"payload":                  json.RawMessage(payload),
+		"accepts_response_payload": false,
+	})
+	return envelopeID
+}
+
+// SendSlashCommand pushes a slash command over socket mode and returns the
+// envelope ID the bot should ack
+func (s *Server) SendSlashCommand(command, text, channel, user string) string {
+	s.mu.Lock()
+	s.events++
+	envelopeID := fmt.Sprintf("envelope-%d", s.events)
+	s.mu.Unlock()
+
+	s.send(map[string]any{
+		"type":        "slash_commands",
+		"envelope_id": envelopeID,
+		"payload": map[string]any{
+			"command":    command,
+			"text":       text,
+			"channel_id": channel,
+			"user_id":    user,
+			"team_id":    "T0000001",
+		},
+		"accepts_response_payload": true,
+	})
+	return envelopeID
+}
+
+func (s *Server) send(msg map[string]any) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.t.Fatalf("encoding socket mode message: %v", err)
+	}
+	id, _ := msg["envelope_id"].(string)
+	s.outgoing <- envelope{id: id, data: data}
+}
+
+// Disconnect drops every socket mode connection without a disconnect
+// request, like a network failure. Clients reconnect on their own.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	conns := make([]*websocket.Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// serveSocket runs one socket mode connection: hello first, then queued
+// messages and pings, while reading acks. Envelopes the connection took but
+// didn't ack are queued again when it closes.
+func (s *Server) serveSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conns[conn] = true
+	s.notifyLocked()
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.notifyLocked()
+		s.mu.Unlock()
+	}()
+
+	// Slack sends envelopes that weren't acked again, on the next
+	// connection
+	var sent []envelope
+	defer func() {
+		for _, msg := range sent {
+			if msg.id != "" && !s.Acked(msg.id) {
+				s.outgoing <- msg
+			}
+		}
+	}()
+
+	hello := map[string]any{
+		"type":            "hello",
+		"num_connections": 1,
+		"connection_info": map[string]any{"app_id": "A0000001"},
+		"debug_info":      map[string]any{"host": "slacktest"},
+	}
+	if err := conn.WriteJSON(hello); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var ack struct {
+				EnvelopeID string `json:"envelope_id"`
+			}
+			if err := conn.ReadJSON(&ack); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.acks[ack.EnvelopeID] = true
+			s.notifyLocked()
+			s.mu.Unlock()
+		}
+	}()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case msg := <-s.outgoing:
+			if err := conn.WriteMessage(websocket.TextMessage, msg.data); err != nil {
+				// Keep the message for the next connection
+				s.outgoing <- msg
+				return
+			}
+			sent = append(sent, msg)
+		}
+	}
+}
+
+// serveAPI answers a Web API call, with a queued failure, a custom
+// handler or the built-in behavior
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	s.mu.Lock()
+	s.calls[method]++
+	s.notifyLocked()
+	var response any
+	var echo map[string]any
+	if errs := s.failures[method]; len(errs) > 0 {
+		s.failures[method] = errs[1:]
+		response = map[string]any{"ok": false, "error": errs[0]}
+	} else if code := s.failureFor(method, r.Form); code != "" {
+		response = map[string]any{"ok": false, "error": code}
+	} else if h, ok := s.handlers[method]; ok {
+		s.mu.Unlock()
+		response = h(r.Form)
+		s.mu.Lock()
+	} else {
+		response = s.builtinLocked(method, r.Form)
+		if s.echo && method == "chat.postMessage" {
+			post := s.posts[len(s.posts)-1]
+			echo = Message(post.Channel, BotUserID, post.Text, post.TS)
+			echo["bot_id"] = BotID
+			if post.ThreadTS != "" {
+				echo["thread_ts"] = post.ThreadTS
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if echo != nil {
+		s.SendEvent("", echo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// failureFor returns the error code a FailWhen condition fails a call
+// with, or ""; callers must hold s.mu
+func (s *Server) failureFor(method string, form url.Values) string {
+	if cond, ok := s.failWhen[method]; ok {
+		return cond(form)
+	}
+	return ""
+}
+
+// builtinLocked is the default answer to a Web API call; callers must
+// hold s.mu
+func (s *Server) builtinLocked(method string, form url.Values) any {
+	ok := map[string]any{"ok": true}
+	switch method {
+	case "auth.test":
+		return map[string]any{"ok": true, "user": "genalpha", "user_id": BotUserID, "bot_id": BotID,
+			"team": "Test Workspace", "team_id": "T0000001", "url": "https://test.slack.com/"}
+	case "apps.connections.open":
+		return map[string]any{"ok": true, "url": "ws" + strings.TrimPrefix(s.srv.URL, "http") + "/link"}
+	case "users.info":
+		user, found := s.users[form.Get("user")]
+		if !found {
+			return map[string]any{"ok": false, "error": "user_not_found"}
+		}
+		return map[string]any{"ok": true, "user": user}
+	case "users.lookupByEmail":
+		for _, user := range s.users {
+			if user.Profile.Email == form.Get("email") {
+				return map[string]any{"ok": true, "user": user}
+			}
+		}
+		return map[string]any{"ok": false, "error": "users_not_found"}
+	case "users.list":
+		users := make([]slack.User, 0, len(s.users))
+		for _, user := range s.users {
+			users = append(users, user)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+		return map[string]any{"ok": true, "members": users}
+	case "conversations.info":
+		for _, channel := range s.channels {
+			if channel.ID == form.Get("channel") {
+				return map[string]any{"ok": true, "channel": channel}
+			}
+		}
+		return map[string]any{"ok": false, "error": "channel_not_found"}
+	case "users.conversations", "conversations.list":
+		return s.channelPageLocked(form)
+	case "conversations.members":
+		return map[string]any{"ok": true, "members": []string{BotUserID}}
+	case "conversations.replies", "conversations.history":
+		return map[string]any{"ok": true, "messages": []any{}}
+	case "chat.postMessage":
+		post := s.recordLocked(method, form, s.nextTSLocked())
+		return map[string]any{"ok": true, "channel": post.Channel, "ts": post.TS,
+			"message": map[string]any{"type": "message", "text": post.Text, "ts": post.TS, "bot_id": BotID, "user": BotUserID}}
+	case "chat.update":
+		post := s.recordLocked(method, form, form.Get("ts"))
+		return map[string]any{"ok": true, "channel": post.Channel, "ts": post.TS, "text": post.Text}
+	case "chat.delete":
+		post := s.recordLocked(method, form, form.Get("ts"))
+		return map[string]any{"ok": true, "channel": post.Channel, "ts": post.TS}
+	case "chat.postEphemeral":
+		s.recordLocked(method, form, "")
+		return map[string]any{"ok": true, "message_ts": s.nextTSLocked()}
+	case "reactions.add", "reactions.remove":
+		return ok
+	case "usergroups.users.list":
+		return map[string]any{"ok": true, "users": []string{}}
+	}
+	return map[string]any{"ok": false, "error": "unknown_method"}
+}
+
+// channelPageLocked answers a conversation list call with the page of
+// channels its cursor and limit select; callers must hold s.mu
+func (s *Server) channelPageLocked(form url.Values) any {
+	start, _ := strconv.Atoi(form.Get("cursor"))
+	limit, err := strconv.Atoi(form.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	end := start + limit
+	next := strconv.Itoa(end)
+	if end >= len(s.channels) {
+		end, next = len(s.channels), ""
+	}
+	if start > end {
+		start = end
+	}
+	return map[string]any{
+		"ok":                true,
+		"channels":          s.channels[start:end],
+		"response_metadata": map[string]any{"next_cursor": next},
+	}
+}
+
+// recordLocked remembers a post, update or delete; callers must hold s.mu
+func (s *Server) recordLocked(method string, form url.Values, ts string) Post {
+	post := Post{
+		Method:   method,
+		Channel:  form.Get("channel"),
+		TS:       ts,
+		ThreadTS: form.Get("thread_ts"),
+		Text:     form.Get("text"),
+		User:     form.Get("user"),
+		Blocks:   form.Get("blocks"),
+	}
+	s.posts = append(s.posts, post)
+	return post
+}
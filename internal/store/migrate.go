@@ -0,0 +1,169 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// Migration upgrades the persisted document by one schema version. Apply
+// edits the top-level keys of the document in place.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(doc map[string]json.RawMessage) error
+}
+
+// migrations are applied in order; a state file at version n still needs
+// every migration after index n-1. Never edit or reorder released steps,
+// only append new ones.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "record the schema version in state files written before versioning",
+		Apply:       func(doc map[string]json.RawMessage) error { return nil },
+	},
+}
+
+// SchemaVersion is the state file version this binary writes
+var SchemaVersion = migrations[len(migrations)-1].Version
+
+// ErrSchemaTooNew is returned for state files written by a newer version of
+// the bot, which this binary can't safely read or write
+var ErrSchemaTooNew = errors.New("state file was written by a newer version of the bot")
+
+const (
+	// migrationLockTimeout bounds how long startup waits for another
+	// instance to finish migrating
+	migrationLockTimeout = 30 * time.Second
+
+	// migrationLockPoll is how often the lock is retried
+	migrationLockPoll = 200 * time.Millisecond
+)
+
+// Pending returns the schema version of the state file at path and the
+// migrations it still needs, without changing anything. A missing file
+// needs none, since a fresh store starts at the current version.
+func Pending(path string) (int, []Migration, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SchemaVersion, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	version, err := schemaVersion(data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error parsing state file %s: %w", path, err)
+	}
+	pending, err := pendingMigrations(version)
+	return version, pending, err
+}
+
+// schemaVersion reads the schema_version key; files without it predate
+// versioning and are version 0
+func schemaVersion(data []byte) (int, error) {
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+	return doc.SchemaVersion, nil
+}
+
+// pendingMigrations returns the migrations after version
+func pendingMigrations(version int) ([]Migration, error) {
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("%w: schema version %d, this binary supports up to %d", ErrSchemaTooNew, version, SchemaVersion)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// migrate brings the state file at path up to the current schema version
+// and returns its contents. Migrating holds a lock file, so two instances
+// starting together don't both rewrite the file.
+func migrate(path string, data []byte, logger *logging.Logger) ([]byte, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingMigrations(version)
+	if err != nil || len(pending) == 0 {
+		return data, err
+	}
+
+	unlock, err := lockMigration(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Another instance may have migrated the file while we waited
+	if data, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if version, err = schemaVersion(data); err != nil {
+		return nil, err
+	}
+	if pending, err = pendingMigrations(version); err != nil || len(pending) == 0 {
+		return data, err
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for _, m := range pending {
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("error migrating state file to schema version %d (%s): %w", m.Version, m.Description, err)
+		}
+		doc["schema_version"] = json.RawMessage(strconv.Itoa(m.Version))
+		logger.Infof("🔧 Migrated state file %s to schema version %d: %s", path, m.Version, m.Description)
+	}
+
+	if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+		return nil, fmt.Errorf("error encoding migrated state: %w", err)
+	}
+	if err := writeFile(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// lockMigration creates path.lock, waiting while another instance holds it,
+// and returns a function that removes it
+func lockMigration(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(migrationLockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error creating migration lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("state file %s is still being migrated by another instance after %s; remove %s if no other instance is running",
+				path, migrationLockTimeout, lockPath)
+		}
+		time.Sleep(migrationLockPoll)
+	}
+}
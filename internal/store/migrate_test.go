@@ -0,0 +1,180 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// withMigrations replaces the migration steps for the length of a test
+func withMigrations(t *testing.T, steps []Migration) {
+	t.Helper()
+	oldMigrations, oldVersion := migrations, SchemaVersion
+	migrations, SchemaVersion = steps, steps[len(steps)-1].Version
+	t.Cleanup(func() { migrations, SchemaVersion = oldMigrations, oldVersion })
+}
+
+func writeState(t *testing.T, doc string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readDoc(t *testing.T, path string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("state file isn't JSON: %v", err)
+	}
+	return doc
+}
+
+func TestFreshStateFileStartsAtCurrentVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	version, pending, err := Pending(path)
+	if err != nil || version != SchemaVersion || len(pending) != 0 {
+		t.Fatalf("Pending on a missing file = %d, %d migrations, %v, want %d, none, nil", version, len(pending), err, SchemaVersion)
+	}
+
+	s, err := Open(path, testLogger())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.RecordReply(reply("2.000001")); err != nil {
+		t.Fatalf("RecordReply: %v", err)
+	}
+
+	version, pending, err = Pending(path)
+	if err != nil || version != SchemaVersion || len(pending) != 0 {
+		t.Errorf("Pending on the written file = %d, %d migrations, %v, want %d, none, nil", version, len(pending), err, SchemaVersion)
+	}
+}
+
+func TestMigrateAppliesStepsInOrder(t *testing.T) {
+	var applied []int
+	step := func(version int, apply func(doc map[string]json.RawMessage)) Migration {
+		return Migration{
+			Version: version,
+			Apply: func(doc map[string]json.RawMessage) error {
+				if got := string(doc["schema_version"]); version > 1 && got != strconv.Itoa(version-1) {
+					t.Errorf("migration %d saw schema_version %s, want %d", version, got, version-1)
+				}
+				applied = append(applied, version)
+				apply(doc)
+				return nil
+			},
+		}
+	}
+	withMigrations(t, []Migration{
+		step(1, func(doc map[string]json.RawMessage) {}),
+		step(2, func(doc map[string]json.RawMessage) {
+			doc["last_seen"] = doc["seen"]
+			delete(doc, "seen")
+		}),
+		step(3, func(doc map[string]json.RawMessage) {
+			doc["opted_out"] = json.RawMessage(`{"U0000001":"2024-01-01T00:00:00Z"}`)
+		}),
+	})
+
+	tests := []struct {
+		name    string
+		doc     string
+		applied []int
+	}{
+		{"unversioned", `{"seen":{"C0000001":"1.000001"}}`, []int{1, 2, 3}},
+		{"version 1", `{"schema_version":1,"seen":{"C0000001":"1.000001"}}`, []int{2, 3}},
+		{"version 2", `{"schema_version":2,"last_seen":{"C0000001":"1.000001"}}`, []int{3}},
+		{"current", `{"schema_version":3,"last_seen":{"C0000001":"1.000001"}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied = nil
+			path := writeState(t, tt.doc)
+
+			_, pending, err := Pending(path)
+			if err != nil || len(pending) != len(tt.applied) {
+				t.Fatalf("Pending = %d migrations, %v, want %d", len(pending), err, len(tt.applied))
+			}
+
+			s, err := Open(path, testLogger())
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if len(applied) != len(tt.applied) {
+				t.Fatalf("applied migrations %v, want %v", applied, tt.applied)
+			}
+			for i := range applied {
+				if applied[i] != tt.applied[i] {
+					t.Fatalf("applied migrations %v, want %v", applied, tt.applied)
+				}
+			}
+
+			if got := s.LastSeen()["C0000001"]; got != "1.000001" {
+				t.Errorf("last seen = %q after migrating, want 1.000001", got)
+			}
+			if !s.OptedOut("U0000001") && len(tt.applied) > 0 {
+				t.Errorf("opt-out added by migration 3 is missing")
+			}
+			if got := string(readDoc(t, path)["schema_version"]); got != "3" {
+				t.Errorf("schema_version on disk = %s, want 3", got)
+			}
+			if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("migration lock left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateStopsAtFailingStep(t *testing.T) {
+	errBroken := errors.New("broken step")
+	withMigrations(t, []Migration{
+		{Version: 1, Apply: func(doc map[string]json.RawMessage) error { return nil }},
+		{Version: 2, Description: "broken", Apply: func(doc map[string]json.RawMessage) error { return errBroken }},
+	})
+	original := `{"schema_version":1}`
+	path := writeState(t, original)
+
+	if _, err := Open(path, testLogger()); !errors.Is(err, errBroken) {
+		t.Fatalf("Open = %v, want the failing step's error", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != original {
+		t.Errorf("state file rewritten after a failed migration: %s", data)
+	}
+}
+
+func TestSchemaTooNew(t *testing.T) {
+	doc, err := json.Marshal(map[string]int{"schema_version": SchemaVersion + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeState(t, string(doc))
+
+	if _, _, err := Pending(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Pending = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := Open(path, testLogger()); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Open = %v, want ErrSchemaTooNew", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(doc) {
+		t.Errorf("state file from a newer version was rewritten: %s", data)
+	}
+}
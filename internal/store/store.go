@@ -0,0 +1,487 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// degradeAfter is the number of consecutive failed writes after which the
+// store is considered degraded
+const degradeAfter = 3
+
+// maxReplies bounds how many posted replies are remembered; the oldest are
+// forgotten first
+const maxReplies = 10000
+
+// Reply records a message the bot posted in response to another message
+type Reply struct {
+	Channel    string    `json:"channel"`
+	OriginalTS string    `json:"original_ts"`
+	ReplyTS    string    `json:"reply_ts"`
+	User       string    `json:"user"`
+	PostedAt   time.Time `json:"posted_at"`
+}
+
+// PendingApproval is a translation waiting for an approver to post or
+// discard it
+type PendingApproval struct {
+	ID         string    `json:"id"`
+	Channel    string    `json:"channel"`
+	OriginalTS string    `json:"original_ts"`
+	ThreadTS   string    `json:"thread_ts,omitempty"`
+	User       string    `json:"user"`
+	Approver   string    `json:"approver"`
+	Text       string    `json:"text"`
+	Style      string    `json:"style"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	// OutputStyle is the OUTPUT_STYLE the text was made in, empty for
+	// approvals saved before it was recorded
+	OutputStyle string `json:"output_style,omitempty"`
+}
+
+// DailySpend is the LLM usage of one day, counted against the daily budget
+type DailySpend struct {
+	// Day is the date in the budget's time zone, e.g. 2024-05-31
+	Day              string `json:"day"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	// Notified is set once the day's budget notice was posted
+	Notified bool `json:"notified,omitempty"`
+}
+
+// state is the persisted document
+type state struct {
+	SchemaVersion int                        `json:"schema_version"`
+	Replies       map[string]Reply           `json:"replies"`
+	Approvals     map[string]PendingApproval `json:"pending_approvals"`
+	Onboarded     map[string]time.Time       `json:"onboarded,omitempty"`
+	OptedOut      map[string]time.Time       `json:"opted_out,omitempty"`
+	LastSeen      map[string]string          `json:"last_seen,omitempty"`
+	DailySpend    *DailySpend                `json:"daily_spend,omitempty"`
+}
+
+// Store keeps the bot's state in memory and, when a path is given, in a
+// JSON file that is rewritten atomically on every change.
+//
+// The in-memory state is authoritative. When writes keep failing (e.g. the
+// disk is full) the store turns degraded: changes are only kept in memory
+// and callers aren't bothered with errors, until Flush manages to write the
+// file again.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	state    state
+	logger   *logging.Logger
+	failures int
+	degraded bool
+	// LastSeen changed since the last write
+	seenChanged bool
+}
+
+// Open loads the store from path, migrating it to the current schema
+// version first. An empty path keeps state in memory only; a missing file
+// starts empty. State files from a newer version of the bot are refused
+// with ErrSchemaTooNew.
+func Open(path string, logger *logging.Logger) (*Store, error) {
+	s := &Store{
+		path: path,
+		state: state{
+			SchemaVersion: SchemaVersion,
+			Replies:       make(map[string]Reply),
+			Approvals:     make(map[string]PendingApproval),
+			Onboarded:     make(map[string]time.Time),
+			OptedOut:      make(map[string]time.Time),
+			LastSeen:      make(map[string]string),
+		},
+		logger: logger,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	if data, err = migrate(path, data, logger); err != nil {
+		return nil, fmt.Errorf("error migrating state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, fmt.Errorf("error parsing state file %s: %w", path, err)
+	}
+	if s.state.Replies == nil {
+		s.state.Replies = make(map[string]Reply)
+	}
+	if s.state.Approvals == nil {
+		s.state.Approvals = make(map[string]PendingApproval)
+	}
+	if s.state.Onboarded == nil {
+		s.state.Onboarded = make(map[string]time.Time)
+	}
+	if s.state.OptedOut == nil {
+		s.state.OptedOut = make(map[string]time.Time)
+	}
+	if s.state.LastSeen == nil {
+		s.state.LastSeen = make(map[string]string)
+	}
+	return s, nil
+}
+
+// Path returns the file the store is persisted to, empty when in memory
+func (s *Store) Path() string {
+	return s.path
+}
+
+func replyKey(channel, ts string) string {
+	return channel + "/" + ts
+}
+
+// RecordReply remembers a posted reply
+func (s *Store) RecordReply(r Reply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Replies[replyKey(r.Channel, r.ReplyTS)] = r
+	if len(s.state.Replies) > maxReplies {
+		s.evictOldestReply()
+	}
+	return s.persist()
+}
+
+// evictOldestReply drops the reply posted first; callers must hold s.mu
+func (s *Store) evictOldestReply() {
+	var oldestKey string
+	var oldest time.Time
+	for k, r := range s.state.Replies {
+		if oldestKey == "" || r.PostedAt.Before(oldest) {
+			oldestKey, oldest = k, r.PostedAt
+		}
+	}
+	delete(s.state.Replies, oldestKey)
+}
+
+// Replies returns all remembered replies, oldest first
+func (s *Store) Replies() []Reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replies := make([]Reply, 0, len(s.state.Replies))
+	for _, r := range s.state.Replies {
+		replies = append(replies, r)
+	}
+	sort.Slice(replies, func(i, j int) bool {
+		return replies[i].PostedAt.Before(replies[j].PostedAt)
+	})
+	return replies
+}
+
+// Reply returns the reply posted with the given timestamp
+func (s *Store) Reply(channel, replyTS string) (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.state.Replies[replyKey(channel, replyTS)]
+	return r, ok
+}
+
+// RepliedTo reports whether a reply to the given message was recorded
+func (s *Store) RepliedTo(channel, originalTS string) bool {
+	_, ok := s.ReplyTo(channel, originalTS)
+	return ok
+}
+
+// ReplyTo returns the most recent reply recorded for the given message
+func (s *Store) ReplyTo(channel, originalTS string) (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest Reply
+	found := false
+	for _, r := range s.state.Replies {
+		if r.Channel == channel && r.OriginalTS == originalTS && (!found || r.PostedAt.After(latest.PostedAt)) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// DeleteReply forgets a posted reply
+func (s *Store) DeleteReply(channel, replyTS string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := replyKey(channel, replyTS)
+	if _, ok := s.state.Replies[k]; !ok {
+		return nil
+	}
+	delete(s.state.Replies, k)
+	return s.persist()
+}
+
+// AddPendingApproval remembers a translation awaiting approval
+func (s *Store) AddPendingApproval(p PendingApproval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Approvals[p.ID] = p
+	return s.persist()
+}
+
+// TakePendingApproval removes and returns a pending approval. It reports
+// false when there is none with that ID, or when it has expired.
+func (s *Store) TakePendingApproval(id string, now time.Time) (PendingApproval, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.state.Approvals[id]
+	if !ok {
+		return PendingApproval{}, false, nil
+	}
+	delete(s.state.Approvals, id)
+	if err := s.persist(); err != nil {
+		return PendingApproval{}, false, err
+	}
+	return p, now.Before(p.ExpiresAt), nil
+}
+
+// ExpirePendingApprovals removes and returns the approvals that expired
+// before now
+func (s *Store) ExpirePendingApprovals(now time.Time) ([]PendingApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []PendingApproval
+	for id, p := range s.state.Approvals {
+		if !now.Before(p.ExpiresAt) {
+			expired = append(expired, p)
+			delete(s.state.Approvals, id)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	return expired, s.persist()
+}
+
+// MarkOnboarded records that a user was introduced to the bot in a channel.
+// It reports false when they already were, so each user is introduced once
+// per channel even across restarts.
+func (s *Store) MarkOnboarded(user, channel string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := channel + "/" + user
+	if _, ok := s.state.Onboarded[k]; ok {
+		return false, nil
+	}
+	s.state.Onboarded[k] = now
+	if err := s.persist(); err != nil {
+		delete(s.state.Onboarded, k)
+		return false, err
+	}
+	return true, nil
+}
+
+// UnmarkOnboarded forgets that a user was introduced in a channel, for when
+// posting the introduction failed
+func (s *Store) UnmarkOnboarded(user, channel string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.state.Onboarded, channel+"/"+user)
+	return s.persist()
+}
+
+// OptOut records that a user doesn't want their messages translated. It
+// reports false when they had already opted out.
+func (s *Store) OptOut(user string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.state.OptedOut[user]; ok {
+		return false, nil
+	}
+	s.state.OptedOut[user] = now
+	if err := s.persist(); err != nil {
+		delete(s.state.OptedOut, user)
+		return false, err
+	}
+	return true, nil
+}
+
+// OptIn undoes OptOut. It reports false when the user hadn't opted out.
+func (s *Store) OptIn(user string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.state.OptedOut[user]
+	if !ok {
+		return false, nil
+	}
+	delete(s.state.OptedOut, user)
+	if err := s.persist(); err != nil {
+		s.state.OptedOut[user] = at
+		return false, err
+	}
+	return true, nil
+}
+
+// OptedOut reports whether a user opted out of translations
+func (s *Store) OptedOut(user string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.state.OptedOut[user]
+	return ok
+}
+
+// MarkSeen records ts as the latest message seen in a channel, unless a
+// later one already was. A message arrives for every channel message, so
+// the mark is kept in memory and written with the next change or by
+// FlushSeen rather than rewriting the file each time.
+func (s *Store) MarkSeen(channel, ts string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Timestamps have the same number of digits, so they sort as strings
+	if ts <= s.state.LastSeen[channel] {
+		return
+	}
+	s.state.LastSeen[channel] = ts
+	s.seenChanged = true
+}
+
+// LastSeen returns the latest message seen in each channel
+func (s *Store) LastSeen() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]string, len(s.state.LastSeen))
+	for channel, ts := range s.state.LastSeen {
+		seen[channel] = ts
+	}
+	return seen
+}
+
+// FlushSeen writes the state file if MarkSeen changed it since the last
+// write
+func (s *Store) FlushSeen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.seenChanged {
+		return nil
+	}
+	return s.persist()
+}
+
+// DailySpend returns the usage recorded for the daily budget, false when
+// there is none
+func (s *Store) DailySpend() (DailySpend, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.DailySpend == nil {
+		return DailySpend{}, false
+	}
+	return *s.state.DailySpend, true
+}
+
+// SetDailySpend records the usage counted against the daily budget
+func (s *Store) SetDailySpend(spend DailySpend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.DailySpend = &spend
+	return s.persist()
+}
+
+// Degraded reports whether writes to the state file are currently failing
+func (s *Store) Degraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.degraded
+}
+
+// Flush writes the in-memory state to the file, which brings a degraded
+// store back once the file is writable again
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.persist()
+}
+
+// persist saves the state and tracks failures. Once degraded, errors are
+// swallowed since the change is kept in memory; callers must hold s.mu.
+func (s *Store) persist() error {
+	err := s.save()
+	if err == nil {
+		s.seenChanged = false
+		if s.degraded {
+			s.logger.Infof("✅ State store recovered, %s is up to date again", s.path)
+		}
+		s.failures = 0
+		s.degraded = false
+		return nil
+	}
+
+	s.failures++
+	if s.failures < degradeAfter {
+		return err
+	}
+	if !s.degraded {
+		s.degraded = true
+		s.logger.Warnf("⚠️ State store degraded after %d failed writes, keeping state in memory only: %v", s.failures, err)
+	}
+	return nil
+}
+
+// save writes the state to a temporary file and renames it over the old
+// one, so a crash never leaves a truncated file; callers must hold s.mu
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state: %w", err)
+	}
+	return writeFile(s.path, data)
+}
+
+// writeFile writes data to a temporary file and renames it over path
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	return nil
+}
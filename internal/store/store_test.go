@@ -0,0 +1,138 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+func testLogger() *logging.Logger {
+	return logging.New(log.New(io.Discard, "", 0), logging.LevelError)
+}
+
+// openInDir opens a store whose file lives in its own directory, so
+// removing the directory makes every write fail, even as root
+func openInDir(t *testing.T) (*Store, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(filepath.Join(dir, "state.json"), testLogger())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s, dir
+}
+
+func reply(ts string) Reply {
+	return Reply{Channel: "C0000001", OriginalTS: "1.000001", ReplyTS: ts, User: "U0000001", PostedAt: time.Now()}
+}
+
+func TestStoreDegradesAfterRepeatedFailures(t *testing.T) {
+	s, dir := openInDir(t)
+	if err := s.RecordReply(reply("2.000001")); err != nil {
+		t.Fatalf("RecordReply before the outage: %v", err)
+	}
+
+	// The disk goes away
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < degradeAfter; i++ {
+		if err := s.RecordReply(reply(fmt.Sprintf("3.%06d", i))); err == nil {
+			t.Fatalf("write %d during the outage succeeded", i)
+		}
+		if s.Degraded() {
+			t.Fatalf("store degraded after %d failed writes, want %d", i, degradeAfter)
+		}
+	}
+	if err := s.RecordReply(reply("4.000001")); err != nil {
+		t.Errorf("write %d during the outage = %v, want the error swallowed once degraded", degradeAfter, err)
+	}
+	if !s.Degraded() {
+		t.Fatalf("store not degraded after %d failed writes", degradeAfter)
+	}
+
+	// Degraded, changes still land in memory without errors
+	if err := s.RecordReply(reply("5.000001")); err != nil {
+		t.Errorf("RecordReply while degraded = %v, want nil", err)
+	}
+	if ok, err := s.OptOut("U0000002", time.Now()); !ok || err != nil {
+		t.Errorf("OptOut while degraded = %v, %v, want true, nil", ok, err)
+	}
+	if got := len(s.Replies()); got != 2+degradeAfter {
+		t.Errorf("%d replies in memory, want %d", got, 2+degradeAfter)
+	}
+	if err := s.Flush(); err != nil {
+		t.Errorf("Flush while the disk is still gone = %v, want nil", err)
+	}
+	if !s.Degraded() {
+		t.Errorf("store recovered while the disk is still gone")
+	}
+
+	// The disk comes back
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush after the outage: %v", err)
+	}
+	if s.Degraded() {
+		t.Fatalf("store still degraded after a successful write")
+	}
+
+	reopened, err := Open(s.Path(), testLogger())
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	if got := len(reopened.Replies()); got != 2+degradeAfter {
+		t.Errorf("%d replies written after recovery, want every one kept in memory during the outage (%d)", got, 2+degradeAfter)
+	}
+	if !reopened.OptedOut("U0000002") {
+		t.Errorf("opt-out made during the outage was lost")
+	}
+}
+
+func TestStoreSuccessResetsFailures(t *testing.T) {
+	s, dir := openInDir(t)
+
+	for i := 0; i < 3*degradeAfter; i++ {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for j := 1; j < degradeAfter; j++ {
+			s.RecordReply(reply("1.000001"))
+		}
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RecordReply(reply("2.000001")); err != nil {
+			t.Fatalf("round %d: RecordReply after the disk came back: %v", i, err)
+		}
+		if s.Degraded() {
+			t.Fatalf("round %d: store degraded although no %d writes in a row failed", i, degradeAfter)
+		}
+	}
+}
+
+func TestInMemoryStoreNeverDegrades(t *testing.T) {
+	s, err := Open("", testLogger())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for i := 0; i < 2*degradeAfter; i++ {
+		if err := s.RecordReply(reply("1.000001")); err != nil {
+			t.Fatalf("RecordReply: %v", err)
+		}
+	}
+	if s.Degraded() {
+		t.Errorf("in-memory store degraded")
+	}
+}
@@ -0,0 +1,171 @@
+// Package threadctx picks the earlier messages of a thread worth sending to
+// the model as context for translating a reply.
+package threadctx
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Message is a candidate context message
+type Message struct {
+	Author string
+	Text   string
+}
+
+// CharsPerToken is a rough average for English text, good enough to stay
+// within a budget without a tokenizer
+const CharsPerToken = 4
+
+// EstimateTokens approximates the number of tokens text takes up
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + CharsPerToken - 1) / CharsPerToken
+}
+
+// Tokens a message takes in the prompt besides its text, for the author
+// label and quoting
+const messageOverhead = 8
+
+func messageTokens(m Message) int {
+	return EstimateTokens(m.Author) + EstimateTokens(m.Text) + messageOverhead
+}
+
+// trivialReplies carry no context once emoji and punctuation are stripped
+var trivialReplies = map[string]bool{
+	"ok": true, "okay": true, "k": true, "kk": true, "yes": true, "yep": true, "yeah": true,
+	"no": true, "nope": true, "thanks": true, "thank you": true, "thx": true, "ty": true,
+	"lol": true, "lmao": true, "nice": true, "cool": true, "same": true, "agreed": true,
+	"done": true, "+1": true, "sgtm": true, "lgtm": true, "will do": true, "on it": true,
+}
+
+// emojiCode matches Slack's :emoji_name: codes
+var emojiCode = regexp.MustCompile(`:[a-z0-9_+\-']+:`)
+
+// minContextLetters is the fewest letters a message needs to be worth
+// sending as context
+const minContextLetters = 4
+
+// IsTrivial reports whether a message adds nothing as context: emoji only,
+// very short, or a stock reply like "ok" or "+1"
+func IsTrivial(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(emojiCode.ReplaceAllString(text, "")))
+	if trivialReplies[strings.TrimRight(text, "!. ")] {
+		return true
+	}
+
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters++
+		}
+	}
+	return letters < minContextLetters
+}
+
+// Scoring weights: a message by the same author, or one sharing words with
+// the current message, is more likely what the current message refers to.
+// Recency breaks ties.
+const (
+	sameAuthorScore = 2.0
+	overlapScore    = 1.0
+	recencyScore    = 0.5
+)
+
+// Select picks context for current from its thread: the parent and the
+// replies before current, oldest first. The parent is always included
+// unless it has no text, trivial replies never are, and the rest are
+// chosen by relevance until budget tokens are used up. The chosen messages
+// are returned in thread order.
+func Select(parent Message, replies []Message, current Message, budget int) []Message {
+	if budget <= 0 {
+		return nil
+	}
+
+	// The parent sets the topic, so it goes in even if it has to be cut
+	var selected []Message
+	if parent.Text != "" {
+		if tokens := messageTokens(parent); tokens > budget {
+			keep := (budget - messageOverhead - EstimateTokens(parent.Author)) * CharsPerToken
+			if keep <= 0 {
+				return nil
+			}
+			runes := []rune(parent.Text)
+			if keep < len(runes) {
+				parent.Text = string(runes[:keep])
+			}
+			return []Message{parent}
+		}
+		budget -= messageTokens(parent)
+		selected = append(selected, parent)
+	}
+
+	words := contentWords(current.Text)
+	type candidate struct {
+		index int
+		score float64
+	}
+	var candidates []candidate
+	for i, m := range replies {
+		if IsTrivial(m.Text) {
+			continue
+		}
+
+		score := recencyScore * float64(i+1) / float64(len(replies)+1)
+		if m.Author != "" && m.Author == current.Author {
+			score += sameAuthorScore
+		}
+		score += overlapScore * float64(overlap(words, contentWords(m.Text)))
+		candidates = append(candidates, candidate{index: i, score: score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	chosen := make(map[int]bool)
+	for _, c := range candidates {
+		tokens := messageTokens(replies[c.index])
+		if tokens > budget {
+			continue
+		}
+		budget -= tokens
+		chosen[c.index] = true
+	}
+
+	for i, m := range replies {
+		if chosen[i] {
+			selected = append(selected, m)
+		}
+	}
+	return selected
+}
+
+// minWordLength skips short words, which are mostly stop words
+const minWordLength = 4
+
+// contentWords returns the distinct lowercased words of text worth
+// comparing
+func contentWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) >= minWordLength {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// overlap counts the words two sets have in common
+func overlap(a, b map[string]bool) int {
+	n := 0
+	for word := range a {
+		if b[word] {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,102 @@
+package threadctx
+
+import (
+	"strings"
+	"testing"
+)
+
+func texts(messages []Message) []string {
+	out := make([]string, len(messages))
+	for i, m := range messages {
+		out[i] = m.Text
+	}
+	return out
+}
+
+func TestSelectKeepsParent(t *testing.T) {
+	parent := Message{Author: "Deploy Bot", Text: "deploy of the billing service failed"}
+	replies := []Message{
+		{Author: "alice", Text: "looking into the billing deploy now"},
+		{Author: "bob", Text: "ok"},
+		{Author: "carol", Text: "rollback finished on all hosts"},
+	}
+	current := Message{Author: "alice", Text: "billing is back up"}
+
+	got := Select(parent, replies, current, 1000)
+	want := []string{parent.Text, replies[0].Text, replies[2].Text}
+	if strings.Join(texts(got), "|") != strings.Join(want, "|") {
+		t.Errorf("Select = %q, want %q", texts(got), want)
+	}
+}
+
+func TestSelectWithoutParentText(t *testing.T) {
+	replies := []Message{
+		{Author: "alice", Text: "who uploaded this screenshot?"},
+		{Author: "bob", Text: "that was me, from the staging dashboard"},
+	}
+	current := Message{Author: "carol", Text: "staging looks broken"}
+
+	got := Select(Message{Author: "bob"}, replies, current, 1000)
+	if len(got) != 2 || got[0] != replies[0] || got[1] != replies[1] {
+		t.Errorf("Select = %q, want both replies and no empty parent", texts(got))
+	}
+}
+
+func TestSelectCutsLongParent(t *testing.T) {
+	parent := Message{Author: "alice", Text: strings.Repeat("word ", 200)}
+	replies := []Message{{Author: "bob", Text: "a reply that doesn't fit any more"}}
+
+	got := Select(parent, replies, Message{Author: "carol", Text: "hm"}, 50)
+	if len(got) != 1 {
+		t.Fatalf("Select = %d messages, want only the cut parent", len(got))
+	}
+	if tokens := messageTokens(got[0]); tokens > 50 {
+		t.Errorf("cut parent takes %d tokens, want at most 50", tokens)
+	}
+	if !strings.HasPrefix(parent.Text, got[0].Text) {
+		t.Errorf("cut parent %q isn't a prefix of the parent", got[0].Text)
+	}
+}
+
+func TestSelectPrefersRelevantReplies(t *testing.T) {
+	parent := Message{Author: "alice", Text: "planning the offsite"}
+	replies := []Message{
+		{Author: "bob", Text: "the venue needs a deposit by friday"},
+		{Author: "carol", Text: "catering quote came in at twice the budget"},
+		{Author: "dave", Text: "unrelated chatter about lunch options"},
+	}
+	current := Message{Author: "erin", Text: "can we negotiate the catering budget"}
+	budget := messageTokens(parent) + messageTokens(replies[1])
+
+	got := Select(parent, replies, current, budget)
+	want := []string{parent.Text, replies[1].Text}
+	if strings.Join(texts(got), "|") != strings.Join(want, "|") {
+		t.Errorf("Select = %q, want %q", texts(got), want)
+	}
+}
+
+func TestSelectNoBudget(t *testing.T) {
+	if got := Select(Message{Text: "parent"}, nil, Message{}, 0); got != nil {
+		t.Errorf("Select with no budget = %q, want nothing", texts(got))
+	}
+}
+
+func TestIsTrivial(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"ok", true},
+		{"Thanks!", true},
+		{":+1:", true},
+		{"lgtm :shipit:", true},
+		{"yo", true},
+		{"the build is green again", false},
+		{"ok but the tests are flaky", false},
+	}
+	for _, tt := range tests {
+		if got := IsTrivial(tt.text); got != tt.want {
+			t.Errorf("IsTrivial(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
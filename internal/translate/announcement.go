@@ -0,0 +1,73 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Announcement is a serious summary of an announcement together with its
+// translation
+type Announcement struct {
+	TLDR        string `json:"tldr"`
+	Translation string `json:"translation"`
+}
+
+// announcementSchema is the JSON schema Summarize asks the model to follow
+var announcementSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"tldr": {"type": "string", "description": "A serious one-sentence summary of the announcement"},
+		"translation": {"type": "string", "description": "The announcement translated as instructed"}
+	},
+	"required": ["tldr", "translation"],
+	"additionalProperties": false
+}`)
+
+// Summarize returns a serious one-sentence TL;DR of an announcement and its
+// translation into the given style, from a single structured call to
+// complete
+func Summarize(ctx context.Context, style Style, message, username string, complete CompleteFunc) (Announcement, error) {
+	message = normalizeInput(message)
+	username = normalizeName(username)
+
+	var prompt strings.Builder
+	if err := style.UserPrompt.Execute(&prompt, promptData{Username: username, Message: message}); err != nil {
+		return Announcement{}, fmt.Errorf("error rendering prompt: %w", err)
+	}
+
+	content, err := complete(ctx, Completion{
+		System: style.SystemPrompt + " You also write TL;DRs: one serious, plain sentence that tells a busy reader " +
+			"what the announcement means for them, without slang or emoji.",
+		User: "This message is an announcement to the whole channel. Put its TL;DR in \"tldr\" and " +
+			"the translation asked for below in \"translation\".\n\n" + prompt.String(),
+		Temperature: 0.7,
+		Schema:      &Schema{Name: "announcement", Schema: announcementSchema},
+	})
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var announcement Announcement
+	if err := decodeJSON(content, &announcement); err != nil {
+		return Announcement{}, fmt.Errorf("error decoding announcement response: %w", err)
+	}
+
+	announcement.TLDR = strings.TrimSpace(announcement.TLDR)
+	announcement.Translation = strings.TrimSpace(announcement.Translation)
+	if announcement.TLDR == "" || announcement.Translation == "" {
+		return Announcement{}, fmt.Errorf("announcement response is missing the TL;DR or the translation")
+	}
+	return announcement, nil
+}
+
+// decodeJSON decodes a structured reply into out. Unknown fields are
+// rejected, so a reply that drifts from the schema is an error rather than
+// silently half-decoded.
+func decodeJSON(content string, out any) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
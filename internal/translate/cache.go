@@ -0,0 +1,144 @@
+package translate
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// Cache is a Translator that remembers the translations of another one, so
+// a message that keeps coming back (a standup template, "deploying now")
+// costs one call until it expires. The least recently used translations
+// are forgotten first. Vibe checks and announcements aren't cached. It is
+// safe for concurrent use.
+type Cache struct {
+	Translator
+	size   int
+	ttl    time.Duration
+	logger *logging.Logger
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	// Most recently used first
+	order *list.List
+}
+
+// cacheEntry is a cached translation
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	result  TranslationResult
+	expires time.Time
+}
+
+// NewCache caches up to size translations of next for ttl each
+func NewCache(next Translator, size int, ttl time.Duration, logger *logging.Logger) *Cache {
+	return &Cache{
+		Translator: next,
+		size:       size,
+		ttl:        ttl,
+		logger:     logger,
+		entries:    make(map[[sha256.Size]byte]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// noCacheKey marks a context whose translations skip the cache
+type noCacheKey struct{}
+
+// WithoutCache returns a copy of ctx whose translations are always made
+// afresh, like re-rolls, which would be pointless if they returned the
+// same translation again. The fresh translation still replaces the cached
+// one.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// Translate returns the cached translation of a message, or translates it
+// and remembers the result. Messages with thread context or quotes are
+// always translated, since the context changes the translation.
+func (c *Cache) Translate(ctx context.Context, req TranslationRequest) (TranslationResult, error) {
+	if len(req.Thread) > 0 || len(req.Quotes) > 0 {
+		return c.Translator.Translate(ctx, req)
+	}
+
+	key := cacheKey(req)
+	if skip, _ := ctx.Value(noCacheKey{}).(bool); !skip {
+		if result, ok := c.get(key, time.Now()); ok {
+			logging.FromContext(ctx, c.logger).Debugf("Translation (cached): %s", result.Text)
+			return result, nil
+		}
+	}
+
+	result, err := c.Translator.Translate(ctx, req)
+	if err != nil {
+		return TranslationResult{}, err
+	}
+	c.put(key, result, time.Now())
+	return result, nil
+}
+
+// cacheKey hashes what the translation depends on: the style's prompts,
+// the normalized text and the author's name. The name is part of the key
+// rather than left out of the prompt, because prompts may address the
+// author and the model often echoes the name, which must never end up in
+// someone else's translation. Anonymous messages have no name, so they
+// share entries across authors.
+func cacheKey(req TranslationRequest) [sha256.Size]byte {
+	var key strings.Builder
+	for _, part := range []string{
+		req.Style.Name,
+		req.Style.SystemPrompt,
+		req.OutputLanguage,
+		normalizeName(req.Username),
+		strings.Join(strings.Fields(normalizeInput(req.Message)), " "),
+	} {
+		key.WriteString(part)
+		key.WriteByte(0)
+	}
+	return sha256.Sum256([]byte(key.String()))
+}
+
+// get returns a translation that hasn't expired, marking it recently used
+func (c *Cache) get(key [sha256.Size]byte, now time.Time) (TranslationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return TranslationResult{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !now.Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return TranslationResult{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry.result, true
+}
+
+// put remembers a translation, forgetting the least recently used one when
+// the cache is full
+func (c *Cache) put(key [sha256.Size]byte, result TranslationResult, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.result, entry.expires = result, now.Add(c.ttl)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, expires: now.Add(c.ttl)})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
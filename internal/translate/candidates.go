@@ -0,0 +1,23 @@
+package translate
+
+import "context"
+
+// candidatesKey carries the *[]string collecting a translation's
+// candidates
+type candidatesKey struct{}
+
+// withCandidates returns a copy of ctx that collects the replies offered
+// with OfferCandidates into the returned slice
+func withCandidates(ctx context.Context) (context.Context, *[]string) {
+	offered := new([]string)
+	return context.WithValue(ctx, candidatesKey{}, offered), offered
+}
+
+// OfferCandidates hands the replies a provider got besides the one it
+// returned, for a completion asking for Candidates, back to the
+// translation. They become the translation's Candidates.
+func OfferCandidates(ctx context.Context, replies ...string) {
+	if offered, ok := ctx.Value(candidatesKey{}).(*[]string); ok {
+		*offered = append(*offered, replies...)
+	}
+}
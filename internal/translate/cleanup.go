@@ -0,0 +1,80 @@
+package translate
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// preamblePattern matches the introduction models put before a translation
+// now and then, e.g. "Sure! Here's the Gen Alpha version:" or
+// "Translation:"
+var preamblePattern = regexp.MustCompile(`(?i)^\s*` +
+	`(?:(?:sure(?: thing)?|okay|ok|certainly|of course|absolutely|alright|bet|got it)[!.,]*\s+)?` +
+	`(?:here(?:'s|’s| is)\b[^:\n]{0,60}\b(?:translation|translated|version|rewrite)\b[^:\n]{0,40}:|` +
+	`here (?:you|ya) go[!.]*(?::|\s*\n)|` +
+	`(?:the )?(?:[a-z-]+ ){0,2}(?:translation|translated message|version)\b[^:\n]{0,40}:)\s*`)
+
+// interjectionPattern matches a first line that is only an acknowledgement,
+// like "Sure!"
+var interjectionPattern = regexp.MustCompile(`(?i)^\s*(?:sure(?: thing)?|okay|ok|certainly|of course|absolutely|alright|got it)[!.]*\s*\n`)
+
+// headerPattern matches a markdown header line, which Slack doesn't render
+var headerPattern = regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+(.*)$`)
+
+// titlePattern matches a header the model added to name its reply, like
+// "# Gen Alpha Translation"
+var titlePattern = regexp.MustCompile(`(?i)^[ \t]*#{1,6}[ \t]+.{0,40}\b(?:translation|translated|version)\b.{0,20}$`)
+
+// quotePairs are the quotation marks a whole translation is sometimes
+// wrapped in
+var quotePairs = [][2]string{
+	{`"`, `"`},
+	{"“", "”"},
+	{"'", "'"},
+	{"‘", "’"},
+	{"«", "»"},
+}
+
+// cleanTranslation strips what models wrap a translation in despite being
+// asked not to: a preamble, a markdown header and a pair of quotation
+// marks around the whole thing. Should nothing be left, the reply is
+// returned as it was.
+func cleanTranslation(text string) string {
+	cleaned := strings.TrimSpace(text)
+
+	cleaned = interjectionPattern.ReplaceAllString(cleaned, "")
+	cleaned = strings.TrimSpace(preamblePattern.ReplaceAllString(cleaned, ""))
+
+	// A title like "# Gen Alpha Translation" goes, other headers only lose
+	// their markers
+	if lines := strings.SplitN(cleaned, "\n", 2); len(lines) == 2 && titlePattern.MatchString(lines[0]) {
+		cleaned = strings.TrimSpace(lines[1])
+	}
+	cleaned = headerPattern.ReplaceAllString(cleaned, "$1")
+
+	cleaned = unquote(cleaned)
+
+	if cleaned == "" {
+		return strings.TrimSpace(text)
+	}
+	return cleaned
+}
+
+// unquote removes a pair of quotation marks around the whole text. Text
+// that merely starts and ends with a quote, like `"Hi" she said "bye"`,
+// keeps them.
+func unquote(text string) string {
+	for _, pair := range quotePairs {
+		open, close := pair[0], pair[1]
+		if utf8.RuneCountInString(text) < 2 || !strings.HasPrefix(text, open) || !strings.HasSuffix(text, close) {
+			continue
+		}
+		inner := text[len(open) : len(text)-len(close)]
+		if strings.Contains(inner, open) || strings.Contains(inner, close) {
+			continue
+		}
+		return strings.TrimSpace(inner)
+	}
+	return text
+}
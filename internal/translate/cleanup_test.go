@@ -0,0 +1,51 @@
+package translate
+
+import "testing"
+
+func TestCleanTranslation(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "clean", in: "no cap the meeting is bussin", want: "no cap the meeting is bussin"},
+		{name: "surrounding whitespace", in: "\n  lowkey fire  \n", want: "lowkey fire"},
+
+		// Preambles
+		{name: "sure, here's the version", in: "Sure! Here's the Gen Alpha version: it's giving Monday", want: "it's giving Monday"},
+		{name: "here's the translation on its own line", in: "Here's the translation:\nslay fr", want: "slay fr"},
+		{name: "curly apostrophe", in: "Here’s your Gen Alpha translation: W rizz", want: "W rizz"},
+		{name: "translation label", in: "Translation: skibidi standup at 10", want: "skibidi standup at 10"},
+		{name: "styled translation label", in: "Gen Alpha translation: mid tbh", want: "mid tbh"},
+		{name: "here you go", in: "Here you go!\nthe vibes are immaculate", want: "the vibes are immaculate"},
+		{name: "interjection line", in: "Sure!\nbestie the deploy is cooked", want: "bestie the deploy is cooked"},
+		{name: "interjection and preamble", in: "Okay!\nHere is the translated version: ong", want: "ong"},
+		{name: "colon in the message itself", in: "Reminder: standup is at 10", want: "Reminder: standup is at 10"},
+
+		// Quotes
+		{name: "straight quotes", in: `"it's giving main character"`, want: "it's giving main character"},
+		{name: "curly quotes", in: "“rizz check passed”", want: "rizz check passed"},
+		{name: "guillemets", in: "« sheesh »", want: "sheesh"},
+		{name: "preamble and quotes", in: `Sure! Here's the translation: "fr fr"`, want: "fr fr"},
+		{name: "quotes inside", in: `"Hi" she said "bye"`, want: `"Hi" she said "bye"`},
+		{name: "single quote character", in: `"`, want: `"`},
+
+		// Markdown headers
+		{name: "title header", in: "# Gen Alpha Translation\nthe sprint is bussin", want: "the sprint is bussin"},
+		{name: "title header and quotes", in: "## Translated Version\n\"no cap\"", want: "no cap"},
+		{name: "other headers lose their markers", in: "## Action items\n- ship it fr", want: "Action items\n- ship it fr"},
+		{name: "hashtag isn't a header", in: "#general is lit", want: "#general is lit"},
+
+		// Nothing left
+		{name: "only a preamble", in: "Translation:", want: "Translation:"},
+		{name: "only quotes", in: `""`, want: `""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanTranslation(tt.in); got != tt.want {
+				t.Errorf("cleanTranslation(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package translate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/user/slack-bot-api/config"
+)
+
+// languageNames names the languages DetectLanguage recognizes, and others
+// OUTPUT_LANGUAGE may ask for, by ISO 639-1 code
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// LanguageName names the language with an ISO 639-1 code, or returns the
+// code when it's not one of the known languages
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// stopwords are short, frequent words telling the languages written in
+// Latin script apart. Words common to several languages count for each.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "it", "that", "this", "you", "for", "with", "was", "have", "not", "what", "be", "on", "we", "i"},
+	"es": {"el", "la", "los", "las", "que", "de", "y", "es", "en", "un", "una", "por", "para", "con", "no", "lo", "se", "del", "pero", "muy", "está", "estoy", "hay"},
+	"fr": {"le", "la", "les", "et", "est", "un", "une", "des", "du", "que", "pour", "dans", "pas", "ce", "je", "vous", "nous", "avec", "sur", "c'est", "mais"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "ich", "du", "wir", "mit", "auf", "zu", "den", "dem", "es", "sie", "auch", "noch"},
+	"pt": {"o", "a", "os", "as", "que", "de", "e", "é", "em", "um", "uma", "para", "com", "não", "do", "da", "se", "mas", "muito", "está", "você"},
+	"it": {"il", "la", "le", "che", "di", "e", "è", "un", "una", "per", "con", "non", "del", "della", "sono", "ma", "anche", "questo", "ci"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "van", "ik", "je", "dat", "wij", "met", "op", "te", "ook", "maar", "zijn", "er"},
+}
+
+// minStopwords is how many stopwords a message written in Latin script
+// needs before its language is guessed at all
+const minStopwords = 2
+
+// DetectLanguage guesses the language a message is written in, cheaply
+// and without a model: by its script, and for Latin script by counting
+// frequent words. It returns an ISO 639-1 code, or "" when the message is
+// too short or mixed to tell.
+func DetectLanguage(text string) string {
+	if language := detectScript(text); language != "" {
+		return language
+	}
+
+	counts := make(map[string]int, len(stopwords))
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for language, words := range stopwords {
+			for _, stopword := range words {
+				if word == stopword {
+					counts[language]++
+					break
+				}
+			}
+		}
+	}
+	// Letters only some languages use settle close calls
+	for language, letters := range map[string]string{"es": "ñ¿¡", "pt": "ãõç", "de": "ßäöü", "fr": "çèêëœ"} {
+		if strings.ContainsAny(text, letters) {
+			counts[language]++
+		}
+	}
+
+	best, bestCount, tied := "", 0, false
+	for language, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tied = language, count, false
+		case count == bestCount:
+			tied = true
+		}
+	}
+	if bestCount < minStopwords || tied {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of a message mostly written in a
+// script used by one language, or "" for Latin script and mixed messages
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	// Japanese mixes kanji with kana
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	for language, count := range counts {
+		if count*2 > letters {
+			return language
+		}
+	}
+	return ""
+}
+
+// languagePrompt tells the model which language a message is in and which
+// the translation is to be in, or returns "" for an English message
+// translated into English, which the prompts are written for
+func languagePrompt(detected, output string) string {
+	switch {
+	case output == config.OutputLanguageMatch && detected == "":
+		return "The message may not be in English. Understand it in its own language, and write the translation in that same language."
+	case output == config.OutputLanguageMatch:
+		output = detected
+	}
+
+	var prompt string
+	if detected != "" && detected != output {
+		prompt = fmt.Sprintf("The message is written in %s. Understand it in %[1]s first, then translate what it means. ", LanguageName(detected))
+	}
+	if output == "en" && prompt == "" {
+		return ""
+	}
+	return prompt + fmt.Sprintf("Write the translation in %s, whatever language the instructions are in.", LanguageName(output))
+}
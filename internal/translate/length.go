@@ -0,0 +1,74 @@
+package translate
+
+import (
+	"context"
+
+	"github.com/user/slack-bot-api/config"
+	"github.com/user/slack-bot-api/internal/logging"
+	"github.com/user/slack-bot-api/internal/threadctx"
+)
+
+// lengthPrompt keeps the model from turning a one-liner into paragraphs
+const lengthPrompt = "Keep the translation roughly as long as the message: a one-liner stays a one-liner."
+
+// replyTokens is how many tokens a translation of text may take: twice its
+// own, leaving room for slang and emoji without inviting an essay
+func replyTokens(text string) int {
+	return 2 * threadctx.EstimateTokens(text)
+}
+
+// OutputLimits bound the length of replies, shared by all providers
+type OutputLimits struct {
+	// MinTokens and MaxTokens clamp the cap a completion asks for
+	MinTokens int
+	MaxTokens int
+	// RetryTruncated sends a reply cut off below MaxTokens once more,
+	// capped at MaxTokens
+	RetryTruncated bool
+}
+
+// NewOutputLimits returns the limits configured with OPENAI_MIN_TOKENS,
+// OPENAI_MAX_TOKENS and OPENAI_RETRY_TRUNCATED
+func NewOutputLimits(cfg *config.Config) OutputLimits {
+	return OutputLimits{
+		MinTokens:      cfg.OpenAIMinTokens,
+		MaxTokens:      cfg.OpenAIMaxTokens,
+		RetryTruncated: cfg.OpenAIRetryTruncated,
+	}
+}
+
+// Tokens returns the cap of a completion asking for requested tokens, or
+// for no particular length when it's 0
+func (l OutputLimits) Tokens(requested int) int {
+	switch {
+	case requested <= 0 || requested > l.MaxTokens:
+		return l.MaxTokens
+	case requested < l.MinTokens:
+		return l.MinTokens
+	default:
+		return requested
+	}
+}
+
+// Complete gets a completion asking for requested tokens from send, which
+// makes the request with the given cap and reports whether the reply was
+// cut off by it. A cut-off reply is logged, and retried with MaxTokens
+// when RetryTruncated is set; if that's cut off too it's used as it is.
+func (l OutputLimits) Complete(ctx context.Context, logger *logging.Logger, provider string, requested int, send func(maxTokens int) (string, bool, error)) (string, error) {
+	maxTokens := l.Tokens(requested)
+	content, truncated, err := send(maxTokens)
+	if err != nil || !truncated {
+		return content, err
+	}
+
+	if !l.RetryTruncated || maxTokens >= l.MaxTokens {
+		logger.Warnf("⚠️ %s reply was cut off at %d tokens", provider, maxTokens)
+		return content, nil
+	}
+	logger.Warnf("⚠️ %s reply was cut off at %d tokens, retrying with %d", provider, maxTokens, l.MaxTokens)
+	content, truncated, err = send(l.MaxTokens)
+	if err == nil && truncated {
+		logger.Warnf("⚠️ %s reply was cut off at %d tokens", provider, l.MaxTokens)
+	}
+	return content, err
+}
@@ -0,0 +1,11 @@
+package translate
+
+import "context"
+
+// Moderator checks text for content unfit for a work Slack before it's
+// posted
+type Moderator interface {
+	// Moderate scores text from 0 to 1 in each category of harmful content,
+	// like "harassment" or "hate"
+	Moderate(ctx context.Context, text string) (map[string]float64, error)
+}
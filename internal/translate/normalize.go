@@ -0,0 +1,52 @@
+package translate
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxInputBytes caps the size of any single piece of user text sent to
+// the model. Anything longer is almost certainly pasted logs, not a message.
+const maxInputBytes = 32 * 1024
+
+// normalizeInput makes user-provided text safe to embed in a request: invalid
+// UTF-8 (e.g. unpaired surrogates from Windows copy-paste) is replaced with
+// U+FFFD, C0 control characters other than newline and tab are stripped, and
+// the result is capped at maxInputBytes on a rune boundary.
+func normalizeInput(s string) string {
+	s = strings.ToValidUTF8(s, "�")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+
+	if len(s) > maxInputBytes {
+		cut := maxInputBytes
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+
+	return s
+}
+
+// anonymousName stands in for the author of a message in the prompt when
+// no name is given, so the model never sees or echoes one
+const anonymousName = "someone"
+
+// normalizeName is normalizeInput for the name of a message's author, with
+// anonymousName for an empty one
+func normalizeName(name string) string {
+	if name = normalizeInput(name); name == "" {
+		return anonymousName
+	}
+	return name
+}
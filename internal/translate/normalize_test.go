@@ -0,0 +1,44 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeInput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "no cap 🔥", want: "no cap 🔥"},
+		{name: "newlines and tabs kept", in: "one\n\ttwo", want: "one\n\ttwo"},
+		{name: "CRLF", in: "one\r\ntwo", want: "one\ntwo"},
+		{name: "lone surrogate", in: "a\xed\xa0\x80b", want: "a�b"},
+		{name: "stray continuation byte", in: "a\x80b", want: "a�b"},
+		{name: "cut-off sequence", in: "a\xf0\x9f\x94", want: "a�"},
+		{name: "control characters", in: "a\x00b\x1b[31mc\x7fd\re", want: "ab[31mcde"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeInput(tt.in); got != tt.want {
+				t.Errorf("normalizeInput(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// Text over maxInputBytes is cut on a rune boundary
+func TestNormalizeInputCap(t *testing.T) {
+	got := normalizeInput("a" + strings.Repeat("🔥", maxInputBytes))
+	if len(got) > maxInputBytes {
+		t.Errorf("normalizeInput kept %d bytes, want at most %d", len(got), maxInputBytes)
+	}
+	if !utf8.ValidString(got) {
+		t.Error("normalizeInput cut a rune in half")
+	}
+	if want := maxInputBytes - 3; len(got) != want {
+		t.Errorf("normalizeInput kept %d bytes, want %d", len(got), want)
+	}
+}
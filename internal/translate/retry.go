@@ -0,0 +1,142 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/user/slack-bot-api/internal/logging"
+)
+
+// Backoff between retries when the provider doesn't send Retry-After
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// StatusError is a non-success response from a provider's API
+type StatusError struct {
+	// Provider names the API, e.g. "OpenAI"
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+	// Permanent marks errors the provider says sending again won't fix,
+	// whatever the status code, like an exhausted quota
+	Permanent bool
+}
+
+func (e *StatusError) Error() string {
+	return e.Provider + " API error: " + e.Body + ", status code: " + strconv.Itoa(e.StatusCode)
+}
+
+// Unwrap marks retryable 429 and 5xx responses as ErrOverloaded
+func (e *StatusError) Unwrap() error {
+	if e.retryable() {
+		return ErrOverloaded
+	}
+	return nil
+}
+
+// retryable reports whether the request may succeed if sent again
+func (e *StatusError) retryable() bool {
+	return !e.Permanent && (e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError)
+}
+
+// Retry calls send up to maxAttempts times, backing off between attempts,
+// for as long as it fails with a network error or a 429/5xx StatusError.
+// provider names the API in logs and errors.
+func Retry(ctx context.Context, logger *logging.Logger, provider string, maxAttempts int, send func() error) error {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := retryDelay(lastErr, attempt-1)
+			logger.Warnf("🔁 Retrying %s request in %v (attempt %d/%d) after: %v", provider, delay, attempt, maxAttempts, lastErr)
+			if err := sleepContext(ctx, delay); err != nil {
+				return fmt.Errorf("gave up retrying %s request: %w", provider, lastErr)
+			}
+		}
+
+		err := send()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !shouldRetry(ctx, err) {
+			return err
+		}
+	}
+
+	var statusErr *StatusError
+	if errors.As(lastErr, &statusErr) {
+		return fmt.Errorf("%s request failed after %d attempts, last status code %d: %w", provider, maxAttempts, statusErr.StatusCode, lastErr)
+	}
+	return fmt.Errorf("%s request failed after %d attempts: %w", provider, maxAttempts, lastErr)
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating. Network
+// errors and 429/5xx responses are; other 4xx responses and errors caused
+// by the context ending are not.
+func shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryable()
+	}
+	return true
+}
+
+// retryDelay returns how long to wait before the given retry (1-based),
+// preferring the server's Retry-After over exponential backoff with jitter
+func retryDelay(err error, retry int) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if statusErr.RetryAfter > retryMaxDelay {
+			return retryMaxDelay
+		}
+		return statusErr.RetryAfter
+	}
+
+	delay := retryBaseDelay << (retry - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	// Full jitter in the upper half so concurrent retries spread out
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
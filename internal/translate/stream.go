@@ -0,0 +1,31 @@
+package translate
+
+import "context"
+
+// partialsKey carries the func a streamed translation's partial text is
+// sent with
+type partialsKey struct{}
+
+// WithPartials returns a copy of ctx whose translations, when the provider
+// streams them, send the text received so far on partials as it grows.
+// Sends never block: a partial the receiver isn't ready for is dropped,
+// the next one includes it anyway. A nil channel stops the partials of an
+// outer WithPartials from being sent.
+func WithPartials(ctx context.Context, partials chan<- string) context.Context {
+	if partials == nil {
+		return context.WithValue(ctx, partialsKey{}, (func(string))(nil))
+	}
+	return context.WithValue(ctx, partialsKey{}, func(text string) {
+		select {
+		case partials <- text:
+		default:
+		}
+	})
+}
+
+// partialsFrom returns the func partial text is sent with, or nil when
+// nobody wants it
+func partialsFrom(ctx context.Context) func(string) {
+	send, _ := ctx.Value(partialsKey{}).(func(string))
+	return send
+}
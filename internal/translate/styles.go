@@ -0,0 +1,109 @@
+package translate
+
+import (
+	"sort"
+	"text/template"
+
+	"github.com/user/slack-bot-api/config"
+)
+
+// DefaultStyle is the translation style used when none is configured
+const DefaultStyle = "genalpha"
+
+// Style is a voice the bot can translate into
+type Style struct {
+	Name         string
+	Description  string
+	SystemPrompt string
+	// UserPrompt renders the translation request from promptData
+	UserPrompt *template.Template
+}
+
+// builtinStyles are the styles available besides the configurable
+// genalpha default
+var builtinStyles = []Style{
+	{
+		Name:        "shakespeare",
+		Description: "Early modern English, as if from a Shakespeare play",
+		SystemPrompt: "You are a translator into Shakespearean English. Rewrite normal messages as if spoken by a character in a Shakespeare play, " +
+			"with thee, thou, flourishes and iambic flair, while keeping them understandable.",
+		UserPrompt: mustParseStyleTemplate("shakespeare",
+			"Translate the following message into Shakespearean English. Keep the original meaning. The message is from {{.Username}}: \"{{.Message}}\""),
+	},
+	{
+		Name:        "corporate",
+		Description: "Buzzword-heavy corporate speak",
+		SystemPrompt: "You are a translator into corporate speak. Rewrite normal messages the way an overly enthusiastic middle manager would, " +
+			"full of synergies, alignment, circling back and moving the needle, while keeping the original meaning recognizable.",
+		UserPrompt: mustParseStyleTemplate("corporate",
+			"Translate the following message into corporate speak. Keep the original meaning. The message is from {{.Username}}: \"{{.Message}}\""),
+	},
+	{
+		Name:         "pirate",
+		Description:  "Salty pirate talk",
+		SystemPrompt: "You are a pirate translator. Rewrite normal messages in exaggerated pirate talk with arrs, mateys and nautical metaphors, but keep them understandable.",
+		UserPrompt: mustParseStyleTemplate("pirate",
+			"Translate the following message into pirate talk. Keep the original meaning. The message is from {{.Username}}: \"{{.Message}}\""),
+	},
+}
+
+func mustParseStyleTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Option("missingkey=error").Parse(text))
+}
+
+// Styles are the voices the bot can translate into, by name
+type Styles map[string]Style
+
+// NewStyles builds the style registry. The genalpha style uses the
+// configured prompts, so OPENAI_SYSTEM_PROMPT and
+// OPENAI_USER_PROMPT_TEMPLATE customize the default voice.
+func NewStyles(cfg *config.Config) Styles {
+	styles := make(Styles, len(builtinStyles)+1)
+	styles[DefaultStyle] = Style{
+		Name:         DefaultStyle,
+		Description:  "Gen Alpha slang with emojis and youth trends",
+		SystemPrompt: cfg.OpenAISystemPrompt,
+		UserPrompt:   cfg.OpenAIUserPromptTemplate,
+	}
+	for _, style := range builtinStyles {
+		styles[style.Name] = style
+	}
+	return styles
+}
+
+// Style returns the translation style with the given name
+func (s Styles) Style(name string) (Style, bool) {
+	style, ok := s[name]
+	return style, ok
+}
+
+// Names returns the names of all available styles, sorted
+func (s Styles) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// accessibleInstructions are added to the system prompt for channels with
+// accessible output
+const accessibleInstructions = " The output will be read aloud by screen readers: use at most 2 emoji, " +
+	"never stretch words by repeating letters (write \"so\", not \"sooooo\") and never write words in all caps."
+
+// Accessible returns a copy of the style whose prompt asks for output that
+// works well with screen readers
+func (s Style) Accessible() Style {
+	s.SystemPrompt += accessibleInstructions
+	return s
+}
+
+// WithGlossary returns a copy of the style whose system prompt ends with
+// the team's glossary, when there is one
+func (s Style) WithGlossary(glossary string) Style {
+	if glossary != "" {
+		s.SystemPrompt += "\n\n" + glossary
+	}
+	return s
+}
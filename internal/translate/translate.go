@@ -0,0 +1,230 @@
+// Package translate defines how the bot talks to a language model. A
+// Translator turns messages into the configured voice; providers like the
+// OpenAI client implement it on top of a single completion call, sharing the
+// prompts built here.
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/codeblock"
+)
+
+// ErrOverloaded is returned when a provider rejects a request because of
+// load (429 or 5xx), as opposed to a problem with the request itself
+var ErrOverloaded = errors.New("LLM API overloaded")
+
+// Translator translates messages with a language model
+type Translator interface {
+	// Translate translates a message into a style
+	Translate(ctx context.Context, req TranslationRequest) (TranslationResult, error)
+	// VibeCheck sums up the tone of a message in one Gen Alpha line
+	VibeCheck(ctx context.Context, message, username string) (string, error)
+	// Summarize returns a serious TL;DR of an announcement together with
+	// its translation into a style
+	Summarize(ctx context.Context, style Style, message, username string) (Announcement, error)
+	// Complete sends a system and user prompt of the caller's own and
+	// returns the reply, for composing steps the other methods don't
+	// cover
+	Complete(ctx context.Context, system, user string, opts ...CompleteOption) (string, error)
+	// Model names the model translations come from
+	Model() string
+}
+
+// TranslationRequest is a message to translate
+type TranslationRequest struct {
+	Style   Style
+	Message string
+	// Username is the author's name; empty translates the message
+	// anonymously, the prompt names no author
+	Username string
+	// Thread holds earlier messages of the thread the message replies in,
+	// and Quotes messages it shares or forwards. Both are context only.
+	Thread []QuotedMessage
+	Quotes []QuotedMessage
+	// MaxMessageTokens cuts a longer message short, at a sentence; 0
+	// translates it whole
+	MaxMessageTokens int
+	// OutputLanguage is the ISO 639-1 code of the language to translate
+	// into, or config.OutputLanguageMatch for the message's own; empty is
+	// English
+	OutputLanguage string
+}
+
+// TranslationResult is a translated message
+type TranslationResult struct {
+	Text string
+	// Truncated reports that only the start of the message was translated
+	Truncated bool
+	// Candidates are other translations the model offered, for re-rolls
+	Candidates []string
+	// Language is the ISO 639-1 code of the language the message was
+	// detected to be in, empty when it couldn't be told
+	Language string
+}
+
+// QuotedMessage is a message shared or forwarded inside the message being
+// translated. It is given to the model as context only.
+type QuotedMessage struct {
+	Author string
+	Text   string
+}
+
+// Completion is a single prompt for a model
+type Completion struct {
+	System      string
+	User        string
+	Temperature float64
+	// MaxTokens caps the reply's length, clamped to the provider's
+	// OutputLimits; 0 leaves it to them
+	MaxTokens int
+	// Candidates lets a provider ask for several replies, returning one
+	// and offering the others with OfferCandidates
+	Candidates bool
+	// Schema optionally asks for a JSON reply matching it
+	Schema *Schema
+	// Partials, when set, lets a provider stream the reply, calling it
+	// with the text received so far as it grows
+	Partials func(text string)
+}
+
+// Schema is a named JSON schema for structured replies
+type Schema struct {
+	Name   string
+	Schema json.RawMessage
+}
+
+// CompleteFunc sends a completion to a model and returns its reply
+type CompleteFunc func(ctx context.Context, completion Completion) (string, error)
+
+// CompleteOption adjusts a completion sent with Translator.Complete
+type CompleteOption func(*Completion)
+
+// MaxTokens caps the reply at n tokens, within the provider's
+// OutputLimits
+func MaxTokens(n int) CompleteOption {
+	return func(completion *Completion) { completion.MaxTokens = n }
+}
+
+// Temperature sets the sampling temperature, unless OPENAI_TEMPERATURE
+// overrides it
+func Temperature(temperature float64) CompleteOption {
+	return func(completion *Completion) { completion.Temperature = temperature }
+}
+
+// NewCompletion returns the completion of a Translator.Complete call, at
+// the translations' temperature of 0.7 unless opts change it
+func NewCompletion(system, user string, opts ...CompleteOption) Completion {
+	completion := Completion{System: system, User: user, Temperature: 0.7}
+	for _, opt := range opts {
+		opt(&completion)
+	}
+	return completion
+}
+
+// promptData holds the values available to the user prompt template
+type promptData struct {
+	Username string
+	Message  string
+}
+
+// Translate translates a message with complete. Code in the message is
+// swapped for placeholders the model keeps, and put back verbatim. Thread
+// messages and quotes are included in the prompt as labeled context so the
+// model can reference them without re-translating them. Preambles and
+// quotation marks the model wraps the translation in are stripped.
+func Translate(ctx context.Context, req TranslationRequest, complete CompleteFunc) (TranslationResult, error) {
+	// Sanitize everything user-provided before it goes into the request
+	message := normalizeInput(req.Message)
+	username := normalizeName(req.Username)
+	quotes := normalizeQuotes(req.Quotes)
+	thread := normalizeQuotes(req.Thread)
+
+	// Only the prose is translated, code goes back in verbatim. A message
+	// too long for its cost is cut short, dropping the code after the cut.
+	protected := codeblock.Protect(message)
+	prose, truncated := truncate(protected.Prose, req.MaxMessageTokens)
+	if truncated {
+		protected = protected.Cut(prose)
+	}
+
+	var promptBuf strings.Builder
+	if err := req.Style.UserPrompt.Execute(&promptBuf, promptData{Username: username, Message: protected.Prose}); err != nil {
+		return TranslationResult{}, fmt.Errorf("error rendering prompt: %w", err)
+	}
+	prompt := promptBuf.String() + "\n\nReply with the translation only: no introduction, no title and no quotation marks around it. " + lengthPrompt
+
+	if truncated {
+		prompt += "\n\n" + truncationPrompt
+	}
+
+	// Messages in other languages are understood in their own first
+	language := DetectLanguage(protected.Prose)
+	outputLanguage := req.OutputLanguage
+	if outputLanguage == "" {
+		outputLanguage = "en"
+	}
+	system := req.Style.SystemPrompt
+	if adjustment := languagePrompt(language, outputLanguage); adjustment != "" {
+		system += "\n\n" + adjustment
+	}
+
+	if protected.HasCode() {
+		prompt += "\n\nCode in the message was replaced with placeholders like [[CODE_1]]. " +
+			"Keep each placeholder exactly as written, once, where the code belongs in the translation."
+	}
+
+	if len(thread) > 0 {
+		prompt += "\n\nThe message is a reply in a thread. Earlier messages of the thread, for context only; do not translate or repeat them:"
+		for _, m := range thread {
+			prompt += fmt.Sprintf("\n[%s]: \"%s\"", m.Author, m.Text)
+		}
+	}
+
+	if len(quotes) > 0 {
+		prompt += "\n\nThe message shares the following quoted message(s) as context. " +
+			"Translate only the message above; reference the quoted content where it makes the joke land, but do not translate or repeat it:"
+		for _, quote := range quotes {
+			prompt += fmt.Sprintf("\n[Quoted message from %s]: \"%s\"", quote.Author, quote.Text)
+		}
+	}
+
+	// A streamed translation shows code as it will be posted
+	var partials func(string)
+	if send := partialsFrom(ctx); send != nil {
+		partials = func(text string) { send(protected.RestorePartial(text)) }
+	}
+
+	ctx, offered := withCandidates(ctx)
+	translated, err := complete(ctx, Completion{
+		System:      system,
+		User:        prompt,
+		Temperature: 0.7, // Slightly creative
+		MaxTokens:   replyTokens(protected.Prose),
+		Candidates:  true,
+		Partials:    partials,
+	})
+	if err != nil {
+		return TranslationResult{}, err
+	}
+
+	result := TranslationResult{Text: protected.Restore(cleanTranslation(translated)), Truncated: truncated, Language: language}
+	for _, candidate := range *offered {
+		result.Candidates = append(result.Candidates, protected.Restore(cleanTranslation(candidate)))
+	}
+	return result, nil
+}
+
+// normalizeQuotes returns a sanitized copy of quoted messages
+func normalizeQuotes(quotes []QuotedMessage) []QuotedMessage {
+	quotes = append([]QuotedMessage(nil), quotes...)
+	for i := range quotes {
+		quotes[i].Author = normalizeName(quotes[i].Author)
+		quotes[i].Text = normalizeInput(quotes[i].Text)
+	}
+	return quotes
+}
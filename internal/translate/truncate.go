@@ -0,0 +1,46 @@
+package translate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/user/slack-bot-api/internal/threadctx"
+)
+
+// truncationMarker ends a message cut short
+const truncationMarker = "[…]"
+
+// truncationPrompt explains the marker to the model
+const truncationPrompt = "The message was too long and was cut short where it says " + truncationMarker +
+	"; translate what's there and end the translation with " + truncationMarker + " too."
+
+// sentenceEnd matches the end of a sentence, punctuation and any closing
+// quotes or brackets, followed by whitespace
+var sentenceEnd = regexp.MustCompile(`[.!?…]["'”’)\]]*\s`)
+
+// truncate cuts text down to about maxTokens, after the last sentence that
+// fits, and marks the cut. Without a sentence end in the second half of
+// what fits, it's cut after the last word instead. It reports whether text
+// was cut; a maxTokens of 0 never cuts.
+func truncate(text string, maxTokens int) (string, bool) {
+	if maxTokens <= 0 || threadctx.EstimateTokens(text) <= maxTokens {
+		return text, false
+	}
+
+	runes := []rune(text)
+	fits := string(runes[:min(len(runes), maxTokens*threadctx.CharsPerToken)])
+
+	cut := -1
+	if ends := sentenceEnd.FindAllStringIndex(fits, -1); len(ends) > 0 {
+		if end := ends[len(ends)-1][1]; end >= len(fits)/2 {
+			cut = end
+		}
+	}
+	if cut < 0 {
+		cut = strings.LastIndexAny(fits, " \t\n")
+	}
+	if cut > 0 {
+		fits = fits[:cut]
+	}
+	return strings.TrimSpace(fits) + " " + truncationMarker, true
+}
@@ -0,0 +1,106 @@
+package translate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/user/slack-bot-api/internal/threadctx"
+)
+
+// postmortem is a synthetic message of about 8,000 tokens
+var postmortem = strings.Repeat("The cache cluster failed over at 09:14 and the API returned errors for six minutes. ", 380)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxTokens int
+		// want is what's left before the marker; empty expects text back
+		// uncut
+		want string
+	}{
+		{name: "short enough", text: "Deploy is done.", maxTokens: 100},
+		{name: "no limit", text: postmortem, maxTokens: 0},
+		{
+			name:      "cut at the last sentence that fits",
+			text:      postmortem,
+			maxTokens: 50,
+			want:      strings.TrimSpace(strings.Repeat("The cache cluster failed over at 09:14 and the API returned errors for six minutes. ", 2)),
+		},
+		{
+			name:      "closing quote stays with its sentence",
+			text:      `He said "roll it back!" ` + strings.Repeat("x", 100),
+			maxTokens: 10,
+			want:      `He said "roll it back!"`,
+		},
+		{
+			name:      "without a sentence end, cut at a word",
+			text:      strings.Repeat("incident ", 100),
+			maxTokens: 10,
+			want:      strings.TrimSpace(strings.Repeat("incident ", 4)),
+		},
+		{
+			name:      "a sentence end early on is ignored",
+			text:      "Hi. " + strings.Repeat("incident ", 100),
+			maxTokens: 10,
+			want:      "Hi. " + strings.TrimSpace(strings.Repeat("incident ", 4)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncate(tt.text, tt.maxTokens)
+			if tt.want == "" {
+				if truncated || got != tt.text {
+					t.Errorf("truncate cut the text to %q, want it whole", got)
+				}
+				return
+			}
+
+			if !truncated {
+				t.Fatalf("truncate didn't cut a text of %d tokens to %d", threadctx.EstimateTokens(tt.text), tt.maxTokens)
+			}
+			if want := tt.want + " " + truncationMarker; got != want {
+				t.Errorf("truncate = %q, want %q", got, want)
+			}
+			if n := utf8.RuneCountInString(tt.want); n > tt.maxTokens*threadctx.CharsPerToken {
+				t.Errorf("kept %d characters, want at most %d", n, tt.maxTokens*threadctx.CharsPerToken)
+			}
+		})
+	}
+}
+
+// A long message is cut before it's sent, and the prompt explains the
+// marker
+func TestTranslateTruncatesLongMessages(t *testing.T) {
+	var prompt string
+	complete := func(ctx context.Context, completion Completion) (string, error) {
+		prompt = completion.User
+		return "the cache said bye " + truncationMarker, nil
+	}
+
+	result, err := Translate(context.Background(), TranslationRequest{
+		Style:            builtinStyles[0],
+		Message:          postmortem,
+		Username:         "alice",
+		MaxMessageTokens: 2000,
+	}, complete)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	if !result.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	if !strings.Contains(prompt, "six minutes. "+truncationMarker) {
+		t.Error("prompt doesn't hold the message cut at a sentence and marked")
+	}
+	if !strings.Contains(prompt, truncationPrompt) {
+		t.Error("prompt doesn't explain the truncation marker")
+	}
+	if tokens := threadctx.EstimateTokens(prompt); tokens > 2500 {
+		t.Errorf("prompt is %d tokens, want the message cut to about 2000", tokens)
+	}
+}
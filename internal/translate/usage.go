@@ -0,0 +1,60 @@
+package translate
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage adds up the tokens of the model requests made with a context from
+// WithUsage. It is safe for concurrent use.
+type Usage struct {
+	mu               sync.Mutex
+	promptTokens     int
+	completionTokens int
+	// model is the model that answered the last request
+	model string
+}
+
+// usageKey carries a *Usage
+type usageKey struct{}
+
+// WithUsage returns a copy of ctx that counts the tokens of requests made
+// with it into the returned Usage
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{}
+	return context.WithValue(ctx, usageKey{}, usage), usage
+}
+
+// Tokens returns the prompt and completion tokens counted so far
+func (u *Usage) Tokens() (prompt, completion int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.promptTokens, u.completionTokens
+}
+
+// Model returns the model that answered the last request, empty when none
+// did
+func (u *Usage) Model() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.model
+}
+
+// AddUsage counts a response's token usage into the Usage carried by ctx,
+// if any, along with the model that answered. Providers call it for every
+// response.
+func AddUsage(ctx context.Context, model string, promptTokens, completionTokens int) {
+	u, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.promptTokens += promptTokens
+	u.completionTokens += completionTokens
+	u.model = model
+}
@@ -0,0 +1,62 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxVibeCheckLength is the maximum length of a vibe check, in characters
+const maxVibeCheckLength = 80
+
+// VibeCheck summarizes the tone of a message with complete, as a single
+// Gen Alpha line of at most 80 characters. If the model ignores the limit
+// the request is retried once, after which the line is truncated.
+func VibeCheck(ctx context.Context, message, username string, complete CompleteFunc) (string, error) {
+	message = normalizeInput(message)
+	username = normalizeName(username)
+
+	completion := Completion{
+		System: "You are a Gen Alpha vibe checker. Summarize the tone of a message in Gen Alpha slang as a single line " +
+			fmt.Sprintf("of at most %d characters, starting with \"vibe:\". ", maxVibeCheckLength) +
+			"Example: \"vibe: unhinged optimism 📈✨, certified W\". Reply with the line only.",
+		User:        fmt.Sprintf("Vibe check this message from %s: \"%s\"", username, message),
+		Temperature: 0.7,
+	}
+
+	var vibe string
+	for attempt := 1; attempt <= 2; attempt++ {
+		content, err := complete(ctx, completion)
+		if err != nil {
+			return "", err
+		}
+
+		vibe = firstLine(content)
+		if utf8.RuneCountInString(vibe) <= maxVibeCheckLength {
+			return vibe, nil
+		}
+	}
+
+	return truncateRunes(vibe, maxVibeCheckLength), nil
+}
+
+// firstLine returns the first non-empty line of s, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// truncateRunes shortens s to at most n characters, ending with an ellipsis
+// when anything was cut
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
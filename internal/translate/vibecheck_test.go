@@ -0,0 +1,89 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// replies returns a CompleteFunc answering with replies in turn, and the
+// number of calls made
+func replies(answers ...string) (CompleteFunc, *int) {
+	calls := 0
+	return func(ctx context.Context, completion Completion) (string, error) {
+		answer := answers[min(calls, len(answers)-1)]
+		calls++
+		return answer, nil
+	}, &calls
+}
+
+func TestVibeCheckLength(t *testing.T) {
+	long := "vibe: " + strings.Repeat("unhinged optimism ", 10)
+	tests := []struct {
+		name    string
+		answers []string
+		want    string
+		calls   int
+	}{
+		{
+			name:    "within the limit",
+			answers: []string{"vibe: chaotic good 🔥"},
+			want:    "vibe: chaotic good 🔥",
+			calls:   1,
+		},
+		{
+			name:    "first line only",
+			answers: []string{"\n  vibe: lowkey stressed  \nThis message shows stress."},
+			want:    "vibe: lowkey stressed",
+			calls:   1,
+		},
+		{
+			name:    "too long, retried",
+			answers: []string{long, "vibe: big W energy"},
+			want:    "vibe: big W energy",
+			calls:   2,
+		},
+		{
+			name:    "too long twice, truncated",
+			answers: []string{long, long + "again"},
+			want:    strings.TrimSpace(string([]rune(long + "again")[:maxVibeCheckLength-1])) + "…",
+			calls:   2,
+		},
+		{
+			name:    "exactly the limit in emoji",
+			answers: []string{strings.Repeat("🔥", maxVibeCheckLength)},
+			want:    strings.Repeat("🔥", maxVibeCheckLength),
+			calls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complete, calls := replies(tt.answers...)
+			got, err := VibeCheck(context.Background(), "we shipped it on friday", "alice", complete)
+			if err != nil {
+				t.Fatalf("VibeCheck: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VibeCheck = %q, want %q", got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > maxVibeCheckLength {
+				t.Errorf("vibe check is %d characters, want at most %d", n, maxVibeCheckLength)
+			}
+			if *calls != tt.calls {
+				t.Errorf("%d completions, want %d", *calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestVibeCheckError(t *testing.T) {
+	failure := errors.New("model on vacation")
+	_, err := VibeCheck(context.Background(), "hello", "alice", func(context.Context, Completion) (string, error) {
+		return "", failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("VibeCheck error = %v, want %v", err, failure)
+	}
+}
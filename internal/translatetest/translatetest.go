@@ -0,0 +1,102 @@
+// Package translatetest is a fake translate.Translator for tests. It
+// answers without a model, records what it was asked, and can be made to
+// fail, stall or stream.
+package translatetest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/user/slack-bot-api/internal/translate"
+)
+
+// Model is the model name the fake reports
+const Model = "fake-model"
+
+// Translator is a fake translate.Translator. By default a message is
+// translated to "fr fr " followed by the message.
+type Translator struct {
+	// TranslateFunc, when set, replaces the default translation
+	TranslateFunc func(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error)
+
+	mu       sync.Mutex
+	requests []translate.TranslationRequest
+	errs     []error
+}
+
+// Translation is what the default fake makes of a message
+func Translation(message string) string {
+	return "fr fr " + message
+}
+
+// Fail makes the next translations fail with errs, one per call
+func (t *Translator) Fail(errs ...error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errs = append(t.errs, errs...)
+}
+
+// Requests returns the translations asked for so far, in order
+func (t *Translator) Requests() []translate.TranslationRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]translate.TranslationRequest(nil), t.requests...)
+}
+
+// Messages returns the messages translations were asked for, in order
+func (t *Translator) Messages() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	messages := make([]string, len(t.requests))
+	for i, req := range t.requests {
+		messages[i] = req.Message
+	}
+	return messages
+}
+
+// Translate records the request and translates it, or fails with the next
+// queued error
+func (t *Translator) Translate(ctx context.Context, req translate.TranslationRequest) (translate.TranslationResult, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	var err error
+	if len(t.errs) > 0 {
+		err, t.errs = t.errs[0], t.errs[1:]
+	}
+	translateFunc := t.TranslateFunc
+	t.mu.Unlock()
+
+	if err != nil {
+		return translate.TranslationResult{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return translate.TranslationResult{}, err
+	}
+	if translateFunc != nil {
+		return translateFunc(ctx, req)
+	}
+	return translate.TranslationResult{Text: Translation(req.Message)}, nil
+}
+
+// VibeCheck returns a fixed vibe line
+func (t *Translator) VibeCheck(ctx context.Context, message, username string) (string, error) {
+	return "vibe: immaculate", ctx.Err()
+}
+
+// Summarize returns the message as its own TL;DR, translated
+func (t *Translator) Summarize(ctx context.Context, style translate.Style, message, username string) (translate.Announcement, error) {
+	result, err := t.Translate(ctx, translate.TranslationRequest{Style: style, Message: message, Username: username})
+	return translate.Announcement{TLDR: message, Translation: result.Text}, err
+}
+
+// Complete echoes the user prompt's last line
+func (t *Translator) Complete(ctx context.Context, system, user string, opts ...translate.CompleteOption) (string, error) {
+	lines := strings.Split(strings.TrimSpace(user), "\n")
+	return lines[len(lines)-1], ctx.Err()
+}
+
+// Model returns Model
+func (t *Translator) Model() string {
+	return Model
+}
@@ -0,0 +1,15 @@
+package v1
+
+import "reflect"
+
+// Responses lists every response type by name, in the order they appear in
+// the generated schema
+var Responses = []struct {
+	Name string
+	Type reflect.Type
+}{
+	{"DebugState", reflect.TypeOf(DebugState{})},
+	{"SlackUsage", reflect.TypeOf(SlackUsage{})},
+	{"Health", reflect.TypeOf(Health{})},
+	{"Stats", reflect.TypeOf(Stats{})},
+}
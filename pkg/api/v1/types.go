@@ -0,0 +1,141 @@
+// Package v1 defines the JSON responses of the bot's HTTP endpoints.
+//
+// Within schema version 1 changes are additive only: fields may be added,
+// but existing fields are never renamed, retyped or removed. Anything else
+// requires a new package (v2) and a new SchemaVersion. schema.json is
+// generated from these types with `go generate ./pkg/api/v1`; go test
+// fails when it is out of date or a change removes or retypes a field.
+package v1
+
+//go:generate go run ../../../cmd/schemagen -out schema.json
+
+import "time"
+
+// SchemaVersion is the major version of the response schema
+const SchemaVersion = 1
+
+// DebugState is the response of GET /debug/state
+type DebugState struct {
+	SchemaVersion               int              `json:"schema_version" description:"Major version of this response schema"`
+	StartupPhase                string           `json:"startup_phase" description:"Current startup phase: starting, connecting, verifying or ready"`
+	TranslationConcurrencyLimit int              `json:"translation_concurrency_limit" description:"Current limit on concurrent translation requests"`
+	TranslationsInFlight        int              `json:"translations_in_flight" description:"Translation requests currently running"`
+	MetricLabelOverflow         uint64           `json:"metric_label_overflow" description:"Times a metric label combination was collapsed because of the series cap"`
+	DuplicateEventsDropped      uint64           `json:"duplicate_events_dropped" description:"Redelivered Slack events that were dropped"`
+	StateStoreDegraded          bool             `json:"state_store_degraded" description:"Whether writes to the state file are failing and state is kept in memory only"`
+	Approvals                   ApprovalStats    `json:"approvals" description:"Outcomes of translations held for approval since startup"`
+	Decisions                   []DecisionRecord `json:"decisions" description:"Filter decisions for recent messages, oldest first"`
+}
+
+// Health is the response of GET /health and GET /ready
+type Health struct {
+	SchemaVersion      int        `json:"schema_version" description:"Major version of this response schema"`
+	Status             string     `json:"status" description:"ok, unhealthy (for /health) or not_ready (for /ready)"`
+	Problem            string     `json:"problem,omitempty" description:"What is wrong when the status isn't ok"`
+	Connected          bool       `json:"connected" description:"Whether the socket mode connection is currently up"`
+	LastConnectedAt    *time.Time `json:"last_connected_at,omitempty" description:"When the socket mode connection was last established"`
+	ConnectionFailures int        `json:"connection_failures" description:"Socket mode connection attempts in a row that failed, 0 while connected"`
+	StateStoreDegraded bool       `json:"state_store_degraded" description:"Whether writes to the state file are failing; the bot keeps working from memory"`
+}
+
+// Health statuses
+const (
+	HealthOK        = "ok"
+	HealthUnhealthy = "unhealthy"
+	HealthNotReady  = "not_ready"
+)
+
+// SlackUsage is the response of GET /debug/slack-usage
+type SlackUsage struct {
+	SchemaVersion   int           `json:"schema_version" description:"Major version of this response schema"`
+	WindowSeconds   int           `json:"window_seconds" description:"Length of the window the usage covers, ending now"`
+	LatencyBoundsMS []int64       `json:"latency_bounds_ms" description:"Upper bounds of the latency histogram buckets in milliseconds; a final bucket counts slower calls"`
+	Methods         []MethodUsage `json:"methods" description:"Usage per Web API method, most called first"`
+}
+
+// MethodUsage summarizes the calls of one Slack Web API method
+type MethodUsage struct {
+	Method       string   `json:"method" description:"Web API method, e.g. chat.postMessage"`
+	Calls        uint64   `json:"calls" description:"Calls made within the window"`
+	RateLimited  uint64   `json:"rate_limited" description:"Calls rejected with HTTP 429 within the window"`
+	AvgLatencyMS int64    `json:"avg_latency_ms" description:"Average call latency in milliseconds"`
+	Histogram    []uint64 `json:"latency_histogram" description:"Calls per latency bucket, see latency_bounds_ms"`
+}
+
+// Stats is the response of GET /status. The bot has no circuit breaker;
+// BudgetExhausted, which pauses translations, and
+// TranslationConcurrencyLimit, which drops while the LLM provider is slow,
+// stand in for one.
+type Stats struct {
+	SchemaVersion               int        `json:"schema_version" description:"Major version of this response schema"`
+	Build                       Build      `json:"build" description:"Version of the running binary"`
+	StartedAt                   time.Time  `json:"started_at" description:"When the bot started"`
+	UptimeSeconds               int64      `json:"uptime_seconds" description:"Seconds since the bot started"`
+	Connection                  Connection `json:"connection" description:"State of the socket mode connection to Slack"`
+	MonitoredChannels           *int       `json:"monitored_channels" description:"Channels listed in SLACK_CHANNEL_IDS; null when the bot monitors every channel it's in"`
+	TargetUsers                 *int       `json:"target_users" description:"Users whose messages are translated, user group members included; null when everyone's are"`
+	BudgetExhausted             bool       `json:"budget_exhausted" description:"Whether the daily LLM budget is used up, pausing translations until midnight"`
+	TranslationConcurrencyLimit int        `json:"translation_concurrency_limit" description:"Current limit on concurrent translation requests, lowered while the LLM provider is slow"`
+	Translations                uint64     `json:"translations" description:"Translations posted since startup"`
+	OpenAIErrors                uint64     `json:"openai_errors" description:"Failed OpenAI requests since startup"`
+	Panics                      uint64     `json:"panics" description:"Events whose handling panicked since startup; the bot recovers and carries on, but each one is a bug"`
+	PromptTokens                uint64     `json:"prompt_tokens" description:"Prompt tokens sent to the LLM since startup"`
+	CompletionTokens            uint64     `json:"completion_tokens" description:"Completion tokens received from the LLM since startup"`
+	EstimatedCost               *float64   `json:"estimated_cost,omitempty" description:"Estimated spend since startup, from the configured per-1K-token prices; missing without prices"`
+	Users                       []Count    `json:"users" description:"Translations by author user ID, most first"`
+	Channels                    []Count    `json:"channels" description:"Translations by channel ID, most first"`
+	Languages                   []Count    `json:"languages" description:"Translated messages by detected ISO 639-1 language code, most first; messages whose language couldn't be told aren't counted"`
+	OutputStyles                []Count    `json:"output_styles" description:"Translations by output style (translation, vibecheck or both), most first"`
+}
+
+// Build identifies the running binary, from the build information Go
+// embeds. Fields Go couldn't stamp, like the commit of a build outside a
+// git checkout, are missing.
+type Build struct {
+	Version   string `json:"version,omitempty" description:"Module version, (devel) for a build from a checkout"`
+	Commit    string `json:"commit,omitempty" description:"Git commit the binary was built from"`
+	Modified  bool   `json:"modified,omitempty" description:"Whether the checkout had uncommitted changes"`
+	GoVersion string `json:"go_version" description:"Go version the binary was built with"`
+}
+
+// Connection is the state of the socket mode connection
+type Connection struct {
+	Connected       bool       `json:"connected" description:"Whether the socket mode connection is currently up"`
+	LastConnectedAt *time.Time `json:"last_connected_at,omitempty" description:"When the socket mode connection was last established"`
+	Failures        int        `json:"failures" description:"Socket mode connection attempts in a row that failed, 0 while connected"`
+	StartupPhase    string     `json:"startup_phase" description:"Current startup phase: starting, connecting, verifying or ready"`
+}
+
+// Count is the number of translations for one user, channel, language or
+// output style
+type Count struct {
+	ID    string `json:"id" description:"User or channel ID, language code or output style"`
+	Count uint64 `json:"count" description:"Translations posted"`
+}
+
+// ApprovalStats counts how translations held for approval ended
+type ApprovalStats struct {
+	Approved  uint64 `json:"approved" description:"Translations an approver posted"`
+	Discarded uint64 `json:"discarded" description:"Translations an approver discarded"`
+	Expired   uint64 `json:"expired" description:"Translations discarded because nobody decided in time"`
+}
+
+// DecisionRecord is the decision trail for one message
+type DecisionRecord struct {
+	Channel    string         `json:"channel" description:"Channel ID"`
+	Timestamp  string         `json:"ts" description:"Message timestamp"`
+	User       string         `json:"user,omitempty" description:"Author user ID"`
+	ReceivedAt time.Time      `json:"received_at" description:"When the bot received the message"`
+	Steps      []DecisionStep `json:"steps" description:"Filters evaluated, in order"`
+	Translated bool           `json:"translated" description:"Whether a translation was posted"`
+	Model      string         `json:"model,omitempty" description:"Model that produced the translation"`
+	LatencyMS  int64          `json:"latency_ms,omitempty" description:"Translation latency in milliseconds"`
+	Error      string         `json:"error,omitempty" description:"Error that stopped the translation"`
+}
+
+// DecisionStep is a single filter decision
+type DecisionStep struct {
+	Filter string `json:"filter" description:"Name of the filter"`
+	Passed bool   `json:"passed" description:"Whether the message passed the filter"`
+	Detail string `json:"detail,omitempty" description:"Why the filter passed or failed"`
+}